@@ -0,0 +1,171 @@
+package codec
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/kumareswaramoorthi/companies/api/models"
+)
+
+// Supported formats, JSON is only used for exports
+const (
+	CSV    = "csv"
+	NDJSON = "ndjson"
+	JSON   = "json"
+)
+
+// maxLineSize bounds one NDJSON line, descriptions are up to 3000 characters.
+const maxLineSize = 1024 * 1024
+
+// RowError is returned by a decoder when a single row cannot be read.
+// Decoding can go on with the next row.
+type RowError struct {
+	Line int
+	Err  error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Err.Error())
+}
+
+// CompanyDecoder reads companies one row at a time.
+type CompanyDecoder interface {
+	// Decode returns the next company, the fields the row holds as they would be
+	// decoded from JSON and the line it was read from, or io.EOF once the input
+	// is exhausted.
+	Decode() (models.Company, map[string]interface{}, int, error)
+}
+
+// NewCompanyDecoder returns a decoder for the given format.
+func NewCompanyDecoder(format string, r io.Reader) (CompanyDecoder, error) {
+	switch format {
+	case CSV:
+		return newCSVDecoder(r)
+	case NDJSON:
+		return &ndjsonDecoder{scanner: newLineScanner(r)}, nil
+	}
+	return nil, fmt.Errorf("unsupported import format [%s]", format)
+}
+
+func newLineScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	return scanner
+}
+
+type ndjsonDecoder struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func (d *ndjsonDecoder) Decode() (models.Company, map[string]interface{}, int, error) {
+	for d.scanner.Scan() {
+		d.line++
+		raw := strings.TrimSpace(d.scanner.Text())
+		if raw == "" {
+			continue
+		}
+		var company models.Company
+		var fields map[string]interface{}
+		if err := json.Unmarshal([]byte(raw), &company); err != nil {
+			return models.Company{}, nil, d.line, &RowError{Line: d.line, Err: err}
+		}
+		if err := json.Unmarshal([]byte(raw), &fields); err != nil {
+			return models.Company{}, nil, d.line, &RowError{Line: d.line, Err: err}
+		}
+		return company, fields, d.line, nil
+	}
+	if err := d.scanner.Err(); err != nil {
+		return models.Company{}, nil, d.line, err
+	}
+	return models.Company{}, nil, d.line, io.EOF
+}
+
+type csvDecoder struct {
+	reader  *csv.Reader
+	columns map[string]int
+	line    int
+}
+
+// newCSVDecoder reads the header row, which names the columns of the following rows.
+func newCSVDecoder(r io.Reader) (*csvDecoder, error) {
+	reader := csv.NewReader(r)
+	reader.ReuseRecord = true
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read csv header: %s", err.Error())
+	}
+	columns := make(map[string]int, len(header))
+	for i, column := range header {
+		columns[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+	if _, ok := columns["name"]; !ok {
+		return nil, fmt.Errorf("csv header has no name column")
+	}
+	line, _ := reader.FieldPos(0)
+	return &csvDecoder{reader: reader, columns: columns, line: line}, nil
+}
+
+func (d *csvDecoder) Decode() (models.Company, map[string]interface{}, int, error) {
+	record, err := d.reader.Read()
+	if err == io.EOF {
+		return models.Company{}, nil, d.line, io.EOF
+	}
+	// The reader has no field positions for a row it failed to parse, the line
+	// is taken from the parse error instead.
+	if parseErr, ok := err.(*csv.ParseError); ok {
+		d.line = parseErr.StartLine
+		return models.Company{}, nil, d.line, &RowError{Line: d.line, Err: parseErr.Err}
+	}
+	if err != nil {
+		return models.Company{}, nil, d.line, err
+	}
+	d.line, _ = d.reader.FieldPos(0)
+	line := d.line
+
+	company := models.Company{
+		ID:          d.field(record, "id"),
+		Name:        d.field(record, "name"),
+		Description: d.field(record, "description"),
+		Type:        d.field(record, "type"),
+	}
+	// an empty cell is a missing field, as a member left out of a JSON document
+	fields := make(map[string]interface{})
+	for _, column := range []string{"id", "name", "description", "type"} {
+		if value := d.field(record, column); value != "" {
+			fields[column] = value
+		}
+	}
+	if value := d.field(record, "amount_of_employees"); value != "" {
+		if company.AmountOfEmployees, err = strconv.Atoi(value); err != nil {
+			return models.Company{}, nil, line, &RowError{Line: line, Err: fmt.Errorf("invalid amount_of_employees [%s]", value)}
+		}
+		fields["amount_of_employees"] = float64(company.AmountOfEmployees)
+	}
+	if value := d.field(record, "registered"); value != "" {
+		if company.Registered, err = strconv.ParseBool(value); err != nil {
+			return models.Company{}, nil, line, &RowError{Line: line, Err: fmt.Errorf("invalid registered [%s]", value)}
+		}
+		fields["registered"] = company.Registered
+	}
+	if value := d.field(record, "parent_id"); value != "" {
+		company.ParentID = &value
+		fields["parent_id"] = value
+	}
+	return company, fields, line, nil
+}
+
+func (d *csvDecoder) field(record []string, column string) string {
+	i, ok := d.columns[column]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
@@ -0,0 +1,151 @@
+package codec
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DecoderTestSuite struct {
+	suite.Suite
+}
+
+func TestDecoder(t *testing.T) {
+	suite.Run(t, new(DecoderTestSuite))
+}
+
+func (suite *DecoderTestSuite) decoder(format, input string) CompanyDecoder {
+	decoder, err := NewCompanyDecoder(format, strings.NewReader(input))
+	suite.Require().NoError(err)
+	return decoder
+}
+
+// rowError asserts that err is a RowError reported on line.
+func (suite *DecoderTestSuite) rowError(err error, line int) {
+	rowErr, ok := err.(*RowError)
+	suite.Require().True(ok, "expected a RowError, got %v", err)
+	suite.Equal(line, rowErr.Line)
+}
+
+func (suite *DecoderTestSuite) TestCSVDecode() {
+	decoder := suite.decoder(CSV, "Name, Amount_of_Employees,registered,type,parent_id\nAcme,42,true,Corporations,\nBeta,,,NonProfit,9b2e4f6a-1c3d-4e5f-8a7b-6c5d4e3f2a1b\n")
+
+	company, fields, line, err := decoder.Decode()
+	suite.NoError(err)
+	suite.Equal(2, line)
+	suite.Equal("Acme", company.Name)
+	suite.Equal(42, company.AmountOfEmployees)
+	suite.Equal(true, fields["registered"])
+	suite.Equal(float64(42), fields["amount_of_employees"])
+	suite.True(company.Registered)
+	suite.Nil(company.ParentID)
+
+	company, fields, line, err = decoder.Decode()
+	suite.NoError(err)
+	suite.Equal(3, line)
+	suite.Equal(0, company.AmountOfEmployees)
+	suite.False(company.Registered)
+	suite.NotContains(fields, "amount_of_employees")
+	suite.NotContains(fields, "registered")
+	suite.Equal("9b2e4f6a-1c3d-4e5f-8a7b-6c5d4e3f2a1b", *company.ParentID)
+
+	_, _, line, err = decoder.Decode()
+	suite.Equal(io.EOF, err)
+	suite.Equal(3, line)
+}
+
+func (suite *DecoderTestSuite) TestCSVBareQuoteOnFirstRow() {
+	decoder := suite.decoder(CSV, "name\nab\"c\nAcme\n")
+
+	_, _, line, err := decoder.Decode()
+	suite.rowError(err, 2)
+	suite.Equal(2, line)
+
+	company, _, line, err := decoder.Decode()
+	suite.NoError(err)
+	suite.Equal(3, line)
+	suite.Equal("Acme", company.Name)
+}
+
+func (suite *DecoderTestSuite) TestCSVWrongFieldCount() {
+	decoder := suite.decoder(CSV, "name,type\nAcme,Corporations\nBeta\n")
+
+	_, _, _, err := decoder.Decode()
+	suite.NoError(err)
+	_, _, line, err := decoder.Decode()
+	suite.rowError(err, 3)
+	suite.Equal(3, line)
+}
+
+func (suite *DecoderTestSuite) TestCSVInvalidInt() {
+	decoder := suite.decoder(CSV, "name,amount_of_employees\nAcme,many\n")
+
+	_, _, line, err := decoder.Decode()
+	suite.rowError(err, 2)
+	suite.Equal(2, line)
+	suite.Contains(err.Error(), "amount_of_employees")
+}
+
+func (suite *DecoderTestSuite) TestCSVInvalidBool() {
+	decoder := suite.decoder(CSV, "name,registered\nAcme,yes please\n")
+
+	_, _, _, err := decoder.Decode()
+	suite.rowError(err, 2)
+	suite.Contains(err.Error(), "registered")
+}
+
+func (suite *DecoderTestSuite) TestCSVHeaderWithoutName() {
+	_, err := NewCompanyDecoder(CSV, strings.NewReader("id,type\n"))
+	suite.Error(err)
+}
+
+func (suite *DecoderTestSuite) TestCSVHeaderOnly() {
+	decoder := suite.decoder(CSV, "name\n")
+
+	_, _, line, err := decoder.Decode()
+	suite.Equal(io.EOF, err)
+	suite.Equal(1, line)
+}
+
+func (suite *DecoderTestSuite) TestNDJSONSkipsBlankLines() {
+	decoder := suite.decoder(NDJSON, "{\"name\":\"Acme\"}\n\n   \n{\"name\":\"Beta\"}\n")
+
+	company, _, line, err := decoder.Decode()
+	suite.NoError(err)
+	suite.Equal(1, line)
+	suite.Equal("Acme", company.Name)
+
+	company, _, line, err = decoder.Decode()
+	suite.NoError(err)
+	suite.Equal(4, line)
+	suite.Equal("Beta", company.Name)
+
+	_, _, line, err = decoder.Decode()
+	suite.Equal(io.EOF, err)
+	suite.Equal(4, line)
+}
+
+func (suite *DecoderTestSuite) TestNDJSONInvalidLine() {
+	decoder := suite.decoder(NDJSON, "{\"name\":\n{\"name\":\"Beta\"}\n")
+
+	_, _, _, err := decoder.Decode()
+	suite.rowError(err, 1)
+
+	company, _, line, err := decoder.Decode()
+	suite.NoError(err)
+	suite.Equal(2, line)
+	suite.Equal("Beta", company.Name)
+}
+
+func (suite *DecoderTestSuite) TestNDJSONEmptyInput() {
+	_, _, line, err := suite.decoder(NDJSON, "").Decode()
+	suite.Equal(io.EOF, err)
+	suite.Equal(0, line)
+}
+
+func (suite *DecoderTestSuite) TestUnsupportedFormat() {
+	_, err := NewCompanyDecoder("xml", strings.NewReader(""))
+	suite.Error(err)
+}
@@ -0,0 +1,140 @@
+package codec
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/kumareswaramoorthi/companies/api/models"
+)
+
+// csvHeader is the header row of CSV exports, readable again by the CSV decoder.
+var csvHeader = []string{"id", "name", "description", "amount_of_employees", "registered", "type", "parent_id"}
+
+// CompanyEncoder writes companies one row at a time. Rows are buffered
+// until Flush or Close, which also writes whatever closes the document.
+type CompanyEncoder interface {
+	Encode(company models.Company) error
+	Flush() error
+	Close() error
+}
+
+// NewCompanyEncoder returns an encoder for the given format.
+func NewCompanyEncoder(format string, w io.Writer) (CompanyEncoder, error) {
+	switch format {
+	case CSV:
+		return &csvEncoder{writer: csv.NewWriter(w)}, nil
+	case NDJSON:
+		return &jsonEncoder{writer: bufio.NewWriter(w)}, nil
+	case JSON:
+		return &jsonEncoder{writer: bufio.NewWriter(w), array: true}, nil
+	}
+	return nil, fmt.Errorf("unsupported export format [%s]", format)
+}
+
+// ContentType returns the media type of the given format.
+func ContentType(format string) string {
+	switch format {
+	case CSV:
+		return "text/csv"
+	case NDJSON:
+		return "application/x-ndjson"
+	}
+	return "application/json"
+}
+
+type csvEncoder struct {
+	writer        *csv.Writer
+	headerWritten bool
+}
+
+func (e *csvEncoder) writeHeader() error {
+	if e.headerWritten {
+		return nil
+	}
+	e.headerWritten = true
+	return e.writer.Write(csvHeader)
+}
+
+func (e *csvEncoder) Encode(company models.Company) error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	parentID := ""
+	if company.ParentID != nil {
+		parentID = *company.ParentID
+	}
+	return e.writer.Write([]string{
+		company.ID,
+		company.Name,
+		company.Description,
+		strconv.Itoa(company.AmountOfEmployees),
+		strconv.FormatBool(company.Registered),
+		company.Type,
+		parentID,
+	})
+}
+
+func (e *csvEncoder) Flush() error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+func (e *csvEncoder) Close() error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	return e.Flush()
+}
+
+// jsonEncoder writes one JSON object per line, or a JSON array when array is set.
+type jsonEncoder struct {
+	writer *bufio.Writer
+	array  bool
+	rows   int
+}
+
+func (e *jsonEncoder) Encode(company models.Company) error {
+	if e.array {
+		separator := ",\n"
+		if e.rows == 0 {
+			separator = "[\n"
+		}
+		if _, err := e.writer.WriteString(separator); err != nil {
+			return err
+		}
+	}
+	e.rows++
+
+	row, err := json.Marshal(company)
+	if err != nil {
+		return err
+	}
+	if _, err = e.writer.Write(row); err != nil {
+		return err
+	}
+	if !e.array {
+		return e.writer.WriteByte('\n')
+	}
+	return nil
+}
+
+func (e *jsonEncoder) Flush() error {
+	return e.writer.Flush()
+}
+
+func (e *jsonEncoder) Close() error {
+	if e.array {
+		closing := "\n]\n"
+		if e.rows == 0 {
+			closing = "[]\n"
+		}
+		if _, err := e.writer.WriteString(closing); err != nil {
+			return err
+		}
+	}
+	return e.Flush()
+}
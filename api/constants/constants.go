@@ -1,5 +1,7 @@
 package constants
 
+import "time"
+
 // Logger constants
 const (
 	ReqID      = "Req-ID"
@@ -8,3 +10,109 @@ const (
 	LOGGER_KEY = "api_logger"
 	JSON       = "json"
 )
+
+// Auth constants
+const (
+	UserEmail          = "user_email"
+	DefaultAdminEmails = "admin@company.com"
+)
+
+// Revision constants
+const (
+	RevertedFrom = "reverted_from"
+)
+
+// Pagination constants
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+
+	DefaultSuggestionLimit = 10
+	MaxSuggestionLimit     = 25
+	MaxSuggestionLength    = 50
+)
+
+// Bulk constants
+const (
+	MaxBatchSize = 1000
+)
+
+// Export constants
+const (
+	ExportFlushSize = 500
+)
+
+// ID schemes for companies created without an ID, chosen with the ID_SCHEME environment variable
+const (
+	IDSchemeUUIDv4 = "uuidv4"
+	IDSchemeUUIDv7 = "uuidv7"
+)
+
+// Idempotency constants
+const (
+	IdempotencyKeyHeader        = "Idempotency-Key"
+	IdempotentReplayedHeader    = "Idempotent-Replayed"
+	MaxIdempotencyKeyLength     = 255
+	DefaultIdempotencyKeyTTL    = "24h"
+	IdempotencyKeyPurgeInterval = time.Hour
+)
+
+// Concurrency and caching constants
+const (
+	ETagHeader            = "ETag"
+	IfMatchHeader         = "If-Match"
+	IfNoneMatchHeader     = "If-None-Match"
+	LastModifiedHeader    = "Last-Modified"
+	IfModifiedSinceHeader = "If-Modified-Since"
+	CacheControlHeader    = "Cache-Control"
+	DefaultCacheControl   = "public, max-age=0, must-revalidate"
+)
+
+// Trash constants
+const (
+	DefaultTrashRetention = "720h"
+	TrashPurgeInterval    = time.Hour
+)
+
+// Stats constants
+const (
+	DefaultStatsGroupBy    = "type"
+	DefaultEmployeeBuckets = "0,10,50,250,1000"
+	MaxEmployeeBuckets     = 50
+	DefaultStatsCacheTTL   = "0s"
+	MaxStatsCacheEntries   = 1000
+)
+
+// What deleting a company does to its subsidiaries
+const (
+	SubsidiariesRestrict = "restrict"
+	SubsidiariesCascade  = "cascade"
+)
+
+// Ownership constants. Stakes passed around circular holdings shrink on every round,
+// the walk stops once what is left is below OwnershipEpsilon of the company.
+const (
+	OwnershipEpsilon       = 1e-9
+	MaxOwnershipIterations = 1000
+	OwnershipPrecision     = 4
+)
+
+// Location constants. Radius searches measure great-circle distances on a sphere of
+// EarthRadiusKm, MaxNearbyRadiusKm reaches the other side of it.
+const (
+	LocationHeadquarters  = "headquarters"
+	LocationBranch        = "branch"
+	EarthRadiusKm         = 6371.0088
+	DefaultNearbyRadiusKm = 10
+	MaxNearbyRadiusKm     = 20015.1
+)
+
+// Contact constants
+const (
+	MaxContactQueryLength = 100
+)
+
+// Related resources a company response can embed with the include query parameter
+const (
+	IncludeHistory = "history"
+)
@@ -1,25 +1,48 @@
 package controller
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/asaskevich/govalidator"
 	"github.com/gin-contrib/requestid"
 	"github.com/gin-gonic/gin"
+	"github.com/kumareswaramoorthi/companies/api/codec"
 	"github.com/kumareswaramoorthi/companies/api/constants"
+	"github.com/kumareswaramoorthi/companies/api/dto"
 	"github.com/kumareswaramoorthi/companies/api/errors"
 	"github.com/kumareswaramoorthi/companies/api/logging"
 	"github.com/kumareswaramoorthi/companies/api/models"
+	"github.com/kumareswaramoorthi/companies/api/patch"
 	service "github.com/kumareswaramoorthi/companies/api/service"
 	"github.com/kumareswaramoorthi/companies/api/utils"
 )
 
 type Controller interface {
 	CreateCompany(c *gin.Context)
+	CreateCompanies(c *gin.Context)
 	GetCompany(c *gin.Context)
+	GetCompanyByName(c *gin.Context)
 	DeleteCompany(c *gin.Context)
 	UpdateCompany(c *gin.Context)
+	ReplaceCompany(c *gin.Context)
+	UpdateCompanies(c *gin.Context)
+	DeleteCompanies(c *gin.Context)
+	ListCompanies(c *gin.Context)
+	SearchCompanies(c *gin.Context)
+	SuggestCompanies(c *gin.Context)
+	ImportCompanies(c *gin.Context)
+	ExportCompanies(c *gin.Context)
+	ListDeletedCompanies(c *gin.Context)
+	RestoreCompany(c *gin.Context)
+	GetCompanyHistory(c *gin.Context)
+	RevertCompany(c *gin.Context)
+	GetCompanyStats(c *gin.Context)
+	GetCompanyAncestors(c *gin.Context)
+	ListCompanyChildren(c *gin.Context)
+	GetCompanySubtree(c *gin.Context)
 }
 
 type controller struct {
@@ -33,15 +56,17 @@ func NewController(svc service.Company) Controller {
 // Company godoc
 // @Tags Company
 // @Summary create company
-// @Description creation of new company
+// @Description creation of new company, the ID is generated by the server when omitted
 // @Accept json
 // @Produce  json
 // @Success 201 {object} models.Company
+// @Header 201 {string} Location "URL of the created company"
 // @Failure 400 {object} errors.ErrorResponse
 // @Failure 403 {object} errors.ErrorResponse
 // @Failure 500 {object} errors.ErrorResponse
 // @Param CreateCompany body models.Company true "request body"
 // @param authorization header string true "string" default(authorization)
+// @Param Idempotency-Key header string false "replays the stored response when a request is retried with the same key"
 // @Router /api/v1/company [POST]
 func (ctrl controller) CreateCompany(c *gin.Context) {
 	logger := logging.GetLogger(c).
@@ -68,19 +93,97 @@ func (ctrl controller) CreateCompany(c *gin.Context) {
 		return
 	}
 
+	c.Header("Location", "/api/v1/company/"+company.ID)
+	setETag(c, company.Version)
 	c.JSON(http.StatusCreated, company)
 }
 
+// Company godoc
+// @Tags Company
+// @Summary create companies in bulk
+// @Description creation of many companies in one transaction, either all or nothing (atomic) or reporting each failed item (partial)
+// @Accept json
+// @Produce  json
+// @Success 201 {object} models.BulkResult
+// @Success 207 {object} models.BulkResult
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 422 {object} models.BulkResult
+// @Failure 500 {object} errors.ErrorResponse
+// @Param CreateCompanies body []models.Company true "request body"
+// @Param mode query string false "atomic or partial" default(atomic)
+// @param authorization header string true "string" default(authorization)
+// @Param Idempotency-Key header string false "replays the stored response when a request is retried with the same key"
+// @Router /api/v1/company/bulk [POST]
+func (ctrl controller) CreateCompanies(c *gin.Context) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Controller").
+		WithField(constants.Method, "CreateCompanies")
+
+	mode := c.DefaultQuery("mode", models.BulkModeAtomic)
+	if mode != models.BulkModeAtomic && mode != models.BulkModePartial {
+		c.AbortWithStatusJSON(errors.ErrInvalidQueryParams.HttpStatusCode, errors.ErrInvalidQueryParams)
+		return
+	}
+
+	body, err := c.GetRawData()
+	if err != nil {
+		c.AbortWithStatusJSON(errors.ErrBadRequest.HttpStatusCode, errors.ErrBadRequest)
+		return
+	}
+	var companiesReq []models.Company
+	var documents []map[string]interface{}
+	if json.Unmarshal(body, &companiesReq) != nil || json.Unmarshal(body, &documents) != nil {
+		c.AbortWithStatusJSON(errors.ErrBadRequest.HttpStatusCode, errors.ErrBadRequest)
+		return
+	}
+	if len(companiesReq) == 0 || len(companiesReq) > constants.MaxBatchSize {
+		c.AbortWithStatusJSON(errors.ErrInvalidBatchSize.HttpStatusCode, errors.ErrInvalidBatchSize)
+		return
+	}
+
+	result, errResp := ctrl.svc.CreateCompanies(c, companiesReq, documents, mode == models.BulkModeAtomic)
+	if errResp != nil {
+		logger.Errorf("CreateCompanies - %s", errResp.Error())
+		c.AbortWithStatusJSON(errResp.HttpStatusCode, errResp)
+		return
+	}
+
+	c.JSON(bulkStatus(result), result)
+}
+
+// bulkStatus is 201 when every item succeeded, 422 when none did and 207 otherwise.
+func bulkStatus(result models.BulkResult) int {
+	switch {
+	case result.Failed == 0:
+		return http.StatusCreated
+	case result.Succeeded == 0:
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusMultiStatus
+}
+
 // Company godoc
 // @Tags Company
 // @Summary get company
-// @Description get company info by ID
+// @Description get company info by ID, with as_of the company as it was at that time and the revision it was read from (models.CompanyVersion), with fields only those fields and with include the related resources embedded
 // @Accept json
 // @Produce  json
 // @Success 200 {object} models.Company
+// @Header 200 {string} ETag "version of the company, weak with fields or include"
+// @Header 200 {string} Last-Modified "time of the last change of the company"
+// @Header 200 {string} Cache-Control "set by the CACHE_CONTROL environment variable"
+// @Success 304 "the company did not change since the If-None-Match or If-Modified-Since validator"
 // @Failure 400 {object} errors.ErrorResponse
 // @Failure 403 {object} errors.ErrorResponse
 // @Failure 500 {object} errors.ErrorResponse
+// @Param as_of query string false "RFC 3339 point in time, e.g. 2023-01-31T00:00:00Z"
+// @Param fields query string false "comma separated fields to return, the id is always returned"
+// @Param include query string false "comma separated related resources to embed: history, which requires the authorization header"
+// @param authorization header string false "string, required with include=history"
+// @Param If-None-Match header string false "ETag of the cached company"
+// @Param If-Modified-Since header string false "Last-Modified time of the cached company"
 // @Router /api/v1/company/:id [GET]
 func (ctrl controller) GetCompany(c *gin.Context) {
 	logger := logging.GetLogger(c).
@@ -94,27 +197,104 @@ func (ctrl controller) GetCompany(c *gin.Context) {
 		return
 	}
 
-	company, err := ctrl.svc.GetCompany(c, id)
+	asOf, parseErr := parseAsOf(c)
+	if parseErr != nil {
+		logger.Errorf("GetCompany - %s", parseErr.Error())
+		c.AbortWithStatusJSON(errors.ErrInvalidQueryParams.HttpStatusCode, errors.ErrInvalidQueryParams)
+		return
+	}
+	shape, parseErr := parseResponseShape(c)
+	if parseErr != nil {
+		logger.Errorf("GetCompany - %s", parseErr.Error())
+		c.AbortWithStatusJSON(errors.ErrInvalidQueryParams.HttpStatusCode, errors.ErrInvalidQueryParams)
+		return
+	}
+	if asOf != nil {
+		company, err := ctrl.svc.GetCompanyAsOf(c, id, *asOf)
+		if err != nil {
+			logger.Errorf("GetCompany - %s", err.Error())
+			c.AbortWithStatusJSON(err.HttpStatusCode, err)
+			return
+		}
+		ctrl.renderCompany(c, company, id, shape)
+		return
+	}
+
+	company, err := ctrl.svc.GetCompany(c, id, shape.Fields)
 	if err != nil {
 		logger.Errorf("GetCompany - %s", err.Error())
 		c.AbortWithStatusJSON(err.HttpStatusCode, err)
 		return
 	}
 
+	setCacheHeaders(c, company, shape)
+	if notModified(c, company) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	ctrl.renderCompany(c, company, id, shape)
+}
+
+// Company godoc
+// @Tags Company
+// @Summary get company by name
+// @Description get company info by name, matched case-insensitively
+// @Accept json
+// @Produce  json
+// @Success 200 {object} models.Company
+// @Header 200 {string} ETag "version of the company"
+// @Header 200 {string} Last-Modified "time of the last change of the company"
+// @Header 200 {string} Cache-Control "set by the CACHE_CONTROL environment variable"
+// @Success 304 "the company did not change since the If-None-Match or If-Modified-Since validator"
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Param name path string true "company name"
+// @Param If-None-Match header string false "ETag of the cached company"
+// @Param If-Modified-Since header string false "Last-Modified time of the cached company"
+// @Router /api/v1/company/by-name/:name [GET]
+func (ctrl controller) GetCompanyByName(c *gin.Context) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Controller").
+		WithField(constants.Method, "GetCompanyByName")
+
+	name := c.Param("name")
+	if name == "" {
+		c.AbortWithStatusJSON(errors.ErrBadRequest.HttpStatusCode, errors.ErrBadRequest)
+		return
+	}
+
+	company, err := ctrl.svc.GetCompanyByName(c, name)
+	if err != nil {
+		logger.Errorf("GetCompanyByName - %s", err.Error())
+		c.AbortWithStatusJSON(err.HttpStatusCode, err)
+		return
+	}
+
+	setCacheHeaders(c, company, responseShape{})
+	if notModified(c, company) {
+		c.Status(http.StatusNotModified)
+		return
+	}
 	c.JSON(http.StatusOK, company)
 }
 
 // Company godoc
 // @Tags Company
 // @Summary delete a company
-// @Description delete company by ID
+// @Description moves the company with the given ID to the trash, from where it can be restored until it is purged. A company with subsidiaries is only deleted with subsidiaries=cascade, which moves them to the trash as well
 // @Accept json
 // @Produce  json
 // @Success 200 {string} successfully deleted company
 // @Failure 400 {object} errors.ErrorResponse
 // @Failure 403 {object} errors.ErrorResponse
+// @Failure 409 {object} errors.ErrorResponse
+// @Failure 412 {object} errors.ErrorResponse
 // @Failure 500 {object} errors.ErrorResponse
 // @param authorization header string true "string" default(authorization)
+// @Param Idempotency-Key header string false "replays the stored response when a request is retried with the same key"
+// @Param If-Match header string false "ETag of the company, the delete fails with 412 when the company was changed since"
+// @Param subsidiaries query string false "restrict keeps a company with subsidiaries, cascade deletes them with it" Enums(restrict, cascade) default(restrict)
 // @Router /api/v1/company/:id [DELETE]
 func (ctrl controller) DeleteCompany(c *gin.Context) {
 	logger := logging.GetLogger(c).
@@ -128,7 +308,13 @@ func (ctrl controller) DeleteCompany(c *gin.Context) {
 		return
 	}
 
-	err := ctrl.svc.DeleteCompany(c, id)
+	subsidiaries := c.DefaultQuery("subsidiaries", constants.SubsidiariesRestrict)
+	if subsidiaries != constants.SubsidiariesRestrict && subsidiaries != constants.SubsidiariesCascade {
+		c.AbortWithStatusJSON(errors.ErrInvalidQueryParams.HttpStatusCode, errors.ErrInvalidQueryParams)
+		return
+	}
+
+	err := ctrl.svc.DeleteCompany(c, id, parseIfMatch(c), subsidiaries == constants.SubsidiariesCascade)
 	if err != nil {
 		logger.Errorf("DeleteCompany - %s", err.Error())
 		c.AbortWithStatusJSON(err.HttpStatusCode, err)
@@ -140,16 +326,291 @@ func (ctrl controller) DeleteCompany(c *gin.Context) {
 
 // Company godoc
 // @Tags Company
-// @Summary update a company
-// @Description update company by ID
+// @Summary list deleted companies
+// @Description lists the companies in the trash, most recently deleted first, admins only
+// @Accept json
+// @Produce  json
+// @Success 200 {array} models.DeletedCompany
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Param limit query int false "page size" default(20)
+// @Param offset query int false "number of companies to skip" default(0)
+// @param authorization header string true "string" default(authorization)
+// @Router /api/v1/company/trash [GET]
+func (ctrl controller) ListDeletedCompanies(c *gin.Context) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Controller").
+		WithField(constants.Method, "ListDeletedCompanies")
+
+	limit, parseErr := parseLimit(c, constants.DefaultPageSize, constants.MaxPageSize)
+	if parseErr != nil {
+		c.AbortWithStatusJSON(errors.ErrInvalidQueryParams.HttpStatusCode, errors.ErrInvalidQueryParams)
+		return
+	}
+	offset, parseErr := parseOffset(c)
+	if parseErr != nil {
+		c.AbortWithStatusJSON(errors.ErrInvalidQueryParams.HttpStatusCode, errors.ErrInvalidQueryParams)
+		return
+	}
+
+	companies, err := ctrl.svc.ListDeletedCompanies(c, limit, offset)
+	if err != nil {
+		logger.Errorf("ListDeletedCompanies - %s", err.Error())
+		c.AbortWithStatusJSON(err.HttpStatusCode, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, companies)
+}
+
+// Company godoc
+// @Tags Company
+// @Summary restore a company
+// @Description takes a deleted company out of the trash, admins only
+// @Accept json
+// @Produce  json
+// @Success 200 {object} models.Company
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @param authorization header string true "string" default(authorization)
+// @Router /api/v1/company/:id/restore [POST]
+func (ctrl controller) RestoreCompany(c *gin.Context) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Controller").
+		WithField(constants.Method, "RestoreCompany")
+
+	id := c.Param("id")
+	if id == "" {
+		c.AbortWithStatusJSON(errors.ErrBadRequest.HttpStatusCode, errors.ErrBadRequest)
+		return
+	}
+
+	company, err := ctrl.svc.RestoreCompany(c, id)
+	if err != nil {
+		logger.Errorf("RestoreCompany - %s", err.Error())
+		c.AbortWithStatusJSON(err.HttpStatusCode, err)
+		return
+	}
+
+	setETag(c, company.Version)
+	c.JSON(http.StatusOK, company)
+}
+
+// Company godoc
+// @Tags Company
+// @Summary company history
+// @Description lists the revisions of a company, newest first, each with the full snapshot, the changed fields, the actor and the time of the change
+// @Accept json
+// @Produce  json
+// @Success 200 {object} models.CompanyRevisionPage
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Param limit query int false "page size" default(20)
+// @Param offset query int false "number of revisions to skip" default(0)
+// @param authorization header string true "string" default(authorization)
+// @Router /api/v1/company/:id/history [GET]
+func (ctrl controller) GetCompanyHistory(c *gin.Context) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Controller").
+		WithField(constants.Method, "GetCompanyHistory")
+
+	id := c.Param("id")
+	if id == "" {
+		c.AbortWithStatusJSON(errors.ErrBadRequest.HttpStatusCode, errors.ErrBadRequest)
+		return
+	}
+	limit, parseErr := parseLimit(c, constants.DefaultPageSize, constants.MaxPageSize)
+	if parseErr != nil {
+		c.AbortWithStatusJSON(errors.ErrInvalidQueryParams.HttpStatusCode, errors.ErrInvalidQueryParams)
+		return
+	}
+	offset, parseErr := parseOffset(c)
+	if parseErr != nil {
+		c.AbortWithStatusJSON(errors.ErrInvalidQueryParams.HttpStatusCode, errors.ErrInvalidQueryParams)
+		return
+	}
+
+	page, err := ctrl.svc.GetCompanyHistory(c, id, limit, offset)
+	if err != nil {
+		logger.Errorf("GetCompanyHistory - %s", err.Error())
+		c.AbortWithStatusJSON(err.HttpStatusCode, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// Company godoc
+// @Tags Company
+// @Summary revert a company
+// @Description sets a company back to the state of one of its revisions, the revert is recorded as a new revision, admins only
+// @Accept json
+// @Produce  json
+// @Success 200 {object} models.Company
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Param revertReq body dto.RevertReq true "request body"
+// @param authorization header string true "string" default(authorization)
+// @Param Idempotency-Key header string false "replays the stored response when a request is retried with the same key"
+// @Router /api/v1/company/:id/revert [POST]
+func (ctrl controller) RevertCompany(c *gin.Context) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Controller").
+		WithField(constants.Method, "RevertCompany")
+
+	id := c.Param("id")
+	if id == "" {
+		c.AbortWithStatusJSON(errors.ErrBadRequest.HttpStatusCode, errors.ErrBadRequest)
+		return
+	}
+
+	var revertReq dto.RevertReq
+	if err := c.ShouldBindJSON(&revertReq); err != nil || revertReq.Revision < 1 {
+		c.AbortWithStatusJSON(errors.ErrBadRequest.HttpStatusCode, errors.ErrBadRequest)
+		return
+	}
+
+	company, err := ctrl.svc.RevertCompany(c, id, revertReq.Revision)
+	if err != nil {
+		logger.Errorf("RevertCompany - %s", err.Error())
+		c.AbortWithStatusJSON(err.HttpStatusCode, err)
+		return
+	}
+
+	setETag(c, company.Version)
+	c.JSON(http.StatusOK, company)
+}
+
+// Company godoc
+// @Tags Company
+// @Summary company ancestors
+// @Description lists the parent of a company and the parents above it up to the root of its group, nearest first with their depth
+// @Accept json
+// @Produce  json
+// @Success 200 {array} models.CompanyNode
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Router /api/v1/company/:id/ancestors [GET]
+func (ctrl controller) GetCompanyAncestors(c *gin.Context) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Controller").
+		WithField(constants.Method, "GetCompanyAncestors")
+
+	id := c.Param("id")
+	if id == "" {
+		c.AbortWithStatusJSON(errors.ErrBadRequest.HttpStatusCode, errors.ErrBadRequest)
+		return
+	}
+
+	ancestors, err := ctrl.svc.GetCompanyAncestors(c, id)
+	if err != nil {
+		logger.Errorf("GetCompanyAncestors - %s", err.Error())
+		c.AbortWithStatusJSON(err.HttpStatusCode, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ancestors)
+}
+
+// Company godoc
+// @Tags Company
+// @Summary company children
+// @Description lists the direct subsidiaries of a company, ordered by name
+// @Accept json
+// @Produce  json
+// @Success 200 {array} models.Company
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Param limit query int false "page size" default(20)
+// @Param offset query int false "number of subsidiaries to skip" default(0)
+// @Router /api/v1/company/:id/children [GET]
+func (ctrl controller) ListCompanyChildren(c *gin.Context) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Controller").
+		WithField(constants.Method, "ListCompanyChildren")
+
+	id := c.Param("id")
+	if id == "" {
+		c.AbortWithStatusJSON(errors.ErrBadRequest.HttpStatusCode, errors.ErrBadRequest)
+		return
+	}
+	limit, limitErr := parseLimit(c, constants.DefaultPageSize, constants.MaxPageSize)
+	offset, offsetErr := parseOffset(c)
+	if limitErr != nil || offsetErr != nil {
+		c.AbortWithStatusJSON(errors.ErrInvalidQueryParams.HttpStatusCode, errors.ErrInvalidQueryParams)
+		return
+	}
+
+	children, err := ctrl.svc.ListCompanyChildren(c, id, limit, offset)
+	if err != nil {
+		logger.Errorf("ListCompanyChildren - %s", err.Error())
+		c.AbortWithStatusJSON(err.HttpStatusCode, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, children)
+}
+
+// Company godoc
+// @Tags Company
+// @Summary company subtree
+// @Description returns a company with all its subsidiaries, each nested under its parent
 // @Accept json
 // @Produce  json
+// @Success 200 {object} models.CompanyTree
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Router /api/v1/company/:id/subtree [GET]
+func (ctrl controller) GetCompanySubtree(c *gin.Context) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Controller").
+		WithField(constants.Method, "GetCompanySubtree")
+
+	id := c.Param("id")
+	if id == "" {
+		c.AbortWithStatusJSON(errors.ErrBadRequest.HttpStatusCode, errors.ErrBadRequest)
+		return
+	}
+
+	tree, err := ctrl.svc.GetCompanySubtree(c, id)
+	if err != nil {
+		logger.Errorf("GetCompanySubtree - %s", err.Error())
+		c.AbortWithStatusJSON(err.HttpStatusCode, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tree)
+}
+
+// Company godoc
+// @Tags Company
+// @Summary update a company
+// @Description update company by ID. An application/json body sets the given fields, except the id, an application/merge-patch+json body is an RFC 7396 JSON Merge Patch and an application/json-patch+json body an RFC 6902 JSON Patch (see patch.Operation) of the company document
+// @Accept json,application/merge-patch+json,application/json-patch+json
+// @Produce  json
 // @Success 200 {object} models.Company
+// @Header 200 {string} ETag "version of the company"
 // @Failure 400 {object} errors.ErrorResponse
 // @Failure 403 {object} errors.ErrorResponse
+// @Failure 409 {object} errors.ErrorResponse
+// @Failure 412 {object} errors.ErrorResponse
+// @Failure 422 {object} errors.ErrorResponse
 // @Failure 500 {object} errors.ErrorResponse
 // @Param updateReq body models.Company true "request body"
 // @param authorization header string true "string" default(authorization)
+// @Param Idempotency-Key header string false "replays the stored response when a request is retried with the same key"
+// @Param If-Match header string false "ETag of the company, the update fails with 412 when the company was changed since"
 // @Router /api/v1/company/:id [PATCH]
 func (ctrl controller) UpdateCompany(c *gin.Context) {
 	logger := logging.GetLogger(c).
@@ -163,8 +624,13 @@ func (ctrl controller) UpdateCompany(c *gin.Context) {
 		return
 	}
 
+	if patch.IsPatchContentType(c.ContentType()) {
+		ctrl.patchCompany(c, id)
+		return
+	}
+
 	var updateReq map[string]interface{}
-	if err := c.ShouldBindJSON(&updateReq); err != nil {
+	if err := c.ShouldBindJSON(&updateReq); err != nil || changesServerManagedField(updateReq) {
 		c.AbortWithStatusJSON(errors.ErrBadRequest.HttpStatusCode, errors.ErrBadRequest)
 		return
 	}
@@ -174,12 +640,548 @@ func (ctrl controller) UpdateCompany(c *gin.Context) {
 		return
 	}
 
-	company, err := ctrl.svc.UpdateCompany(c, id, updateReq)
+	company, err := ctrl.svc.UpdateCompany(c, id, updateReq, parseIfMatch(c))
 	if err != nil {
 		logger.Errorf("UpdateCompany - %s", err.Error())
 		c.AbortWithStatusJSON(err.HttpStatusCode, err)
 		return
 	}
 
+	setETag(c, company.Version)
 	c.JSON(http.StatusOK, company)
 }
+
+// Company godoc
+// @Tags Company
+// @Summary replace a company
+// @Description replace every field of the company with the request body, creating the company when none has the ID. A company in the trash must be restored before it is replaced
+// @Accept json
+// @Produce  json
+// @Success 200 {object} models.Company
+// @Success 201 {object} models.Company
+// @Header 200 {string} ETag "version of the company"
+// @Header 201 {string} Location "URL of the created company"
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 409 {object} errors.ErrorResponse
+// @Failure 412 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Param id path string true "company ID"
+// @Param ReplaceCompany body models.Company true "request body, the id is taken from the path"
+// @param authorization header string true "string" default(authorization)
+// @Param Idempotency-Key header string false "replays the stored response when a request is retried with the same key"
+// @Param If-Match header string false "ETag of the company, or * for any version, the replace fails with 412 when the company was changed since or does not exist"
+// @Router /api/v1/company/:id [PUT]
+func (ctrl controller) ReplaceCompany(c *gin.Context) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Controller").
+		WithField(constants.Method, "ReplaceCompany")
+
+	id := c.Param("id")
+	body, err := c.GetRawData()
+	if err != nil {
+		c.AbortWithStatusJSON(errors.ErrBadRequest.HttpStatusCode, errors.ErrBadRequest)
+		return
+	}
+	companyReq := models.Company{}
+	var fields map[string]interface{}
+	if json.Unmarshal(body, &companyReq) != nil || json.Unmarshal(body, &fields) != nil || (companyReq.ID != "" && companyReq.ID != id) {
+		c.AbortWithStatusJSON(errors.ErrBadRequest.HttpStatusCode, errors.ErrBadRequest)
+		return
+	}
+	companyReq.ID = id
+	// a replacement may set registered to false and amount_of_employees to 0, as PATCH can
+	if validationerr := utils.ValidateCompany(companyReq, fields); validationerr != nil {
+		c.AbortWithStatusJSON(errors.ErrValidationFailed.HttpStatusCode,
+			errors.NewErrorResponse(errors.ErrValidationFailed.HttpStatusCode, errors.ValidationFailed, validationerr.Error()))
+		return
+	}
+
+	company, created, errResp := ctrl.svc.ReplaceCompany(c, companyReq, parseIfMatch(c), ifMatchAny(c))
+	if errResp != nil {
+		logger.Errorf("ReplaceCompany - %s", errResp.Error())
+		c.AbortWithStatusJSON(errResp.HttpStatusCode, errResp)
+		return
+	}
+
+	setETag(c, company.Version)
+	if created {
+		c.Header("Location", "/api/v1/company/"+company.ID)
+		c.JSON(http.StatusCreated, company)
+		return
+	}
+	c.JSON(http.StatusOK, company)
+}
+
+// patchCompany applies a JSON Merge Patch or JSON Patch body, chosen by its content type.
+func (ctrl controller) patchCompany(c *gin.Context, id string) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Controller").
+		WithField(constants.Method, "PatchCompany")
+
+	body, err := c.GetRawData()
+	if err != nil {
+		c.AbortWithStatusJSON(errors.ErrBadRequest.HttpStatusCode, errors.ErrBadRequest)
+		return
+	}
+	companyPatch, err := patch.NewPatch(c.ContentType(), body)
+	if err != nil {
+		c.AbortWithStatusJSON(errors.ErrBadRequest.HttpStatusCode,
+			errors.NewErrorResponse(errors.ErrBadRequest.HttpStatusCode, errors.BadRequest, err.Error()))
+		return
+	}
+
+	company, errResp := ctrl.svc.PatchCompany(c, id, companyPatch, parseIfMatch(c))
+	if errResp != nil {
+		logger.Errorf("PatchCompany - %s", errResp.Error())
+		c.AbortWithStatusJSON(errResp.HttpStatusCode, errResp)
+		return
+	}
+
+	setETag(c, company.Version)
+	c.JSON(http.StatusOK, company)
+}
+
+// Company godoc
+// @Tags Company
+// @Summary update companies in bulk
+// @Description apply the same changes to companies selected by ids and/or filter, in one transaction
+// @Accept json
+// @Produce  json
+// @Success 200 {object} models.BulkChangeResult
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Param updateReq body dto.BulkUpdateReq true "request body"
+// @param authorization header string true "string" default(authorization)
+// @Param Idempotency-Key header string false "replays the stored response when a request is retried with the same key"
+// @Router /api/v1/company/bulk [PATCH]
+func (ctrl controller) UpdateCompanies(c *gin.Context) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Controller").
+		WithField(constants.Method, "UpdateCompanies")
+
+	var updateReq dto.BulkUpdateReq
+	if err := c.ShouldBindJSON(&updateReq); err != nil {
+		c.AbortWithStatusJSON(errors.ErrBadRequest.HttpStatusCode, errors.ErrBadRequest)
+		return
+	}
+	if !validSelector(updateReq.CompanySelector) {
+		c.AbortWithStatusJSON(errors.ErrInvalidSelector.HttpStatusCode, errors.ErrInvalidSelector)
+		return
+	}
+	if len(updateReq.Changes) == 0 || changesServerManagedField(updateReq.Changes) {
+		c.AbortWithStatusJSON(errors.ErrBadRequest.HttpStatusCode, errors.ErrBadRequest)
+		return
+	}
+	_, validationerr := govalidator.ValidateMap(updateReq.Changes, utils.GetMapValidations())
+	if validationerr != nil {
+		c.AbortWithStatusJSON(errors.ErrValidationFailed.HttpStatusCode,
+			errors.NewErrorResponse(errors.ErrValidationFailed.HttpStatusCode, errors.ValidationFailed, validationerr.Error()))
+		return
+	}
+
+	result, err := ctrl.svc.UpdateCompanies(c, updateReq.CompanySelector, updateReq.Changes)
+	if err != nil {
+		logger.Errorf("UpdateCompanies - %s", err.Error())
+		c.AbortWithStatusJSON(err.HttpStatusCode, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Company godoc
+// @Tags Company
+// @Summary delete companies in bulk
+// @Description delete companies selected by ids and/or filter, in one transaction
+// @Accept json
+// @Produce  json
+// @Success 200 {object} models.BulkChangeResult
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Param deleteReq body dto.BulkDeleteReq true "request body"
+// @param authorization header string true "string" default(authorization)
+// @Param Idempotency-Key header string false "replays the stored response when a request is retried with the same key"
+// @Router /api/v1/company/bulk [DELETE]
+func (ctrl controller) DeleteCompanies(c *gin.Context) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Controller").
+		WithField(constants.Method, "DeleteCompanies")
+
+	var deleteReq dto.BulkDeleteReq
+	if err := c.ShouldBindJSON(&deleteReq); err != nil {
+		c.AbortWithStatusJSON(errors.ErrBadRequest.HttpStatusCode, errors.ErrBadRequest)
+		return
+	}
+	if !validSelector(deleteReq.CompanySelector) {
+		c.AbortWithStatusJSON(errors.ErrInvalidSelector.HttpStatusCode, errors.ErrInvalidSelector)
+		return
+	}
+
+	result, err := ctrl.svc.DeleteCompanies(c, deleteReq.CompanySelector)
+	if err != nil {
+		logger.Errorf("DeleteCompanies - %s", err.Error())
+		c.AbortWithStatusJSON(err.HttpStatusCode, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// validSelector rejects selectors that would match every company or carry malformed ids.
+func validSelector(selector models.CompanySelector) bool {
+	if len(selector.IDs) == 0 && selector.Filter.IsEmpty() {
+		return false
+	}
+	if len(selector.IDs) > constants.MaxBatchSize {
+		return false
+	}
+	for _, id := range selector.IDs {
+		if !govalidator.IsUUID(id) {
+			return false
+		}
+	}
+	return true
+}
+
+// changesServerManagedField reports whether a patch tries to set the id, which contacts,
+// locations, shareholdings and subsidiaries refer to, or a field only the database writes.
+func changesServerManagedField(changes map[string]interface{}) bool {
+	for _, field := range []string{"id", "version", "created_at", "updated_at"} {
+		if _, ok := changes[field]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Company godoc
+// @Tags Company
+// @Summary list companies
+// @Description list companies with filters, sorting and keyset pagination, with as_of the companies as they were at that time and the revision each was read from (models.CompanyVersionPage), with fields or include the companies reduced to those fields and extended with the related resources (models.ShapedCompanyPage)
+// @Accept json
+// @Produce  json
+// @Success 200 {object} models.CompanyPage
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Param type query string false "comma separated company types"
+// @Param registered query bool false "registered companies only"
+// @Param min_amount_of_employees query int false "minimum amount of employees"
+// @Param max_amount_of_employees query int false "maximum amount of employees"
+// @Param min_created_at query string false "RFC 3339 time the companies were created at or after"
+// @Param max_created_at query string false "RFC 3339 time the companies were created at or before"
+// @Param min_updated_at query string false "RFC 3339 time the companies were last changed at or after"
+// @Param max_updated_at query string false "RFC 3339 time the companies were last changed at or before"
+// @Param sort query string false "column to sort by, prefixed with - for descending order" default(id)
+// @Param limit query int false "page size" default(20)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param as_of query string false "RFC 3339 point in time, e.g. 2023-01-31T00:00:00Z"
+// @Param fields query string false "comma separated fields to return, the id is always returned"
+// @Param include query string false "comma separated related resources to embed: history, which requires the authorization header"
+// @param authorization header string false "string, required with include=history"
+// @Router /api/v1/company [GET]
+func (ctrl controller) ListCompanies(c *gin.Context) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Controller").
+		WithField(constants.Method, "ListCompanies")
+
+	query, parseErr := parseListQuery(c)
+	if parseErr != nil {
+		logger.Errorf("ListCompanies - %s", parseErr.Error())
+		c.AbortWithStatusJSON(errors.ErrInvalidQueryParams.HttpStatusCode, errors.ErrInvalidQueryParams)
+		return
+	}
+	shape, parseErr := parseResponseShape(c)
+	if parseErr != nil {
+		logger.Errorf("ListCompanies - %s", parseErr.Error())
+		c.AbortWithStatusJSON(errors.ErrInvalidQueryParams.HttpStatusCode, errors.ErrInvalidQueryParams)
+		return
+	}
+	query.Fields = shape.Fields
+
+	if query.AsOf != nil {
+		page, err := ctrl.svc.ListCompaniesAsOf(c, query)
+		if err != nil {
+			logger.Errorf("ListCompanies - %s", err.Error())
+			c.AbortWithStatusJSON(err.HttpStatusCode, err)
+			return
+		}
+		ctrl.renderCompanyPage(c, page, shape)
+		return
+	}
+
+	page, err := ctrl.svc.ListCompanies(c, query)
+	if err != nil {
+		logger.Errorf("ListCompanies - %s", err.Error())
+		c.AbortWithStatusJSON(err.HttpStatusCode, err)
+		return
+	}
+
+	setCacheControl(c)
+	ctrl.renderCompanyPage(c, page, shape)
+}
+
+// Company godoc
+// @Tags Company
+// @Summary company statistics
+// @Description count the companies matching the list filters by registration, by the group_by column and in amount_of_employees buckets, cached for STATS_CACHE_TTL
+// @Accept json
+// @Produce  json
+// @Success 200 {object} models.CompanyStats
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Param type query string false "comma separated company types"
+// @Param registered query bool false "registered companies only"
+// @Param min_amount_of_employees query int false "minimum amount of employees"
+// @Param max_amount_of_employees query int false "maximum amount of employees"
+// @Param min_created_at query string false "RFC 3339 time the companies were created at or after"
+// @Param max_created_at query string false "RFC 3339 time the companies were created at or before"
+// @Param min_updated_at query string false "RFC 3339 time the companies were last changed at or after"
+// @Param max_updated_at query string false "RFC 3339 time the companies were last changed at or before"
+// @Param group_by query string false "column to count the companies by: type or registered" default(type)
+// @Param buckets query string false "comma separated ascending lower bounds of the amount_of_employees buckets" default(0,10,50,250,1000)
+// @Router /api/v1/company/stats [GET]
+func (ctrl controller) GetCompanyStats(c *gin.Context) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Controller").
+		WithField(constants.Method, "GetCompanyStats")
+
+	query, parseErr := parseStatsQuery(c)
+	if parseErr != nil {
+		logger.Errorf("GetCompanyStats - %s", parseErr.Error())
+		c.AbortWithStatusJSON(errors.ErrInvalidQueryParams.HttpStatusCode, errors.ErrInvalidQueryParams)
+		return
+	}
+
+	stats, err := ctrl.svc.GetCompanyStats(c, query)
+	if err != nil {
+		logger.Errorf("GetCompanyStats - %s", err.Error())
+		c.AbortWithStatusJSON(err.HttpStatusCode, err)
+		return
+	}
+
+	setCacheControl(c)
+	c.JSON(http.StatusOK, stats)
+}
+
+// Company godoc
+// @Tags Company
+// @Summary search companies
+// @Description full-text search over company name and description, ranked by relevance
+// @Accept json
+// @Produce  json
+// @Success 200 {array} models.CompanySearchResult
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Param q query string true "search text, supports quoted phrases, or and -word"
+// @Param limit query int false "page size" default(20)
+// @Param offset query int false "number of results to skip" default(0)
+// @Router /api/v1/company/search [GET]
+func (ctrl controller) SearchCompanies(c *gin.Context) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Controller").
+		WithField(constants.Method, "SearchCompanies")
+
+	text := strings.TrimSpace(c.Query("q"))
+	if text == "" {
+		c.AbortWithStatusJSON(errors.ErrInvalidQueryParams.HttpStatusCode, errors.ErrInvalidQueryParams)
+		return
+	}
+	limit, limitErr := parseLimit(c, constants.DefaultPageSize, constants.MaxPageSize)
+	offset, offsetErr := parseOffset(c)
+	if limitErr != nil || offsetErr != nil {
+		c.AbortWithStatusJSON(errors.ErrInvalidQueryParams.HttpStatusCode, errors.ErrInvalidQueryParams)
+		return
+	}
+
+	results, err := ctrl.svc.SearchCompanies(c, text, limit, offset)
+	if err != nil {
+		logger.Errorf("SearchCompanies - %s", err.Error())
+		c.AbortWithStatusJSON(err.HttpStatusCode, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
+// Company godoc
+// @Tags Company
+// @Summary suggest company names
+// @Description typo tolerant autocomplete of company names, prefix matches first then by trigram similarity
+// @Accept json
+// @Produce  json
+// @Success 200 {array} models.CompanySuggestion
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Param q query string true "typed part of the name"
+// @Param limit query int false "number of suggestions" default(10)
+// @Router /api/v1/company/suggest [GET]
+func (ctrl controller) SuggestCompanies(c *gin.Context) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Controller").
+		WithField(constants.Method, "SuggestCompanies")
+
+	prefix := strings.TrimSpace(c.Query("q"))
+	if prefix == "" || len(prefix) > constants.MaxSuggestionLength {
+		c.AbortWithStatusJSON(errors.ErrInvalidQueryParams.HttpStatusCode, errors.ErrInvalidQueryParams)
+		return
+	}
+	limit, parseErr := parseLimit(c, constants.DefaultSuggestionLimit, constants.MaxSuggestionLimit)
+	if parseErr != nil {
+		c.AbortWithStatusJSON(errors.ErrInvalidQueryParams.HttpStatusCode, errors.ErrInvalidQueryParams)
+		return
+	}
+
+	suggestions, err := ctrl.svc.SuggestCompanies(c, prefix, limit)
+	if err != nil {
+		logger.Errorf("SuggestCompanies - %s", err.Error())
+		c.AbortWithStatusJSON(err.HttpStatusCode, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, suggestions)
+}
+
+// Company godoc
+// @Tags Company
+// @Summary import companies
+// @Description streams a CSV or NDJSON upload row by row, writes every valid row and reports the lines that failed
+// @Accept text/csv,application/x-ndjson
+// @Produce  json
+// @Success 200 {object} models.ImportReport
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Param ImportCompanies body string true "CSV with a header row, or one JSON company per line"
+// @Param format query string false "csv or ndjson, taken from the Content-Type when omitted"
+// @Param on_conflict query string false "skip, overwrite or fail. overwrite matches a row by its ID, or by its name ignoring case when it has none, and does not overwrite companies in the trash" default(fail)
+// @param authorization header string true "string" default(authorization)
+// @Router /api/v1/company/import [POST]
+func (ctrl controller) ImportCompanies(c *gin.Context) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Controller").
+		WithField(constants.Method, "ImportCompanies")
+
+	format := c.DefaultQuery("format", mediaTypeFormat(c.ContentType()))
+	onConflict := c.DefaultQuery("on_conflict", models.ImportConflictFail)
+	if onConflict != models.ImportConflictSkip && onConflict != models.ImportConflictOverwrite && onConflict != models.ImportConflictFail {
+		c.AbortWithStatusJSON(errors.ErrInvalidQueryParams.HttpStatusCode, errors.ErrInvalidQueryParams)
+		return
+	}
+	if format != codec.CSV && format != codec.NDJSON {
+		c.AbortWithStatusJSON(errors.ErrInvalidQueryParams.HttpStatusCode, errors.ErrInvalidQueryParams)
+		return
+	}
+
+	decoder, decodeErr := codec.NewCompanyDecoder(format, c.Request.Body)
+	if decodeErr != nil {
+		logger.Errorf("ImportCompanies - %s", decodeErr.Error())
+		c.AbortWithStatusJSON(errors.ErrInvalidImportFile.HttpStatusCode, errors.NewErrorResponse(errors.ErrInvalidImportFile.HttpStatusCode, errors.InvalidImportFile, decodeErr.Error()))
+		return
+	}
+
+	report, err := ctrl.svc.ImportCompanies(c, decoder, onConflict)
+	if err != nil {
+		logger.Errorf("ImportCompanies - %s", err.Error())
+		c.AbortWithStatusJSON(err.HttpStatusCode, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// Company godoc
+// @Tags Company
+// @Summary export companies
+// @Description streams every company matching the list filters, in the format chosen by the format parameter or the Accept header
+// @Accept json
+// @Produce  json,text/csv,application/x-ndjson
+// @Success 200 {array} models.Company
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 406 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Param format query string false "csv, ndjson or json, takes precedence over the Accept header"
+// @Param type query string false "comma separated company types"
+// @Param registered query bool false "registered companies only"
+// @Param min_amount_of_employees query int false "minimum amount of employees"
+// @Param max_amount_of_employees query int false "maximum amount of employees"
+// @Param min_created_at query string false "RFC 3339 time the companies were created at or after"
+// @Param max_created_at query string false "RFC 3339 time the companies were created at or before"
+// @Param min_updated_at query string false "RFC 3339 time the companies were last changed at or after"
+// @Param max_updated_at query string false "RFC 3339 time the companies were last changed at or before"
+// @Param sort query string false "column to sort by, prefixed with - for descending order" default(id)
+// @Router /api/v1/company/export [GET]
+func (ctrl controller) ExportCompanies(c *gin.Context) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Controller").
+		WithField(constants.Method, "ExportCompanies")
+
+	query, parseErr := parseExportQuery(c)
+	if parseErr != nil {
+		logger.Errorf("ExportCompanies - %s", parseErr.Error())
+		c.AbortWithStatusJSON(errors.ErrInvalidQueryParams.HttpStatusCode, errors.ErrInvalidQueryParams)
+		return
+	}
+
+	format, ok := c.GetQuery("format")
+	if !ok {
+		format = mediaTypeFormat(c.NegotiateFormat(codec.ContentType(codec.JSON), codec.ContentType(codec.CSV), codec.ContentType(codec.NDJSON)))
+	}
+	encoder, encodeErr := codec.NewCompanyEncoder(format, flushWriter{c.Writer})
+	if encodeErr != nil {
+		c.AbortWithStatusJSON(errors.ErrNotAcceptable.HttpStatusCode, errors.ErrNotAcceptable)
+		return
+	}
+
+	c.Header("Content-Type", codec.ContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="companies.%s"`, format))
+	c.Status(http.StatusOK)
+
+	if err := ctrl.svc.ExportCompanies(c, query, encoder); err != nil {
+		logger.Errorf("ExportCompanies - %s", err.Error())
+		// once rows were streamed the status can no longer change, the body is left incomplete
+		if !c.Writer.Written() {
+			c.Writer.Header().Del("Content-Type")
+			c.Writer.Header().Del("Content-Disposition")
+			c.AbortWithStatusJSON(err.HttpStatusCode, err)
+			return
+		}
+		c.Abort()
+	}
+}
+
+// mediaTypeFormat maps a media type to the import or export format it names.
+func mediaTypeFormat(mediaType string) string {
+	switch mediaType {
+	case "text/csv", "application/csv":
+		return codec.CSV
+	case "application/x-ndjson", "application/jsonl", "application/x-jsonlines":
+		return codec.NDJSON
+	case "application/json":
+		return codec.JSON
+	}
+	return ""
+}
+
+// flushWriter sends every write on to the client, so buffered rows are not held back.
+type flushWriter struct {
+	gin.ResponseWriter
+}
+
+func (w flushWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.ResponseWriter.Flush()
+	return n, err
+}
@@ -0,0 +1,283 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+	"github.com/kumareswaramoorthi/companies/api/constants"
+	"github.com/kumareswaramoorthi/companies/api/errors"
+	"github.com/kumareswaramoorthi/companies/api/logging"
+	"github.com/kumareswaramoorthi/companies/api/models"
+	service "github.com/kumareswaramoorthi/companies/api/service"
+)
+
+type ContactController interface {
+	ListContacts(c *gin.Context)
+	GetContact(c *gin.Context)
+	CreateContact(c *gin.Context)
+	ReplaceContact(c *gin.Context)
+	DeleteContact(c *gin.Context)
+	SearchContacts(c *gin.Context)
+}
+
+type contactController struct {
+	svc service.Contact
+}
+
+func NewContactController(svc service.Contact) ContactController {
+	return &contactController{svc: svc}
+}
+
+// Contact godoc
+// @Tags Contact
+// @Summary company contacts
+// @Description lists the people at a company, ordered by name
+// @Accept json
+// @Produce  json
+// @Success 200 {array} models.Contact
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Param id path string true "company ID"
+// @Param limit query int false "page size" default(20)
+// @Param offset query int false "number of contacts to skip" default(0)
+// @param authorization header string true "string" default(authorization)
+// @Router /api/v1/company/:id/contacts [GET]
+func (ctrl contactController) ListContacts(c *gin.Context) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Controller").
+		WithField(constants.Method, "ListContacts")
+
+	id := c.Param("id")
+	if id == "" {
+		c.AbortWithStatusJSON(errors.ErrBadRequest.HttpStatusCode, errors.ErrBadRequest)
+		return
+	}
+	limit, limitErr := parseLimit(c, constants.DefaultPageSize, constants.MaxPageSize)
+	offset, offsetErr := parseOffset(c)
+	if limitErr != nil || offsetErr != nil {
+		c.AbortWithStatusJSON(errors.ErrInvalidQueryParams.HttpStatusCode, errors.ErrInvalidQueryParams)
+		return
+	}
+
+	contacts, err := ctrl.svc.ListContacts(c, id, limit, offset)
+	if err != nil {
+		logger.Errorf("ListContacts - %s", err.Error())
+		c.AbortWithStatusJSON(err.HttpStatusCode, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, contacts)
+}
+
+// Contact godoc
+// @Tags Contact
+// @Summary get a company contact
+// @Description get a person at a company by the contact ID
+// @Accept json
+// @Produce  json
+// @Success 200 {object} models.Contact
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Param id path string true "company ID"
+// @Param contact_id path string true "contact ID"
+// @param authorization header string true "string" default(authorization)
+// @Router /api/v1/company/:id/contacts/:contact_id [GET]
+func (ctrl contactController) GetContact(c *gin.Context) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Controller").
+		WithField(constants.Method, "GetContact")
+
+	id, contactID := c.Param("id"), c.Param("contact_id")
+	if id == "" || contactID == "" {
+		c.AbortWithStatusJSON(errors.ErrBadRequest.HttpStatusCode, errors.ErrBadRequest)
+		return
+	}
+
+	contact, err := ctrl.svc.GetContact(c, id, contactID)
+	if err != nil {
+		logger.Errorf("GetContact - %s", err.Error())
+		c.AbortWithStatusJSON(err.HttpStatusCode, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, contact)
+}
+
+// Contact godoc
+// @Tags Contact
+// @Summary create a company contact
+// @Description adds a person at a company. The email is unique within the company, the phone is an international number, written with or without spaces, dots, dashes and parentheses and stored in E.164 format
+// @Accept json
+// @Produce  json
+// @Success 201 {object} models.Contact
+// @Header 201 {string} Location "URL of the created contact"
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 409 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Param id path string true "company ID"
+// @Param CreateContact body models.Contact true "request body, the company is taken from the path"
+// @param authorization header string true "string" default(authorization)
+// @Param Idempotency-Key header string false "replays the stored response when a request is retried with the same key"
+// @Router /api/v1/company/:id/contacts [POST]
+func (ctrl contactController) CreateContact(c *gin.Context) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Controller").
+		WithField(constants.Method, "CreateContact")
+
+	id := c.Param("id")
+	if id == "" {
+		c.AbortWithStatusJSON(errors.ErrBadRequest.HttpStatusCode, errors.ErrBadRequest)
+		return
+	}
+
+	contactReq := models.Contact{}
+	if err := c.ShouldBindJSON(&contactReq); err != nil {
+		c.AbortWithStatusJSON(errors.ErrBadRequest.HttpStatusCode, errors.ErrBadRequest)
+		return
+	}
+	contactReq.CompanyID = id
+
+	contact, err := ctrl.svc.CreateContact(c, contactReq)
+	if err != nil {
+		logger.Errorf("CreateContact - %s", err.Error())
+		c.AbortWithStatusJSON(err.HttpStatusCode, err)
+		return
+	}
+
+	c.Header("Location", "/api/v1/company/"+id+"/contacts/"+contact.ID)
+	c.JSON(http.StatusCreated, contact)
+}
+
+// Contact godoc
+// @Tags Contact
+// @Summary replace a company contact
+// @Description replaces every field of a contact of a company with the request body
+// @Accept json
+// @Produce  json
+// @Success 200 {object} models.Contact
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 409 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Param id path string true "company ID"
+// @Param contact_id path string true "contact ID"
+// @Param ReplaceContact body models.Contact true "request body, the ids are taken from the path"
+// @param authorization header string true "string" default(authorization)
+// @Param Idempotency-Key header string false "replays the stored response when a request is retried with the same key"
+// @Router /api/v1/company/:id/contacts/:contact_id [PUT]
+func (ctrl contactController) ReplaceContact(c *gin.Context) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Controller").
+		WithField(constants.Method, "ReplaceContact")
+
+	id, contactID := c.Param("id"), c.Param("contact_id")
+	if id == "" || contactID == "" {
+		c.AbortWithStatusJSON(errors.ErrBadRequest.HttpStatusCode, errors.ErrBadRequest)
+		return
+	}
+
+	contactReq := models.Contact{}
+	if err := c.ShouldBindJSON(&contactReq); err != nil {
+		c.AbortWithStatusJSON(errors.ErrBadRequest.HttpStatusCode, errors.ErrBadRequest)
+		return
+	}
+	contactReq.ID, contactReq.CompanyID = contactID, id
+
+	contact, err := ctrl.svc.ReplaceContact(c, contactReq)
+	if err != nil {
+		logger.Errorf("ReplaceContact - %s", err.Error())
+		c.AbortWithStatusJSON(err.HttpStatusCode, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, contact)
+}
+
+// Contact godoc
+// @Tags Contact
+// @Summary delete a company contact
+// @Description removes a person at a company
+// @Accept json
+// @Produce  json
+// @Success 200 {string} successfully deleted contact
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Param id path string true "company ID"
+// @Param contact_id path string true "contact ID"
+// @param authorization header string true "string" default(authorization)
+// @Param Idempotency-Key header string false "replays the stored response when a request is retried with the same key"
+// @Router /api/v1/company/:id/contacts/:contact_id [DELETE]
+func (ctrl contactController) DeleteContact(c *gin.Context) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Controller").
+		WithField(constants.Method, "DeleteContact")
+
+	id, contactID := c.Param("id"), c.Param("contact_id")
+	if id == "" || contactID == "" {
+		c.AbortWithStatusJSON(errors.ErrBadRequest.HttpStatusCode, errors.ErrBadRequest)
+		return
+	}
+
+	if err := ctrl.svc.DeleteContact(c, id, contactID); err != nil {
+		logger.Errorf("DeleteContact - %s", err.Error())
+		c.AbortWithStatusJSON(err.HttpStatusCode, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, fmt.Sprintf("successfully deleted contact with id: %s", contactID))
+}
+
+// Contact godoc
+// @Tags Contact
+// @Summary search contacts
+// @Description finds the contacts whose name, role or email contains the search text, ignoring case, across all companies
+// @Accept json
+// @Produce  json
+// @Success 200 {array} models.ContactSearchResult
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Param q query string true "search text"
+// @Param limit query int false "page size" default(20)
+// @Param offset query int false "number of results to skip" default(0)
+// @param authorization header string true "string" default(authorization)
+// @Router /api/v1/contacts/search [GET]
+func (ctrl contactController) SearchContacts(c *gin.Context) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Controller").
+		WithField(constants.Method, "SearchContacts")
+
+	text := strings.TrimSpace(c.Query("q"))
+	if text == "" || len(text) > constants.MaxContactQueryLength {
+		c.AbortWithStatusJSON(errors.ErrInvalidQueryParams.HttpStatusCode, errors.ErrInvalidQueryParams)
+		return
+	}
+	limit, limitErr := parseLimit(c, constants.DefaultPageSize, constants.MaxPageSize)
+	offset, offsetErr := parseOffset(c)
+	if limitErr != nil || offsetErr != nil {
+		c.AbortWithStatusJSON(errors.ErrInvalidQueryParams.HttpStatusCode, errors.ErrInvalidQueryParams)
+		return
+	}
+
+	results, err := ctrl.svc.SearchContacts(c, text, limit, offset)
+	if err != nil {
+		logger.Errorf("SearchContacts - %s", err.Error())
+		c.AbortWithStatusJSON(err.HttpStatusCode, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
@@ -0,0 +1,97 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kumareswaramoorthi/companies/api/constants"
+	"github.com/kumareswaramoorthi/companies/api/models"
+	"github.com/kumareswaramoorthi/companies/api/utils"
+)
+
+// setETag tags a company response with the company version.
+func setETag(c *gin.Context, version int) {
+	c.Header(constants.ETagHeader, fmt.Sprintf(`"%d"`, version))
+}
+
+// setCacheHeaders sets the validators and the Cache-Control header of a public company read.
+// A read reduced or extended by the fields and include query parameters is another
+// representation of the same version, its ETag is weak so that If-Match, which only
+// accepts strong ETags, never takes it for the full company.
+func setCacheHeaders(c *gin.Context, company models.Company, shape responseShape) {
+	if shape.isEmpty() {
+		setETag(c, company.Version)
+	} else {
+		c.Header(constants.ETagHeader, fmt.Sprintf(`W/"%d"`, company.Version))
+	}
+	c.Header(constants.LastModifiedHeader, company.UpdatedAt.UTC().Format(http.TimeFormat))
+	setCacheControl(c)
+}
+
+// setCacheControl lets browsers and CDNs cache a public read, as configured by the
+// CACHE_CONTROL environment variable.
+func setCacheControl(c *gin.Context) {
+	c.Header(constants.CacheControlHeader, utils.GetEnvVars("CACHE_CONTROL", constants.DefaultCacheControl))
+}
+
+// notModified reports whether the copy of the company the client holds is current, from
+// the If-None-Match header or, without it, the If-Modified-Since header.
+func notModified(c *gin.Context, company models.Company) bool {
+	if header := strings.TrimSpace(c.GetHeader(constants.IfNoneMatchHeader)); header != "" {
+		if header == "*" {
+			return true
+		}
+		// If-None-Match uses the weak comparison
+		for _, tag := range strings.Split(header, ",") {
+			if version, ok := etagVersion(strings.TrimPrefix(strings.TrimSpace(tag), "W/")); ok && version == company.Version {
+				return true
+			}
+		}
+		return false
+	}
+
+	since, err := http.ParseTime(c.GetHeader(constants.IfModifiedSinceHeader))
+	if err != nil {
+		return false
+	}
+	// Last-Modified is sent with a precision of seconds
+	return !company.UpdatedAt.Truncate(time.Second).After(since)
+}
+
+// parseIfMatch reads the company versions accepted by the If-Match header. It returns nil
+// when the header is missing or "*", so the write is unconditional. ETags that are not a
+// company version can never match and are left out, which may leave the list empty.
+func parseIfMatch(c *gin.Context) []int {
+	header := strings.TrimSpace(c.GetHeader(constants.IfMatchHeader))
+	if header == "" || header == "*" {
+		return nil
+	}
+
+	versions := []int{}
+	for _, tag := range strings.Split(header, ",") {
+		// If-Match uses the strong comparison, weak ETags never match
+		if version, ok := etagVersion(strings.TrimSpace(tag)); ok {
+			versions = append(versions, version)
+		}
+	}
+	return versions
+}
+
+// etagVersion returns the company version of a strong ETag set by setETag.
+func etagVersion(tag string) (int, bool) {
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, false
+	}
+	version, err := strconv.Atoi(tag[1 : len(tag)-1])
+	return version, err == nil
+}
+
+// ifMatchAny reports whether the If-Match header is "*", which matches any current
+// version of the company but never a missing one.
+func ifMatchAny(c *gin.Context) bool {
+	return strings.TrimSpace(c.GetHeader(constants.IfMatchHeader)) == "*"
+}
@@ -0,0 +1,146 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kumareswaramoorthi/companies/api/constants"
+	"github.com/kumareswaramoorthi/companies/api/errors"
+	"github.com/kumareswaramoorthi/companies/api/models"
+	"github.com/kumareswaramoorthi/companies/api/utils"
+)
+
+// responseShape holds the fields and include query parameters of a company read.
+type responseShape struct {
+	Fields  []string
+	Include []string
+}
+
+// isEmpty reports whether the companies are answered in full, without related resources.
+func (s responseShape) isEmpty() bool {
+	return len(s.Fields) == 0 && len(s.Include) == 0
+}
+
+// parseResponseShape reads the comma separated fields and include query parameters.
+func parseResponseShape(c *gin.Context) (responseShape, error) {
+	var shape responseShape
+	var err error
+	if shape.Fields, err = listQuery(c, "fields", utils.GetSelectableFields()); err != nil {
+		return shape, err
+	}
+	shape.Include, err = listQuery(c, "include", utils.GetIncludableResources())
+	return shape, err
+}
+
+func listQuery(c *gin.Context, key string, allowed map[string]bool) ([]string, error) {
+	var values []string
+	for _, value := range strings.Split(c.Query(key), ",") {
+		if value = strings.TrimSpace(value); value == "" {
+			continue
+		}
+		if !allowed[value] {
+			return nil, fmt.Errorf("invalid %s value [%s]", key, value)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// shapeCompanies renders companies with only the requested fields and embeds the requested
+// related resources. The id is always kept, as are the revision and revision_at of
+// companies read as_of a point in time.
+func (ctrl controller) shapeCompanies(c *gin.Context, companies interface{}, ids []string, shape responseShape) ([]map[string]interface{}, *errors.ErrorResponse) {
+	raw, err := json.Marshal(companies)
+	if err != nil {
+		return nil, errors.ErrInternalServerError
+	}
+	var shaped []map[string]interface{}
+	if err := json.Unmarshal(raw, &shaped); err != nil {
+		return nil, errors.ErrInternalServerError
+	}
+
+	if len(shape.Fields) > 0 {
+		keep := map[string]bool{"id": true, "revision": true, "revision_at": true}
+		for _, field := range shape.Fields {
+			keep[field] = true
+		}
+		for _, company := range shaped {
+			for field := range company {
+				if !keep[field] {
+					delete(company, field)
+				}
+			}
+		}
+	}
+
+	for _, resource := range shape.Include {
+		switch resource {
+		case constants.IncludeHistory:
+			history, errResp := ctrl.svc.GetCompaniesHistory(c, ids, constants.DefaultPageSize)
+			if errResp != nil {
+				return nil, errResp
+			}
+			for i, company := range shaped {
+				company[resource] = history[ids[i]]
+			}
+		}
+	}
+	return shaped, nil
+}
+
+// shapeCompanyPage applies shapeCompanies to the companies of a list page.
+func (ctrl controller) shapeCompanyPage(c *gin.Context, page interface{}, shape responseShape) (models.ShapedCompanyPage, *errors.ErrorResponse) {
+	var shaped models.ShapedCompanyPage
+	var companies interface{}
+	var ids []string
+
+	switch page := page.(type) {
+	case models.CompanyPage:
+		shaped.NextCursor, shaped.TotalCount = page.NextCursor, page.TotalCount
+		companies = page.Data
+		for _, company := range page.Data {
+			ids = append(ids, company.ID)
+		}
+	case models.CompanyVersionPage:
+		shaped.NextCursor, shaped.TotalCount, shaped.AsOf = page.NextCursor, page.TotalCount, &page.AsOf
+		companies = page.Data
+		for _, company := range page.Data {
+			ids = append(ids, company.ID)
+		}
+	}
+
+	var errResp *errors.ErrorResponse
+	shaped.Data, errResp = ctrl.shapeCompanies(c, companies, ids, shape)
+	return shaped, errResp
+}
+
+// renderCompany writes a company, shaped when fields or include were requested.
+func (ctrl controller) renderCompany(c *gin.Context, company interface{}, id string, shape responseShape) {
+	if shape.isEmpty() {
+		c.JSON(http.StatusOK, company)
+		return
+	}
+	shaped, err := ctrl.shapeCompanies(c, []interface{}{company}, []string{id}, shape)
+	if err != nil {
+		c.AbortWithStatusJSON(err.HttpStatusCode, err)
+		return
+	}
+	c.JSON(http.StatusOK, shaped[0])
+}
+
+// renderCompanyPage writes a page of companies, shaped when fields or include were requested.
+func (ctrl controller) renderCompanyPage(c *gin.Context, page interface{}, shape responseShape) {
+	if shape.isEmpty() {
+		c.JSON(http.StatusOK, page)
+		return
+	}
+	shaped, err := ctrl.shapeCompanyPage(c, page, shape)
+	if err != nil {
+		c.AbortWithStatusJSON(err.HttpStatusCode, err)
+		return
+	}
+	c.JSON(http.StatusOK, shaped)
+}
@@ -0,0 +1,265 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+	"github.com/kumareswaramoorthi/companies/api/constants"
+	"github.com/kumareswaramoorthi/companies/api/errors"
+	"github.com/kumareswaramoorthi/companies/api/logging"
+	"github.com/kumareswaramoorthi/companies/api/models"
+	service "github.com/kumareswaramoorthi/companies/api/service"
+)
+
+type LocationController interface {
+	ListLocations(c *gin.Context)
+	GetLocation(c *gin.Context)
+	CreateLocation(c *gin.Context)
+	ReplaceLocation(c *gin.Context)
+	DeleteLocation(c *gin.Context)
+	ListNearbyCompanies(c *gin.Context)
+}
+
+type locationController struct {
+	svc service.Location
+}
+
+func NewLocationController(svc service.Location) LocationController {
+	return &locationController{svc: svc}
+}
+
+// Location godoc
+// @Tags Location
+// @Summary company locations
+// @Description lists the addresses of a company, its headquarters first
+// @Accept json
+// @Produce  json
+// @Success 200 {array} models.Location
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Param id path string true "company ID"
+// @Router /api/v1/company/:id/locations [GET]
+func (ctrl locationController) ListLocations(c *gin.Context) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Controller").
+		WithField(constants.Method, "ListLocations")
+
+	id := c.Param("id")
+	if id == "" {
+		c.AbortWithStatusJSON(errors.ErrBadRequest.HttpStatusCode, errors.ErrBadRequest)
+		return
+	}
+
+	locations, err := ctrl.svc.ListLocations(c, id)
+	if err != nil {
+		logger.Errorf("ListLocations - %s", err.Error())
+		c.AbortWithStatusJSON(err.HttpStatusCode, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, locations)
+}
+
+// Location godoc
+// @Tags Location
+// @Summary get a company location
+// @Description get a location of a company by its ID
+// @Accept json
+// @Produce  json
+// @Success 200 {object} models.Location
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Param id path string true "company ID"
+// @Param location_id path string true "location ID"
+// @Router /api/v1/company/:id/locations/:location_id [GET]
+func (ctrl locationController) GetLocation(c *gin.Context) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Controller").
+		WithField(constants.Method, "GetLocation")
+
+	id, locationID := c.Param("id"), c.Param("location_id")
+	if id == "" || locationID == "" {
+		c.AbortWithStatusJSON(errors.ErrBadRequest.HttpStatusCode, errors.ErrBadRequest)
+		return
+	}
+
+	location, err := ctrl.svc.GetLocation(c, id, locationID)
+	if err != nil {
+		logger.Errorf("GetLocation - %s", err.Error())
+		c.AbortWithStatusJSON(err.HttpStatusCode, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, location)
+}
+
+// Location godoc
+// @Tags Location
+// @Summary create a company location
+// @Description adds an address to a company, either its headquarters or a branch, with its latitude and longitude in degrees. A company has at most one headquarters
+// @Accept json
+// @Produce  json
+// @Success 201 {object} models.Location
+// @Header 201 {string} Location "URL of the created location"
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 409 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Param id path string true "company ID"
+// @Param CreateLocation body models.Location true "request body, the company is taken from the path"
+// @param authorization header string true "string" default(authorization)
+// @Param Idempotency-Key header string false "replays the stored response when a request is retried with the same key"
+// @Router /api/v1/company/:id/locations [POST]
+func (ctrl locationController) CreateLocation(c *gin.Context) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Controller").
+		WithField(constants.Method, "CreateLocation")
+
+	id := c.Param("id")
+	if id == "" {
+		c.AbortWithStatusJSON(errors.ErrBadRequest.HttpStatusCode, errors.ErrBadRequest)
+		return
+	}
+
+	locationReq := models.Location{}
+	if err := c.ShouldBindJSON(&locationReq); err != nil {
+		c.AbortWithStatusJSON(errors.ErrBadRequest.HttpStatusCode, errors.ErrBadRequest)
+		return
+	}
+	locationReq.CompanyID = id
+
+	location, err := ctrl.svc.CreateLocation(c, locationReq)
+	if err != nil {
+		logger.Errorf("CreateLocation - %s", err.Error())
+		c.AbortWithStatusJSON(err.HttpStatusCode, err)
+		return
+	}
+
+	c.Header("Location", "/api/v1/company/"+id+"/locations/"+location.ID)
+	c.JSON(http.StatusCreated, location)
+}
+
+// Location godoc
+// @Tags Location
+// @Summary replace a company location
+// @Description replaces every field of a location of a company with the request body
+// @Accept json
+// @Produce  json
+// @Success 200 {object} models.Location
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 409 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Param id path string true "company ID"
+// @Param location_id path string true "location ID"
+// @Param ReplaceLocation body models.Location true "request body, the ids are taken from the path"
+// @param authorization header string true "string" default(authorization)
+// @Param Idempotency-Key header string false "replays the stored response when a request is retried with the same key"
+// @Router /api/v1/company/:id/locations/:location_id [PUT]
+func (ctrl locationController) ReplaceLocation(c *gin.Context) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Controller").
+		WithField(constants.Method, "ReplaceLocation")
+
+	id, locationID := c.Param("id"), c.Param("location_id")
+	if id == "" || locationID == "" {
+		c.AbortWithStatusJSON(errors.ErrBadRequest.HttpStatusCode, errors.ErrBadRequest)
+		return
+	}
+
+	locationReq := models.Location{}
+	if err := c.ShouldBindJSON(&locationReq); err != nil {
+		c.AbortWithStatusJSON(errors.ErrBadRequest.HttpStatusCode, errors.ErrBadRequest)
+		return
+	}
+	locationReq.ID, locationReq.CompanyID = locationID, id
+
+	location, err := ctrl.svc.ReplaceLocation(c, locationReq)
+	if err != nil {
+		logger.Errorf("ReplaceLocation - %s", err.Error())
+		c.AbortWithStatusJSON(err.HttpStatusCode, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, location)
+}
+
+// Location godoc
+// @Tags Location
+// @Summary delete a company location
+// @Description removes an address of a company
+// @Accept json
+// @Produce  json
+// @Success 200 {string} successfully deleted location
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Param id path string true "company ID"
+// @Param location_id path string true "location ID"
+// @param authorization header string true "string" default(authorization)
+// @Param Idempotency-Key header string false "replays the stored response when a request is retried with the same key"
+// @Router /api/v1/company/:id/locations/:location_id [DELETE]
+func (ctrl locationController) DeleteLocation(c *gin.Context) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Controller").
+		WithField(constants.Method, "DeleteLocation")
+
+	id, locationID := c.Param("id"), c.Param("location_id")
+	if id == "" || locationID == "" {
+		c.AbortWithStatusJSON(errors.ErrBadRequest.HttpStatusCode, errors.ErrBadRequest)
+		return
+	}
+
+	if err := ctrl.svc.DeleteLocation(c, id, locationID); err != nil {
+		logger.Errorf("DeleteLocation - %s", err.Error())
+		c.AbortWithStatusJSON(err.HttpStatusCode, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, fmt.Sprintf("successfully deleted location with id: %s", locationID))
+}
+
+// Location godoc
+// @Tags Location
+// @Summary nearby companies
+// @Description lists the companies with a location within radius kilometers of a point, nearest first, each with its nearest location and the great-circle distance to it
+// @Accept json
+// @Produce  json
+// @Success 200 {array} models.NearbyCompany
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Param lat query number true "latitude of the point in degrees"
+// @Param lng query number true "longitude of the point in degrees"
+// @Param radius query number false "search radius in kilometers" default(10)
+// @Param limit query int false "page size" default(20)
+// @Param offset query int false "number of companies to skip" default(0)
+// @Router /api/v1/company/near [GET]
+func (ctrl locationController) ListNearbyCompanies(c *gin.Context) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Controller").
+		WithField(constants.Method, "ListNearbyCompanies")
+
+	query, queryErr := parseNearbyQuery(c)
+	if queryErr != nil {
+		logger.Errorf("ListNearbyCompanies - %s", queryErr.Error())
+		c.AbortWithStatusJSON(errors.ErrInvalidQueryParams.HttpStatusCode, errors.ErrInvalidQueryParams)
+		return
+	}
+
+	companies, err := ctrl.svc.ListNearbyCompanies(c, query)
+	if err != nil {
+		logger.Errorf("ListNearbyCompanies - %s", err.Error())
+		c.AbortWithStatusJSON(err.HttpStatusCode, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, companies)
+}
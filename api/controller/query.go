@@ -0,0 +1,255 @@
+package controller
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kumareswaramoorthi/companies/api/constants"
+	"github.com/kumareswaramoorthi/companies/api/models"
+	"github.com/kumareswaramoorthi/companies/api/utils"
+)
+
+// parseCompanyFilter reads the company filter query parameters.
+func parseCompanyFilter(c *gin.Context) (models.CompanyFilter, error) {
+	var filter models.CompanyFilter
+
+	for _, value := range c.QueryArray("type") {
+		for _, companyType := range strings.Split(value, ",") {
+			if companyType = strings.TrimSpace(companyType); companyType != "" {
+				filter.Types = append(filter.Types, companyType)
+			}
+		}
+	}
+
+	if value, ok := c.GetQuery("registered"); ok {
+		registered, err := strconv.ParseBool(value)
+		if err != nil {
+			return filter, fmt.Errorf("invalid registered value [%s]", value)
+		}
+		filter.Registered = &registered
+	}
+
+	var err error
+	if filter.MinAmountOfEmployees, err = intQuery(c, "min_amount_of_employees"); err != nil {
+		return filter, err
+	}
+	if filter.MaxAmountOfEmployees, err = intQuery(c, "max_amount_of_employees"); err != nil {
+		return filter, err
+	}
+	if filter.MinCreatedAt, err = timeQuery(c, "min_created_at"); err != nil {
+		return filter, err
+	}
+	if filter.MaxCreatedAt, err = timeQuery(c, "max_created_at"); err != nil {
+		return filter, err
+	}
+	if filter.MinUpdatedAt, err = timeQuery(c, "min_updated_at"); err != nil {
+		return filter, err
+	}
+	if filter.MaxUpdatedAt, err = timeQuery(c, "max_updated_at"); err != nil {
+		return filter, err
+	}
+
+	return filter, nil
+}
+
+// parseListQuery reads the filter, sort, limit and cursor query parameters of a list request.
+func parseListQuery(c *gin.Context) (models.CompanyListQuery, error) {
+	filter, err := parseCompanyFilter(c)
+	if err != nil {
+		return models.CompanyListQuery{}, err
+	}
+	query := models.CompanyListQuery{Filter: filter}
+
+	if query.SortBy, query.SortDesc, err = parseSort(c); err != nil {
+		return query, err
+	}
+
+	if query.Limit, err = parseLimit(c, constants.DefaultPageSize, constants.MaxPageSize); err != nil {
+		return query, err
+	}
+
+	if query.AsOf, err = parseAsOf(c); err != nil {
+		return query, err
+	}
+
+	if encoded := c.Query("cursor"); encoded != "" {
+		cursor, err := utils.DecodeCursor(encoded)
+		if err != nil {
+			return query, fmt.Errorf("invalid cursor")
+		}
+		sort := query.SortBy
+		if query.SortDesc {
+			sort = "-" + sort
+		}
+		if cursor.Sort != sort {
+			return query, fmt.Errorf("cursor was issued for sort [%s]", cursor.Sort)
+		}
+		query.After = &cursor
+	}
+
+	return query, nil
+}
+
+// parseAsOf reads the RFC 3339 point in time a get or list request is answered for.
+func parseAsOf(c *gin.Context) (*time.Time, error) {
+	return timeQuery(c, "as_of")
+}
+
+// parseExportQuery reads the filter and sort query parameters of an export request.
+func parseExportQuery(c *gin.Context) (models.CompanyListQuery, error) {
+	filter, err := parseCompanyFilter(c)
+	if err != nil {
+		return models.CompanyListQuery{}, err
+	}
+	query := models.CompanyListQuery{Filter: filter}
+
+	query.SortBy, query.SortDesc, err = parseSort(c)
+	return query, err
+}
+
+// parseStatsQuery reads the filter, group_by and buckets query parameters of a stats request.
+func parseStatsQuery(c *gin.Context) (models.CompanyStatsQuery, error) {
+	filter, err := parseCompanyFilter(c)
+	if err != nil {
+		return models.CompanyStatsQuery{}, err
+	}
+	query := models.CompanyStatsQuery{Filter: filter, GroupBy: c.DefaultQuery("group_by", constants.DefaultStatsGroupBy)}
+
+	if !utils.GetGroupableColumns()[query.GroupBy] {
+		return query, fmt.Errorf("invalid group_by column [%s]", query.GroupBy)
+	}
+
+	for _, value := range strings.Split(c.DefaultQuery("buckets", constants.DefaultEmployeeBuckets), ",") {
+		bound, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return query, fmt.Errorf("invalid buckets value [%s]", value)
+		}
+		if len(query.Buckets) > 0 && bound <= query.Buckets[len(query.Buckets)-1] {
+			return query, fmt.Errorf("buckets must be in ascending order")
+		}
+		query.Buckets = append(query.Buckets, bound)
+	}
+	if len(query.Buckets) > constants.MaxEmployeeBuckets {
+		return query, fmt.Errorf("at most %d buckets are allowed", constants.MaxEmployeeBuckets)
+	}
+
+	return query, nil
+}
+
+// parseNearbyQuery reads the point, the radius in kilometers and the page of a radius search.
+func parseNearbyQuery(c *gin.Context) (models.NearbyQuery, error) {
+	var query models.NearbyQuery
+	latitude, err := floatQuery(c, "lat")
+	if err != nil {
+		return query, err
+	}
+	longitude, err := floatQuery(c, "lng")
+	if err != nil {
+		return query, err
+	}
+	if latitude == nil || longitude == nil {
+		return query, fmt.Errorf("lat and lng are required")
+	}
+	if *latitude < -90 || *latitude > 90 || *longitude < -180 || *longitude > 180 {
+		return query, fmt.Errorf("lat must be between -90 and 90, lng between -180 and 180")
+	}
+	query.Latitude, query.Longitude = *latitude, *longitude
+
+	radius, err := floatQuery(c, "radius")
+	if err != nil {
+		return query, err
+	}
+	query.RadiusKm = constants.DefaultNearbyRadiusKm
+	if radius != nil {
+		query.RadiusKm = *radius
+	}
+	if query.RadiusKm <= 0 || query.RadiusKm > constants.MaxNearbyRadiusKm {
+		return query, fmt.Errorf("radius must be above 0 and at most %g", constants.MaxNearbyRadiusKm)
+	}
+
+	if query.Limit, err = parseLimit(c, constants.DefaultPageSize, constants.MaxPageSize); err != nil {
+		return query, err
+	}
+	query.Offset, err = parseOffset(c)
+	return query, err
+}
+
+// parseSort reads the sort column, prefixed with "-" for descending order. It defaults to id.
+func parseSort(c *gin.Context) (string, bool, error) {
+	sort := c.Query("sort")
+	if sort == "" {
+		return "id", false, nil
+	}
+	column := strings.TrimPrefix(sort, "-")
+	if !utils.GetSortableColumns()[column] {
+		return "", false, fmt.Errorf("invalid sort column [%s]", column)
+	}
+	return column, strings.HasPrefix(sort, "-"), nil
+}
+
+// parseLimit reads the number of results to return, bounded by maxLimit.
+func parseLimit(c *gin.Context, defaultLimit, maxLimit int) (int, error) {
+	limit, err := intQuery(c, "limit")
+	if err != nil {
+		return 0, err
+	}
+	if limit == nil {
+		return defaultLimit, nil
+	}
+	if *limit < 1 || *limit > maxLimit {
+		return 0, fmt.Errorf("limit must be between 1 and %d", maxLimit)
+	}
+	return *limit, nil
+}
+
+// parseOffset reads the number of results to skip.
+func parseOffset(c *gin.Context) (int, error) {
+	offset, err := intQuery(c, "offset")
+	if err != nil || offset == nil {
+		return 0, err
+	}
+	if *offset < 0 {
+		return 0, fmt.Errorf("offset must not be negative")
+	}
+	return *offset, nil
+}
+
+func timeQuery(c *gin.Context, key string) (*time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s value [%s]", key, value)
+	}
+	return &t, nil
+}
+
+func intQuery(c *gin.Context, key string) (*int, error) {
+	value, ok := c.GetQuery(key)
+	if !ok {
+		return nil, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s value [%s]", key, value)
+	}
+	return &number, nil
+}
+
+func floatQuery(c *gin.Context, key string) (*float64, error) {
+	value, ok := c.GetQuery(key)
+	if !ok {
+		return nil, nil
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+		return nil, fmt.Errorf("invalid %s value [%s]", key, value)
+	}
+	return &number, nil
+}
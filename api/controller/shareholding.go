@@ -0,0 +1,251 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+	"github.com/kumareswaramoorthi/companies/api/constants"
+	"github.com/kumareswaramoorthi/companies/api/errors"
+	"github.com/kumareswaramoorthi/companies/api/logging"
+	"github.com/kumareswaramoorthi/companies/api/models"
+	service "github.com/kumareswaramoorthi/companies/api/service"
+	"github.com/kumareswaramoorthi/companies/api/utils"
+)
+
+type ShareholdingController interface {
+	ListShareholdings(c *gin.Context)
+	CreateShareholding(c *gin.Context)
+	ReplaceShareholding(c *gin.Context)
+	DeleteShareholding(c *gin.Context)
+	GetUltimateOwners(c *gin.Context)
+}
+
+type shareholdingController struct {
+	svc service.Shareholding
+}
+
+func NewShareholdingController(svc service.Shareholding) ShareholdingController {
+	return &shareholdingController{svc: svc}
+}
+
+// Shareholding godoc
+// @Tags Shareholding
+// @Summary company shareholdings
+// @Description lists the stakes held in a company by other companies, only those in effect at as_of when it is given
+// @Accept json
+// @Produce  json
+// @Success 200 {array} models.Shareholding
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Param id path string true "company ID"
+// @Param as_of query string false "RFC 3339 time the stakes are in effect at"
+// @Router /api/v1/company/:id/shareholdings [GET]
+func (ctrl shareholdingController) ListShareholdings(c *gin.Context) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Controller").
+		WithField(constants.Method, "ListShareholdings")
+
+	id := c.Param("id")
+	if id == "" {
+		c.AbortWithStatusJSON(errors.ErrBadRequest.HttpStatusCode, errors.ErrBadRequest)
+		return
+	}
+
+	asOf, queryErr := parseAsOf(c)
+	if queryErr != nil {
+		c.AbortWithStatusJSON(errors.ErrInvalidQueryParams.HttpStatusCode, errors.ErrInvalidQueryParams)
+		return
+	}
+
+	shareholdings, err := ctrl.svc.ListShareholdings(c, id, asOf)
+	if err != nil {
+		logger.Errorf("ListShareholdings - %s", err.Error())
+		c.AbortWithStatusJSON(err.HttpStatusCode, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, shareholdings)
+}
+
+// Shareholding godoc
+// @Tags Shareholding
+// @Summary create a shareholding
+// @Description records the percentage of the company the shareholder company holds from effective_from until effective_to, open ended without it
+// @Accept json
+// @Produce  json
+// @Success 201 {object} models.Shareholding
+// @Header 201 {string} Location "URL of the shareholdings of the company"
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 409 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Param id path string true "company ID"
+// @Param CreateShareholding body models.Shareholding true "request body, the company is taken from the path"
+// @param authorization header string true "string" default(authorization)
+// @Param Idempotency-Key header string false "replays the stored response when a request is retried with the same key"
+// @Router /api/v1/company/:id/shareholdings [POST]
+func (ctrl shareholdingController) CreateShareholding(c *gin.Context) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Controller").
+		WithField(constants.Method, "CreateShareholding")
+
+	id := c.Param("id")
+	if id == "" {
+		c.AbortWithStatusJSON(errors.ErrBadRequest.HttpStatusCode, errors.ErrBadRequest)
+		return
+	}
+
+	shareholdingReq := models.Shareholding{}
+	if err := c.ShouldBindJSON(&shareholdingReq); err != nil {
+		c.AbortWithStatusJSON(errors.ErrBadRequest.HttpStatusCode, errors.ErrBadRequest)
+		return
+	}
+	shareholdingReq.CompanyID = id
+
+	shareholding, err := ctrl.svc.CreateShareholding(c, shareholdingReq)
+	if err != nil {
+		logger.Errorf("CreateShareholding - %s", err.Error())
+		c.AbortWithStatusJSON(err.HttpStatusCode, err)
+		return
+	}
+
+	c.Header("Location", "/api/v1/company/"+id+"/shareholdings")
+	c.JSON(http.StatusCreated, shareholding)
+}
+
+// Shareholding godoc
+// @Tags Shareholding
+// @Summary replace a shareholding
+// @Description replaces the percentage and the period of a stake, its shareholder cannot be changed
+// @Accept json
+// @Produce  json
+// @Success 200 {object} models.Shareholding
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 409 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Param id path string true "company ID"
+// @Param shareholding_id path string true "shareholding ID"
+// @Param ReplaceShareholding body models.Shareholding true "request body, the ids are taken from the path"
+// @param authorization header string true "string" default(authorization)
+// @Param Idempotency-Key header string false "replays the stored response when a request is retried with the same key"
+// @Router /api/v1/company/:id/shareholdings/:shareholding_id [PUT]
+func (ctrl shareholdingController) ReplaceShareholding(c *gin.Context) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Controller").
+		WithField(constants.Method, "ReplaceShareholding")
+
+	id, shareholdingID := c.Param("id"), c.Param("shareholding_id")
+	if id == "" || shareholdingID == "" {
+		c.AbortWithStatusJSON(errors.ErrBadRequest.HttpStatusCode, errors.ErrBadRequest)
+		return
+	}
+
+	shareholdingReq := models.Shareholding{}
+	if err := c.ShouldBindJSON(&shareholdingReq); err != nil {
+		c.AbortWithStatusJSON(errors.ErrBadRequest.HttpStatusCode, errors.ErrBadRequest)
+		return
+	}
+	shareholdingReq.ID, shareholdingReq.CompanyID = shareholdingID, id
+
+	shareholding, err := ctrl.svc.ReplaceShareholding(c, shareholdingReq)
+	if err != nil {
+		logger.Errorf("ReplaceShareholding - %s", err.Error())
+		c.AbortWithStatusJSON(err.HttpStatusCode, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, shareholding)
+}
+
+// Shareholding godoc
+// @Tags Shareholding
+// @Summary delete a shareholding
+// @Description removes a stake held in the company
+// @Accept json
+// @Produce  json
+// @Success 200 {string} successfully deleted shareholding
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 403 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Param id path string true "company ID"
+// @Param shareholding_id path string true "shareholding ID"
+// @param authorization header string true "string" default(authorization)
+// @Param Idempotency-Key header string false "replays the stored response when a request is retried with the same key"
+// @Router /api/v1/company/:id/shareholdings/:shareholding_id [DELETE]
+func (ctrl shareholdingController) DeleteShareholding(c *gin.Context) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Controller").
+		WithField(constants.Method, "DeleteShareholding")
+
+	id, shareholdingID := c.Param("id"), c.Param("shareholding_id")
+	if id == "" || shareholdingID == "" {
+		c.AbortWithStatusJSON(errors.ErrBadRequest.HttpStatusCode, errors.ErrBadRequest)
+		return
+	}
+
+	if err := ctrl.svc.DeleteShareholding(c, id, shareholdingID); err != nil {
+		logger.Errorf("DeleteShareholding - %s", err.Error())
+		c.AbortWithStatusJSON(err.HttpStatusCode, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, fmt.Sprintf("successfully deleted shareholding with id: %s", shareholdingID))
+}
+
+// Shareholding godoc
+// @Tags Shareholding
+// @Summary company ultimate owners
+// @Description follows the stakes in effect at as_of up from the company to the companies without shareholders, multiplying the percentages along every chain and adding up the chains ending at the same owner. Circular holdings are followed until what they pass on is negligible, the part of the company not traced to an owner is reported as unattributed
+// @Accept json
+// @Produce  json
+// @Success 200 {object} models.OwnershipReport
+// @Failure 400 {object} errors.ErrorResponse
+// @Failure 500 {object} errors.ErrorResponse
+// @Param id path string true "company ID"
+// @Param as_of query string false "RFC 3339 time the stakes are in effect at, now by default"
+// @Param min_percentage query number false "leaves out the owners with a smaller effective percentage" default(0)
+// @Router /api/v1/company/:id/owners [GET]
+func (ctrl shareholdingController) GetUltimateOwners(c *gin.Context) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Controller").
+		WithField(constants.Method, "GetUltimateOwners")
+
+	id := c.Param("id")
+	if id == "" {
+		c.AbortWithStatusJSON(errors.ErrBadRequest.HttpStatusCode, errors.ErrBadRequest)
+		return
+	}
+
+	asOf, queryErr := parseAsOf(c)
+	if queryErr != nil {
+		c.AbortWithStatusJSON(errors.ErrInvalidQueryParams.HttpStatusCode, errors.ErrInvalidQueryParams)
+		return
+	}
+	if asOf == nil {
+		now := utils.Now()
+		asOf = &now
+	}
+	minPercentage, queryErr := strconv.ParseFloat(c.DefaultQuery("min_percentage", "0"), 64)
+	if queryErr != nil || minPercentage < 0 || minPercentage > 100 {
+		c.AbortWithStatusJSON(errors.ErrInvalidQueryParams.HttpStatusCode, errors.ErrInvalidQueryParams)
+		return
+	}
+
+	report, err := ctrl.svc.GetUltimateOwners(c, id, *asOf, minPercentage)
+	if err != nil {
+		logger.Errorf("GetUltimateOwners - %s", err.Error())
+		c.AbortWithStatusJSON(err.HttpStatusCode, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -1,5 +1,7 @@
 package dto
 
+import "github.com/kumareswaramoorthi/companies/api/models"
+
 type CompanyPatchReq struct {
 	ID                string `json:"id,omitempty"`
 	Name              string `json:"name,omitempty"`
@@ -13,3 +15,16 @@ type LoginCredentials struct {
 	Email    string `form:"email"`
 	Password string `form:"password"`
 }
+
+type BulkUpdateReq struct {
+	models.CompanySelector
+	Changes map[string]interface{} `json:"changes"`
+}
+
+type BulkDeleteReq struct {
+	models.CompanySelector
+}
+
+type RevertReq struct {
+	Revision int `json:"revision"`
+}
@@ -19,6 +19,52 @@ const (
 	UnableToFetchCompany            = "ERR_API_UNABLE_TO_FETCH_COMPANY"
 	UnableToDeleteCompany           = "ERR_API_UNABLE_TO_DELETE_COMPANY"
 	UnableToUpdateCompany           = "ERR_API_UNABLE_TO_UPDATE_COMPANY"
+	InvalidQueryParams              = "ERR_API_INVALID_QUERY_PARAMS"
+	UnableToListCompanies           = "ERR_API_UNABLE_TO_LIST_COMPANIES"
+	UnableToSearchCompanies         = "ERR_API_UNABLE_TO_SEARCH_COMPANIES"
+	UnableToSuggestCompanies        = "ERR_API_UNABLE_TO_SUGGEST_COMPANIES"
+	RecordAlreadyExistsForGivenID   = "ERR_API_RECORD_ALREADY_EXISTS_FOR_GIVEN_ID"
+	ValidationFailed                = "ERR_API_VALIDATION_FAILED"
+	InvalidBatchSize                = "ERR_API_INVALID_BATCH_SIZE"
+	InvalidSelector                 = "ERR_API_INVALID_SELECTOR"
+	InvalidImportFile               = "ERR_API_INVALID_IMPORT_FILE"
+	UnableToImportCompanies         = "ERR_API_UNABLE_TO_IMPORT_COMPANIES"
+	UnableToExportCompanies         = "ERR_API_UNABLE_TO_EXPORT_COMPANIES"
+	NotAcceptable                   = "ERR_API_NOT_ACCEPTABLE"
+	InvalidIdempotencyKey           = "ERR_API_INVALID_IDEMPOTENCY_KEY"
+	IdempotencyKeyReused            = "ERR_API_IDEMPOTENCY_KEY_REUSED"
+	IdempotentRequestInProgress     = "ERR_API_IDEMPOTENT_REQUEST_IN_PROGRESS"
+	NoDeletedCompanyFoundByID       = "ERR_API_NO_DELETED_COMPANY_FOUND_FOR_GIVEN_ID"
+	UnableToRestoreCompany          = "ERR_API_UNABLE_TO_RESTORE_COMPANY"
+	UnableToFetchCompanyHistory     = "ERR_API_UNABLE_TO_FETCH_COMPANY_HISTORY"
+	NoCompanyRevisionFound          = "ERR_API_NO_COMPANY_REVISION_FOUND"
+	UnableToRevertCompany           = "ERR_API_UNABLE_TO_REVERT_COMPANY"
+	PreconditionFailed              = "ERR_API_PRECONDITION_FAILED"
+	UnprocessablePatch              = "ERR_API_UNPROCESSABLE_PATCH"
+	PatchTestFailed                 = "ERR_API_PATCH_TEST_FAILED"
+	UnableToComputeCompanyStats     = "ERR_API_UNABLE_TO_COMPUTE_COMPANY_STATS"
+	ParentCompanyNotFound           = "ERR_API_PARENT_COMPANY_NOT_FOUND"
+	CompanyHierarchyCycle           = "ERR_API_COMPANY_HIERARCHY_CYCLE"
+	CompanyHasSubsidiaries          = "ERR_API_COMPANY_HAS_SUBSIDIARIES"
+	UnableToFetchCompanyHierarchy   = "ERR_API_UNABLE_TO_FETCH_COMPANY_HIERARCHY"
+	ShareholderNotFound             = "ERR_API_SHAREHOLDER_NOT_FOUND"
+	NoShareholdingFound             = "ERR_API_NO_SHAREHOLDING_FOUND"
+	ShareholdingOverlaps            = "ERR_API_SHAREHOLDING_OVERLAPS"
+	ShareholdingsExceedTotal        = "ERR_API_SHAREHOLDINGS_EXCEED_TOTAL"
+	UnableToSaveShareholding        = "ERR_API_UNABLE_TO_SAVE_SHAREHOLDING"
+	UnableToFetchShareholdings      = "ERR_API_UNABLE_TO_FETCH_SHAREHOLDINGS"
+	UnableToComputeOwners           = "ERR_API_UNABLE_TO_COMPUTE_OWNERS"
+	NoLocationFound                 = "ERR_API_NO_LOCATION_FOUND"
+	HeadquartersExists              = "ERR_API_HEADQUARTERS_EXISTS"
+	UnableToSaveLocation            = "ERR_API_UNABLE_TO_SAVE_LOCATION"
+	UnableToFetchLocations          = "ERR_API_UNABLE_TO_FETCH_LOCATIONS"
+	UnableToSearchNearbyCompanies   = "ERR_API_UNABLE_TO_SEARCH_NEARBY_COMPANIES"
+	NoContactFound                  = "ERR_API_NO_CONTACT_FOUND"
+	ContactEmailExists              = "ERR_API_CONTACT_EMAIL_EXISTS"
+	UnableToSaveContact             = "ERR_API_UNABLE_TO_SAVE_CONTACT"
+	UnableToFetchContacts           = "ERR_API_UNABLE_TO_FETCH_CONTACTS"
+	UnableToSearchContacts          = "ERR_API_UNABLE_TO_SEARCH_CONTACTS"
+	CompanyInTrash                  = "ERR_API_COMPANY_IN_TRASH"
 )
 
 var ApiErrors = map[ErrorCode]string{
@@ -32,6 +78,52 @@ var ApiErrors = map[ErrorCode]string{
 	UnableToFetchCompany:            "Unable to fetch company",
 	UnableToDeleteCompany:           "Unable to delete company",
 	UnableToUpdateCompany:           "Unable to update company",
+	InvalidQueryParams:              "Invalid query parameters",
+	UnableToListCompanies:           "Unable to list companies",
+	UnableToSearchCompanies:         "Unable to search companies",
+	UnableToSuggestCompanies:        "Unable to suggest companies",
+	RecordAlreadyExistsForGivenID:   "Record already exist for given ID",
+	ValidationFailed:                "Validation failed",
+	InvalidBatchSize:                "Batch must contain between 1 and 1000 items",
+	InvalidSelector:                 "Valid ids or a filter must be given",
+	InvalidImportFile:               "Import file could not be read",
+	UnableToImportCompanies:         "Unable to import companies",
+	UnableToExportCompanies:         "Unable to export companies",
+	NotAcceptable:                   "Requested format is not supported, use csv, ndjson or json",
+	InvalidIdempotencyKey:           "Idempotency-Key must be at most 255 characters",
+	IdempotencyKeyReused:            "Idempotency-Key was already used for a different request",
+	IdempotentRequestInProgress:     "A request with the same Idempotency-Key is still in progress",
+	NoDeletedCompanyFoundByID:       "No deleted company found for given ID",
+	UnableToRestoreCompany:          "Unable to restore company",
+	UnableToFetchCompanyHistory:     "Unable to fetch company history",
+	NoCompanyRevisionFound:          "No revision found for given company ID and number",
+	UnableToRevertCompany:           "Unable to revert company",
+	PreconditionFailed:              "Company was changed since it was read, fetch it again for the current ETag",
+	UnprocessablePatch:              "Patch cannot be applied to the company",
+	PatchTestFailed:                 "A test operation of the patch does not match the company",
+	UnableToComputeCompanyStats:     "Unable to compute company stats",
+	ParentCompanyNotFound:           "Parent company does not exist or is in the trash",
+	CompanyHierarchyCycle:           "A company cannot be a subsidiary of itself or of one of its subsidiaries",
+	CompanyHasSubsidiaries:          "Company has subsidiaries, delete them first or delete with subsidiaries=cascade",
+	UnableToFetchCompanyHierarchy:   "Unable to fetch company hierarchy",
+	ShareholderNotFound:             "Shareholder company does not exist or is in the trash",
+	NoShareholdingFound:             "No shareholding found for given company ID and shareholding ID",
+	ShareholdingOverlaps:            "Shareholder already holds a stake in the company during the given period",
+	ShareholdingsExceedTotal:        "Shareholdings in the company would add up to more than 100 percent",
+	UnableToSaveShareholding:        "Unable to save shareholding",
+	UnableToFetchShareholdings:      "Unable to fetch shareholdings",
+	UnableToComputeOwners:           "Unable to compute ultimate owners",
+	NoLocationFound:                 "No location found for given company ID and location ID",
+	HeadquartersExists:              "Company already has a headquarters, make it a branch first",
+	UnableToSaveLocation:            "Unable to save location",
+	UnableToFetchLocations:          "Unable to fetch locations",
+	UnableToSearchNearbyCompanies:   "Unable to search nearby companies",
+	NoContactFound:                  "No contact found for given company ID and contact ID",
+	ContactEmailExists:              "Company already has a contact with the given email",
+	UnableToSaveContact:             "Unable to save contact",
+	UnableToFetchContacts:           "Unable to fetch contacts",
+	UnableToSearchContacts:          "Unable to search contacts",
+	CompanyInTrash:                  "Company with given ID is in the trash, restore it first",
 }
 
 type ErrorResponse struct {
@@ -63,3 +155,49 @@ var ErrInternalServerError = NewErrorResponse(http.StatusInternalServerError, In
 var ErrUnableToFetchCompany = NewErrorResponse(http.StatusInternalServerError, UnableToFetchCompany, ApiErrors[UnableToFetchCompany])
 var ErrUnableToDeleteCompany = NewErrorResponse(http.StatusInternalServerError, UnableToDeleteCompany, ApiErrors[UnableToDeleteCompany])
 var ErrUnableToUpdateCompany = NewErrorResponse(http.StatusInternalServerError, UnableToUpdateCompany, ApiErrors[UnableToUpdateCompany])
+var ErrInvalidQueryParams = NewErrorResponse(http.StatusBadRequest, InvalidQueryParams, ApiErrors[InvalidQueryParams])
+var ErrUnableToListCompanies = NewErrorResponse(http.StatusInternalServerError, UnableToListCompanies, ApiErrors[UnableToListCompanies])
+var ErrUnableToSearchCompanies = NewErrorResponse(http.StatusInternalServerError, UnableToSearchCompanies, ApiErrors[UnableToSearchCompanies])
+var ErrUnableToSuggestCompanies = NewErrorResponse(http.StatusInternalServerError, UnableToSuggestCompanies, ApiErrors[UnableToSuggestCompanies])
+var ErrRecordAlreadyExistsForGivenID = NewErrorResponse(http.StatusBadRequest, RecordAlreadyExistsForGivenID, ApiErrors[RecordAlreadyExistsForGivenID])
+var ErrValidationFailed = NewErrorResponse(http.StatusBadRequest, ValidationFailed, ApiErrors[ValidationFailed])
+var ErrInvalidBatchSize = NewErrorResponse(http.StatusBadRequest, InvalidBatchSize, ApiErrors[InvalidBatchSize])
+var ErrInvalidSelector = NewErrorResponse(http.StatusBadRequest, InvalidSelector, ApiErrors[InvalidSelector])
+var ErrInvalidImportFile = NewErrorResponse(http.StatusBadRequest, InvalidImportFile, ApiErrors[InvalidImportFile])
+var ErrUnableToImportCompanies = NewErrorResponse(http.StatusInternalServerError, UnableToImportCompanies, ApiErrors[UnableToImportCompanies])
+var ErrUnableToExportCompanies = NewErrorResponse(http.StatusInternalServerError, UnableToExportCompanies, ApiErrors[UnableToExportCompanies])
+var ErrNotAcceptable = NewErrorResponse(http.StatusNotAcceptable, NotAcceptable, ApiErrors[NotAcceptable])
+var ErrInvalidIdempotencyKey = NewErrorResponse(http.StatusBadRequest, InvalidIdempotencyKey, ApiErrors[InvalidIdempotencyKey])
+var ErrIdempotencyKeyReused = NewErrorResponse(http.StatusUnprocessableEntity, IdempotencyKeyReused, ApiErrors[IdempotencyKeyReused])
+var ErrIdempotentRequestInProgress = NewErrorResponse(http.StatusConflict, IdempotentRequestInProgress, ApiErrors[IdempotentRequestInProgress])
+var ErrNoDeletedCompanyFoundByID = NewErrorResponse(http.StatusBadRequest, NoDeletedCompanyFoundByID, ApiErrors[NoDeletedCompanyFoundByID])
+var ErrUnableToRestoreCompany = NewErrorResponse(http.StatusInternalServerError, UnableToRestoreCompany, ApiErrors[UnableToRestoreCompany])
+var ErrUnableToFetchCompanyHistory = NewErrorResponse(http.StatusInternalServerError, UnableToFetchCompanyHistory, ApiErrors[UnableToFetchCompanyHistory])
+var ErrNoCompanyRevisionFound = NewErrorResponse(http.StatusBadRequest, NoCompanyRevisionFound, ApiErrors[NoCompanyRevisionFound])
+var ErrUnableToRevertCompany = NewErrorResponse(http.StatusInternalServerError, UnableToRevertCompany, ApiErrors[UnableToRevertCompany])
+var ErrPreconditionFailed = NewErrorResponse(http.StatusPreconditionFailed, PreconditionFailed, ApiErrors[PreconditionFailed])
+var ErrUnprocessablePatch = NewErrorResponse(http.StatusUnprocessableEntity, UnprocessablePatch, ApiErrors[UnprocessablePatch])
+var ErrPatchTestFailed = NewErrorResponse(http.StatusConflict, PatchTestFailed, ApiErrors[PatchTestFailed])
+var ErrUnableToComputeCompanyStats = NewErrorResponse(http.StatusInternalServerError, UnableToComputeCompanyStats, ApiErrors[UnableToComputeCompanyStats])
+var ErrParentCompanyNotFound = NewErrorResponse(http.StatusBadRequest, ParentCompanyNotFound, ApiErrors[ParentCompanyNotFound])
+var ErrCompanyHierarchyCycle = NewErrorResponse(http.StatusConflict, CompanyHierarchyCycle, ApiErrors[CompanyHierarchyCycle])
+var ErrCompanyHasSubsidiaries = NewErrorResponse(http.StatusConflict, CompanyHasSubsidiaries, ApiErrors[CompanyHasSubsidiaries])
+var ErrUnableToFetchCompanyHierarchy = NewErrorResponse(http.StatusInternalServerError, UnableToFetchCompanyHierarchy, ApiErrors[UnableToFetchCompanyHierarchy])
+var ErrShareholderNotFound = NewErrorResponse(http.StatusBadRequest, ShareholderNotFound, ApiErrors[ShareholderNotFound])
+var ErrNoShareholdingFound = NewErrorResponse(http.StatusBadRequest, NoShareholdingFound, ApiErrors[NoShareholdingFound])
+var ErrShareholdingOverlaps = NewErrorResponse(http.StatusConflict, ShareholdingOverlaps, ApiErrors[ShareholdingOverlaps])
+var ErrShareholdingsExceedTotal = NewErrorResponse(http.StatusConflict, ShareholdingsExceedTotal, ApiErrors[ShareholdingsExceedTotal])
+var ErrUnableToSaveShareholding = NewErrorResponse(http.StatusInternalServerError, UnableToSaveShareholding, ApiErrors[UnableToSaveShareholding])
+var ErrUnableToFetchShareholdings = NewErrorResponse(http.StatusInternalServerError, UnableToFetchShareholdings, ApiErrors[UnableToFetchShareholdings])
+var ErrUnableToComputeOwners = NewErrorResponse(http.StatusInternalServerError, UnableToComputeOwners, ApiErrors[UnableToComputeOwners])
+var ErrNoLocationFound = NewErrorResponse(http.StatusBadRequest, NoLocationFound, ApiErrors[NoLocationFound])
+var ErrHeadquartersExists = NewErrorResponse(http.StatusConflict, HeadquartersExists, ApiErrors[HeadquartersExists])
+var ErrUnableToSaveLocation = NewErrorResponse(http.StatusInternalServerError, UnableToSaveLocation, ApiErrors[UnableToSaveLocation])
+var ErrUnableToFetchLocations = NewErrorResponse(http.StatusInternalServerError, UnableToFetchLocations, ApiErrors[UnableToFetchLocations])
+var ErrUnableToSearchNearbyCompanies = NewErrorResponse(http.StatusInternalServerError, UnableToSearchNearbyCompanies, ApiErrors[UnableToSearchNearbyCompanies])
+var ErrNoContactFound = NewErrorResponse(http.StatusBadRequest, NoContactFound, ApiErrors[NoContactFound])
+var ErrContactEmailExists = NewErrorResponse(http.StatusConflict, ContactEmailExists, ApiErrors[ContactEmailExists])
+var ErrUnableToSaveContact = NewErrorResponse(http.StatusInternalServerError, UnableToSaveContact, ApiErrors[UnableToSaveContact])
+var ErrUnableToFetchContacts = NewErrorResponse(http.StatusInternalServerError, UnableToFetchContacts, ApiErrors[UnableToFetchContacts])
+var ErrUnableToSearchContacts = NewErrorResponse(http.StatusInternalServerError, UnableToSearchContacts, ApiErrors[UnableToSearchContacts])
+var ErrCompanyInTrash = NewErrorResponse(http.StatusConflict, CompanyInTrash, ApiErrors[CompanyInTrash])
@@ -0,0 +1,38 @@
+// Package jobs runs the maintenance tasks of the server in the background.
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/kumareswaramoorthi/companies/api/constants"
+	"github.com/kumareswaramoorthi/companies/api/logging"
+)
+
+// Job is a maintenance task returning the number of rows it removed.
+type Job func(ctx context.Context) (int64, error)
+
+// Schedule runs the job every interval until ctx is done.
+func Schedule(ctx context.Context, name string, interval time.Duration, job Job) {
+	logger := logging.GetLogger(ctx).
+		WithField(constants.Interface, "Job").
+		WithField(constants.Method, name)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				rows, err := job(ctx)
+				if err != nil {
+					logger.Errorf("job: %s error: %s", name, err.Error())
+					continue
+				}
+				logger.Debugf("job: %s removed %d rows", name, rows)
+			}
+		}
+	}()
+}
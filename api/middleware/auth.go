@@ -6,7 +6,9 @@ import (
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
+	"github.com/kumareswaramoorthi/companies/api/constants"
 	service "github.com/kumareswaramoorthi/companies/api/service"
+	"github.com/kumareswaramoorthi/companies/api/utils"
 )
 
 func AuthorizeJWT() gin.HandlerFunc {
@@ -36,10 +38,48 @@ func AuthorizeJWT() gin.HandlerFunc {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 			return
 		}
+		if claims, ok := token.Claims.(jwt.MapClaims); ok {
+			if email, ok := claims["name"].(string); ok {
+				c.Set(constants.UserEmail, email)
+			}
+		}
+		c.Next()
+	}
+}
+
+// AuthorizeIncludes runs AuthorizeJWT on the public reads that embed one of the given
+// resources with the include query parameter, so they are not served without a token
+// where their own endpoint requires one.
+func AuthorizeIncludes(resources ...string) gin.HandlerFunc {
+	authorize := AuthorizeJWT()
+	return func(c *gin.Context) {
+		for _, included := range strings.Split(c.Query("include"), ",") {
+			for _, resource := range resources {
+				if strings.TrimSpace(included) == resource {
+					authorize(c)
+					return
+				}
+			}
+		}
 		c.Next()
 	}
 }
 
+// AuthorizeAdmin lets through the users listed in the comma separated ADMIN_EMAILS
+// environment variable. It must run after AuthorizeJWT.
+func AuthorizeAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		email := c.GetString(constants.UserEmail)
+		for _, admin := range strings.Split(utils.GetEnvVars("ADMIN_EMAILS", constants.DefaultAdminEmails), ",") {
+			if email != "" && strings.TrimSpace(admin) == email {
+				c.Next()
+				return
+			}
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+	}
+}
+
 func ExtractToken(c *gin.Context) string {
 	token := c.Query("token")
 	if token != "" {
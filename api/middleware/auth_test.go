@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kumareswaramoorthi/companies/api/constants"
+	service "github.com/kumareswaramoorthi/companies/api/service"
+	"github.com/stretchr/testify/suite"
+)
+
+type AuthTestSuite struct {
+	suite.Suite
+	router *gin.Engine
+}
+
+func TestAuth(t *testing.T) {
+	suite.Run(t, new(AuthTestSuite))
+}
+
+func (suite *AuthTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	suite.router = gin.New()
+	suite.router.GET("/company", AuthorizeIncludes(constants.IncludeHistory), func(c *gin.Context) {
+		c.JSON(http.StatusOK, c.GetString(constants.UserEmail))
+	})
+}
+
+func (suite *AuthTestSuite) get(url, token string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", url, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	recorder := httptest.NewRecorder()
+	suite.router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func (suite *AuthTestSuite) TestAuthorizeIncludesLetsPublicReadsThrough() {
+	suite.Equal(http.StatusOK, suite.get("/company", "").Code)
+	suite.Equal(http.StatusOK, suite.get("/company?fields=name", "").Code)
+}
+
+func (suite *AuthTestSuite) TestAuthorizeIncludesRequiresTokenForProtectedResource() {
+	suite.Equal(http.StatusUnauthorized, suite.get("/company?include=history", "").Code)
+	suite.Equal(http.StatusUnauthorized, suite.get("/company?fields=name&include=%20history", "").Code)
+}
+
+func (suite *AuthTestSuite) TestAuthorizeIncludesAcceptsValidToken() {
+	token := service.JWTAuthService().GenerateToken("admin@company.com", true)
+
+	res := suite.get("/company?include=history", token)
+	suite.Equal(http.StatusOK, res.Code)
+	suite.Equal(`"admin@company.com"`, res.Body.String())
+}
@@ -0,0 +1,161 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+	"github.com/kumareswaramoorthi/companies/api/constants"
+	"github.com/kumareswaramoorthi/companies/api/errors"
+	"github.com/kumareswaramoorthi/companies/api/logging"
+	"github.com/kumareswaramoorthi/companies/api/repository"
+)
+
+// replayedHeaders are the response headers stored with an idempotent response.
+var replayedHeaders = []string{"Content-Type", "Location", "ETag"}
+
+// Idempotency stores the response of a request sent with an Idempotency-Key header
+// for ttl and replays it when the request is retried with the same key. Reusing a key
+// for a different method, URL or body is rejected, as is a retry while the first
+// request is still running. Server errors are not stored, so they can be retried.
+// Keys are scoped to the authenticated user, another user sending the same key gets
+// a key of their own.
+func Idempotency(repo repository.IdempotencyRepository, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(constants.IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > constants.MaxIdempotencyKeyLength {
+			c.AbortWithStatusJSON(errors.ErrInvalidIdempotencyKey.HttpStatusCode, errors.ErrInvalidIdempotencyKey)
+			return
+		}
+		key = scopedKey(c.GetString(constants.UserEmail), key)
+
+		logger := logging.GetLogger(c).
+			WithField(constants.ReqID, requestid.Get(c)).
+			WithField(constants.Interface, "Middleware").
+			WithField(constants.Method, "Idempotency")
+
+		body, err := c.GetRawData()
+		if err != nil {
+			c.AbortWithStatusJSON(errors.ErrBadRequest.HttpStatusCode, errors.ErrBadRequest)
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		hash := requestHash(c.Request, body)
+
+		reserved, err := repo.ReserveIdempotencyKey(c, key, hash, ttl)
+		if err != nil {
+			logger.Errorf("Idempotency - %s", err.Error())
+			c.AbortWithStatusJSON(errors.ErrInternalServerError.HttpStatusCode, errors.ErrInternalServerError)
+			return
+		}
+		if !reserved {
+			replay(c, repo, key, hash)
+			return
+		}
+
+		// a panicking handler must not hold the key until it expires
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				_ = repo.DeleteIdempotencyKey(c, key)
+				panic(recovered)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError {
+			_ = repo.DeleteIdempotencyKey(c, key)
+			return
+		}
+		headers := map[string]string{}
+		for _, name := range replayedHeaders {
+			if value := recorder.Header().Get(name); value != "" {
+				headers[name] = value
+			}
+		}
+		encodedHeaders, err := json.Marshal(headers)
+		if err != nil {
+			logger.Errorf("Idempotency - %s", err.Error())
+			_ = repo.DeleteIdempotencyKey(c, key)
+			return
+		}
+		_ = repo.SaveIdempotentResponse(c, key, status, encodedHeaders, recorder.body.Bytes())
+	}
+}
+
+// replay answers a retried request with the response stored for its key.
+func replay(c *gin.Context, repo repository.IdempotencyRepository, key, hash string) {
+	record, err := repo.GetIdempotencyKey(c, key)
+	if err == sql.ErrNoRows {
+		// the key expired and was purged since it was reserved, the client can retry
+		c.AbortWithStatusJSON(errors.ErrIdempotentRequestInProgress.HttpStatusCode, errors.ErrIdempotentRequestInProgress)
+		return
+	}
+	if err != nil {
+		c.AbortWithStatusJSON(errors.ErrInternalServerError.HttpStatusCode, errors.ErrInternalServerError)
+		return
+	}
+	if record.RequestHash != hash {
+		c.AbortWithStatusJSON(errors.ErrIdempotencyKeyReused.HttpStatusCode, errors.ErrIdempotencyKeyReused)
+		return
+	}
+	if record.StatusCode == nil {
+		c.AbortWithStatusJSON(errors.ErrIdempotentRequestInProgress.HttpStatusCode, errors.ErrIdempotentRequestInProgress)
+		return
+	}
+
+	headers := map[string]string{}
+	_ = json.Unmarshal(record.ResponseHeaders, &headers)
+	for name, value := range headers {
+		c.Header(name, value)
+	}
+	c.Header(constants.IdempotentReplayedHeader, "true")
+	c.Status(*record.StatusCode)
+	_, _ = c.Writer.Write(record.ResponseBody)
+	c.Abort()
+}
+
+// scopedKey is the key stored for the Idempotency-Key of a user. Neither an email nor
+// a header value holds a newline, so different users never share a stored key.
+func scopedKey(user, key string) string {
+	hash := sha256.Sum256([]byte(user + "\n" + key))
+	return hex.EncodeToString(hash[:])
+}
+
+// requestHash identifies a request by its method, URL and body.
+func requestHash(req *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(req.Method + " " + req.URL.RequestURI() + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder keeps a copy of the response body written to the client.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(p []byte) (int, error) {
+	w.body.Write(p)
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,178 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/kumareswaramoorthi/companies/api/constants"
+	er "github.com/kumareswaramoorthi/companies/api/errors"
+	"github.com/kumareswaramoorthi/companies/api/models"
+	"github.com/kumareswaramoorthi/companies/api/repository/mocks"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	testKey   = "7d1c5e2a-create-acme"
+	testUser  = "admin@company.com"
+	otherUser = "other@company.com"
+	testBody  = `{"name":"acme"}`
+)
+
+type IdempotencyTestSuite struct {
+	suite.Suite
+	mockCtrl        *gomock.Controller
+	mockRepository  *mocks.MockIdempotencyRepository
+	router          *gin.Engine
+	handlerStatus   int
+	handlerRequests int
+}
+
+func TestIdempotency(t *testing.T) {
+	suite.Run(t, new(IdempotencyTestSuite))
+}
+
+func (suite *IdempotencyTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockRepository = mocks.NewMockIdempotencyRepository(suite.mockCtrl)
+	suite.handlerStatus = http.StatusCreated
+	suite.handlerRequests = 0
+
+	suite.router = gin.New()
+	// stands in for AuthorizeJWT, which sets the email of the authenticated user
+	authenticate := func(c *gin.Context) {
+		c.Set(constants.UserEmail, c.GetHeader("X-Test-User"))
+	}
+	suite.router.POST("/company", authenticate, Idempotency(suite.mockRepository, time.Hour), func(c *gin.Context) {
+		suite.handlerRequests++
+		c.Header("Location", "/api/v1/company/041d2027-e6fa-4d6d-836d-eedb235c82bc")
+		c.JSON(suite.handlerStatus, gin.H{"id": "041d2027-e6fa-4d6d-836d-eedb235c82bc"})
+	})
+}
+
+func (suite *IdempotencyTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func (suite *IdempotencyTestSuite) send(user, key, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("POST", "/company", strings.NewReader(body))
+	req.Header.Set("X-Test-User", user)
+	if key != "" {
+		req.Header.Set(constants.IdempotencyKeyHeader, key)
+	}
+	recorder := httptest.NewRecorder()
+	suite.router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func (suite *IdempotencyTestSuite) TestWithoutKey() {
+	res := suite.send(testUser, "", testBody)
+	suite.Equal(http.StatusCreated, res.Code)
+	suite.Equal(1, suite.handlerRequests)
+}
+
+func (suite *IdempotencyTestSuite) TestKeyTooLong() {
+	res := suite.send(testUser, strings.Repeat("k", constants.MaxIdempotencyKeyLength+1), testBody)
+	suite.Equal(er.ErrInvalidIdempotencyKey.HttpStatusCode, res.Code)
+	suite.Equal(0, suite.handlerRequests)
+}
+
+func (suite *IdempotencyTestSuite) TestStoresResponse() {
+	suite.mockRepository.EXPECT().ReserveIdempotencyKey(gomock.Any(), gomock.Any(), gomock.Any(), time.Hour).Return(true, nil)
+	suite.mockRepository.EXPECT().SaveIdempotentResponse(gomock.Any(), gomock.Any(), http.StatusCreated, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(c *gin.Context, key string, statusCode int, headers, body []byte) error {
+			suite.Contains(string(headers), "/api/v1/company/041d2027-e6fa-4d6d-836d-eedb235c82bc")
+			suite.JSONEq(`{"id":"041d2027-e6fa-4d6d-836d-eedb235c82bc"}`, string(body))
+			return nil
+		})
+
+	res := suite.send(testUser, testKey, testBody)
+	suite.Equal(http.StatusCreated, res.Code)
+	suite.Equal(1, suite.handlerRequests)
+}
+
+func (suite *IdempotencyTestSuite) TestReplaysRetry() {
+	var stored models.IdempotencyRecord
+	suite.mockRepository.EXPECT().ReserveIdempotencyKey(gomock.Any(), gomock.Any(), gomock.Any(), time.Hour).
+		DoAndReturn(func(c *gin.Context, key, requestHash string, ttl time.Duration) (bool, error) {
+			if stored.Key != "" {
+				return false, nil
+			}
+			stored = models.IdempotencyRecord{Key: key, RequestHash: requestHash}
+			return true, nil
+		}).Times(2)
+	suite.mockRepository.EXPECT().SaveIdempotentResponse(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(c *gin.Context, key string, statusCode int, headers, body []byte) error {
+			stored.StatusCode, stored.ResponseHeaders, stored.ResponseBody = &statusCode, headers, body
+			return nil
+		})
+	suite.mockRepository.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(c *gin.Context, key string) (models.IdempotencyRecord, error) {
+			suite.Equal(stored.Key, key)
+			return stored, nil
+		})
+
+	first := suite.send(testUser, testKey, testBody)
+	retry := suite.send(testUser, testKey, testBody)
+	suite.Equal(1, suite.handlerRequests)
+	suite.Equal(http.StatusCreated, retry.Code)
+	suite.Equal(first.Body.String(), retry.Body.String())
+	suite.Equal(first.Header().Get("Location"), retry.Header().Get("Location"))
+	suite.Equal("true", retry.Header().Get(constants.IdempotentReplayedHeader))
+}
+
+func (suite *IdempotencyTestSuite) TestKeyIsScopedToUser() {
+	keys := map[string]bool{}
+	suite.mockRepository.EXPECT().ReserveIdempotencyKey(gomock.Any(), gomock.Any(), gomock.Any(), time.Hour).
+		DoAndReturn(func(c *gin.Context, key, requestHash string, ttl time.Duration) (bool, error) {
+			reserved := !keys[key]
+			keys[key] = true
+			return reserved, nil
+		}).Times(2)
+	suite.mockRepository.EXPECT().SaveIdempotentResponse(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+	first := suite.send(testUser, testKey, testBody)
+	second := suite.send(otherUser, testKey, testBody)
+	suite.Len(keys, 2)
+	suite.Equal(2, suite.handlerRequests)
+	suite.Equal(http.StatusCreated, first.Code)
+	suite.Equal(http.StatusCreated, second.Code)
+	suite.Empty(second.Header().Get(constants.IdempotentReplayedHeader))
+}
+
+func (suite *IdempotencyTestSuite) TestRejectsReusedKey() {
+	suite.mockRepository.EXPECT().ReserveIdempotencyKey(gomock.Any(), gomock.Any(), gomock.Any(), time.Hour).Return(false, nil)
+	suite.mockRepository.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Any()).
+		Return(models.IdempotencyRecord{RequestHash: "another request"}, nil)
+
+	res := suite.send(testUser, testKey, `{"name":"beta"}`)
+	suite.Equal(er.ErrIdempotencyKeyReused.HttpStatusCode, res.Code)
+	suite.Equal(0, suite.handlerRequests)
+}
+
+func (suite *IdempotencyTestSuite) TestRejectsRetryInProgress() {
+	suite.mockRepository.EXPECT().ReserveIdempotencyKey(gomock.Any(), gomock.Any(), gomock.Any(), time.Hour).
+		DoAndReturn(func(c *gin.Context, key, requestHash string, ttl time.Duration) (bool, error) {
+			suite.mockRepository.EXPECT().GetIdempotencyKey(gomock.Any(), key).
+				Return(models.IdempotencyRecord{Key: key, RequestHash: requestHash}, nil)
+			return false, nil
+		})
+
+	res := suite.send(testUser, testKey, testBody)
+	suite.Equal(er.ErrIdempotentRequestInProgress.HttpStatusCode, res.Code)
+	suite.Equal(0, suite.handlerRequests)
+}
+
+func (suite *IdempotencyTestSuite) TestReleasesKeyOnServerError() {
+	suite.handlerStatus = http.StatusInternalServerError
+	suite.mockRepository.EXPECT().ReserveIdempotencyKey(gomock.Any(), gomock.Any(), gomock.Any(), time.Hour).Return(true, nil)
+	suite.mockRepository.EXPECT().DeleteIdempotencyKey(gomock.Any(), gomock.Any()).Return(nil)
+
+	res := suite.send(testUser, testKey, testBody)
+	suite.Equal(http.StatusInternalServerError, res.Code)
+}
@@ -1,10 +1,342 @@
 package models
 
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+)
+
 type Company struct {
-	ID                string `json:"id,omitempty" db:"id"  valid:"uuidv4,required"`
-	Name              string `json:"name" db:"name" valid:"stringlength(1|15),required"`
-	Description       string `json:"description,omitempty" db:"description" valid:"maxstringlength(3000)"`
-	AmountOfEmployees int    `json:"amount_of_employees" db:"amount_of_employees" valid:"required"`
-	Registered        bool   `json:"registered" db:"registered" valid:"required"`
-	Type              string `json:"type" db:"type" valid:"in(Corporations|NonProfit|Cooperative|Sole Proprietorship),required"`
+	ID                string    `json:"id,omitempty" db:"id"  valid:"uuid"`
+	Name              string    `json:"name" db:"name" valid:"stringlength(1|15),required"`
+	Description       string    `json:"description,omitempty" db:"description" valid:"maxstringlength(3000)"`
+	AmountOfEmployees int       `json:"amount_of_employees" db:"amount_of_employees" valid:"required"`
+	Registered        bool      `json:"registered" db:"registered" valid:"required"`
+	Type              string    `json:"type" db:"type" valid:"in(Corporations|NonProfit|Cooperative|Sole Proprietorship),required"`
+	Version           int       `json:"version" db:"version" valid:"-"`
+	CreatedAt         time.Time `json:"created_at" db:"created_at" valid:"-"`
+	UpdatedAt         time.Time `json:"updated_at" db:"updated_at" valid:"-"`
+	ParentID          *string   `json:"parent_id" db:"parent_id" valid:"uuid"`
+}
+
+// CompanyNode is a company of a hierarchy with its distance from the company the
+// hierarchy was read from, 1 for its parent or its direct subsidiaries.
+type CompanyNode struct {
+	Company
+	Depth int `json:"depth" db:"depth"`
+}
+
+// CompanyTree is a company with its subsidiaries, each with their own.
+type CompanyTree struct {
+	Company
+	Subsidiaries []*CompanyTree `json:"subsidiaries"`
+}
+
+// DeletedCompany is a soft deleted company waiting in the trash until it is purged.
+type DeletedCompany struct {
+	Company
+	DeletedAt time.Time `json:"deleted_at" db:"deleted_at"`
+}
+
+// CompanyRevision is the state of a company after one change (create, update, delete,
+// restore or revert), with the columns the change touched and the user who made it.
+// RevertedFrom is the revision a revert went back to.
+type CompanyRevision struct {
+	CompanyID     string          `json:"company_id" db:"company_id"`
+	Revision      int             `json:"revision" db:"revision"`
+	Operation     string          `json:"operation" db:"operation"`
+	Snapshot      json.RawMessage `json:"snapshot" db:"snapshot" swaggertype:"object"`
+	ChangedFields pq.StringArray  `json:"changed_fields" db:"changed_fields" swaggertype:"array,string"`
+	Actor         *string         `json:"actor,omitempty" db:"actor"`
+	RevertedFrom  *int            `json:"reverted_from,omitempty" db:"reverted_from"`
+	CreatedAt     time.Time       `json:"created_at" db:"created_at"`
+}
+
+// CompanyRevisionPage is one page of a company's history, newest revision first.
+type CompanyRevisionPage struct {
+	Data       []CompanyRevision `json:"data"`
+	TotalCount int               `json:"total_count"`
+}
+
+// CompanyFilter narrows down the companies returned by a list query.
+// Nil or empty fields are not applied.
+type CompanyFilter struct {
+	Types                []string   `json:"type,omitempty"`
+	Registered           *bool      `json:"registered,omitempty"`
+	MinAmountOfEmployees *int       `json:"min_amount_of_employees,omitempty"`
+	MaxAmountOfEmployees *int       `json:"max_amount_of_employees,omitempty"`
+	MinCreatedAt         *time.Time `json:"min_created_at,omitempty"`
+	MaxCreatedAt         *time.Time `json:"max_created_at,omitempty"`
+	MinUpdatedAt         *time.Time `json:"min_updated_at,omitempty"`
+	MaxUpdatedAt         *time.Time `json:"max_updated_at,omitempty"`
+}
+
+// IsEmpty reports whether the filter has no condition at all.
+func (f CompanyFilter) IsEmpty() bool {
+	return len(f.Types) == 0 && f.Registered == nil && f.MinAmountOfEmployees == nil && f.MaxAmountOfEmployees == nil &&
+		f.MinCreatedAt == nil && f.MaxCreatedAt == nil && f.MinUpdatedAt == nil && f.MaxUpdatedAt == nil
+}
+
+// CompanySelector picks the companies a bulk change applies to, by ID and/or filter.
+type CompanySelector struct {
+	IDs    []string      `json:"ids,omitempty"`
+	Filter CompanyFilter `json:"filter"`
+}
+
+// Cursor is the keyset position of the last company of a page.
+// Sort records the ordering the cursor was issued for.
+type Cursor struct {
+	Sort  string      `json:"s"`
+	Value interface{} `json:"v"`
+	ID    string      `json:"id"`
+}
+
+// CompanyListQuery holds the filter, sorting and keyset pagination options of a list query.
+// A non-nil AsOf lists the companies as they were at that time. Fields lists the columns
+// to read, all of them when empty.
+type CompanyListQuery struct {
+	Filter   CompanyFilter
+	SortBy   string
+	SortDesc bool
+	Limit    int
+	After    *Cursor
+	AsOf     *time.Time
+	Fields   []string
+}
+
+// CompanyPage is one page of a company list.
+type CompanyPage struct {
+	Data       []Company `json:"data"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+	TotalCount int       `json:"total_count"`
+}
+
+// CompanyVersion is a company as it was at a point in time, with the revision it was
+// read from and when that revision was made.
+type CompanyVersion struct {
+	Company
+	Revision   int       `json:"revision" db:"revision"`
+	RevisionAt time.Time `json:"revision_at" db:"revision_at"`
+}
+
+// CompanyVersionPage is one page of a company list at a point in time.
+type CompanyVersionPage struct {
+	Data       []CompanyVersion `json:"data"`
+	NextCursor string           `json:"next_cursor,omitempty"`
+	TotalCount int              `json:"total_count"`
+	AsOf       time.Time        `json:"as_of"`
+}
+
+// ShapedCompanyPage is one page of a company list reduced to the requested fields, with
+// the requested related resources embedded. AsOf is set for a list at a point in time.
+type ShapedCompanyPage struct {
+	Data       []map[string]interface{} `json:"data"`
+	NextCursor string                   `json:"next_cursor,omitempty"`
+	TotalCount int                      `json:"total_count"`
+	AsOf       *time.Time               `json:"as_of,omitempty"`
+}
+
+// CompanyStatsQuery selects the companies aggregated by a stats request, the column
+// they are grouped by and the lower bounds of the amount_of_employees histogram buckets.
+type CompanyStatsQuery struct {
+	Filter  CompanyFilter `json:"filter"`
+	GroupBy string        `json:"group_by"`
+	Buckets []int         `json:"buckets"`
+}
+
+// CompanyStats aggregates the companies matching a stats query.
+type CompanyStats struct {
+	TotalCount      int                 `json:"total_count"`
+	RegisteredCount int                 `json:"registered_count"`
+	RegisteredRatio float64             `json:"registered_ratio"`
+	GroupBy         string              `json:"group_by"`
+	Groups          []CompanyStatsGroup `json:"groups"`
+	Histogram       []EmployeeBucket    `json:"amount_of_employees_histogram"`
+	ComputedAt      time.Time           `json:"computed_at"`
+}
+
+// CompanyStatsGroup counts the companies sharing a value of the grouped column.
+type CompanyStatsGroup struct {
+	Value string `json:"value" db:"value"`
+	Count int    `json:"count" db:"count"`
+}
+
+// EmployeeBucket counts the companies with Min <= amount_of_employees < Max.
+// A nil bound is unbounded.
+type EmployeeBucket struct {
+	Min   *int `json:"min"`
+	Max   *int `json:"max"`
+	Count int  `json:"count"`
+}
+
+// CompanySearchResult is a company matching a full-text search, with its rank and
+// the matched words wrapped in <b></b>.
+type CompanySearchResult struct {
+	Company
+	Rank          float64 `json:"rank" db:"rank"`
+	NameHighlight string  `json:"name_highlight" db:"name_highlight"`
+	Snippet       string  `json:"snippet" db:"snippet"`
+}
+
+// CompanySuggestion is a company name offered for a typed prefix, scored by trigram similarity.
+type CompanySuggestion struct {
+	ID    string  `json:"id" db:"id"`
+	Name  string  `json:"name" db:"name"`
+	Score float64 `json:"score" db:"score"`
+}
+
+// Bulk request modes
+const (
+	BulkModeAtomic  = "atomic"
+	BulkModePartial = "partial"
+)
+
+// Bulk item statuses
+const (
+	BulkItemCreated = "created"
+	BulkItemUpdated = "updated"
+	BulkItemFailed  = "failed"
+	BulkItemSkipped = "skipped"
+)
+
+// BulkItemResult reports the outcome of one item of a bulk request.
+type BulkItemResult struct {
+	Index        int    `json:"index"`
+	ID           string `json:"id,omitempty"`
+	Status       string `json:"status"`
+	ErrorCode    string `json:"error_code,omitempty"`
+	ErrorMessage string `json:"error_message,omitempty"`
+}
+
+// BulkResult is the per-item report of a bulk request.
+type BulkResult struct {
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Items     []BulkItemResult `json:"items"`
+}
+
+// BulkChangeResult reports how many companies a bulk update or delete changed.
+type BulkChangeResult struct {
+	RowsAffected int64 `json:"rows_affected"`
+}
+
+// Import conflict policies, applied when a row matches an existing company
+const (
+	ImportConflictSkip      = "skip"
+	ImportConflictOverwrite = "overwrite"
+	ImportConflictFail      = "fail"
+)
+
+// ImportLineError reports why one line of an import was not imported.
+type ImportLineError struct {
+	Line         int    `json:"line"`
+	ID           string `json:"id,omitempty"`
+	ErrorCode    string `json:"error_code"`
+	ErrorMessage string `json:"error_message"`
+}
+
+// ImportReport summarises an import and lists the lines that failed.
+type ImportReport struct {
+	Processed int               `json:"processed"`
+	Created   int               `json:"created"`
+	Updated   int               `json:"updated"`
+	Skipped   int               `json:"skipped"`
+	Failed    int               `json:"failed"`
+	Errors    []ImportLineError `json:"errors"`
+}
+
+// IdempotencyRecord is the stored response of a request sent with an Idempotency-Key header.
+// StatusCode is nil while the first request is still being handled.
+type IdempotencyRecord struct {
+	Key             string    `db:"key"`
+	RequestHash     string    `db:"request_hash"`
+	StatusCode      *int      `db:"status_code"`
+	ResponseHeaders []byte    `db:"response_headers"`
+	ResponseBody    []byte    `db:"response_body"`
+	ExpiresAt       time.Time `db:"expires_at"`
+}
+
+// Shareholding is the percentage of a company a shareholder company holds from
+// EffectiveFrom until EffectiveTo, open ended when EffectiveTo is nil.
+type Shareholding struct {
+	ID              string     `json:"id" db:"id"`
+	CompanyID       string     `json:"company_id" db:"company_id"`
+	ShareholderID   string     `json:"shareholder_id" db:"shareholder_id" valid:"uuid,required"`
+	ShareholderName string     `json:"shareholder_name" db:"shareholder_name"`
+	Percentage      float64    `json:"percentage" db:"percentage"`
+	EffectiveFrom   time.Time  `json:"effective_from" db:"effective_from"`
+	EffectiveTo     *time.Time `json:"effective_to,omitempty" db:"effective_to"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+}
+
+// UltimateOwner is a company at the top of the ownership chains of another company, with
+// the percentage of it owned through all these chains.
+type UltimateOwner struct {
+	ID                  string  `json:"id"`
+	Name                string  `json:"name"`
+	EffectivePercentage float64 `json:"effective_percentage"`
+}
+
+// OwnershipReport lists the ultimate owners of a company at a point in time. The part of
+// the company that is not traced to an ultimate owner, because its shareholders are not
+// recorded or own each other in a closed cycle, is UnattributedPercentage.
+type OwnershipReport struct {
+	CompanyID              string          `json:"company_id"`
+	AsOf                   time.Time       `json:"as_of"`
+	Owners                 []UltimateOwner `json:"owners"`
+	UnattributedPercentage float64         `json:"unattributed_percentage"`
+}
+
+// Location is an address of a company, its headquarters or one of its branches, with
+// its latitude and longitude in degrees.
+type Location struct {
+	ID         string    `json:"id" db:"id" valid:"-"`
+	CompanyID  string    `json:"company_id" db:"company_id" valid:"-"`
+	Type       string    `json:"type" db:"type" valid:"in(headquarters|branch),required"`
+	Street     string    `json:"street" db:"street" valid:"stringlength(1|200),required"`
+	City       string    `json:"city" db:"city" valid:"stringlength(1|100),required"`
+	PostalCode string    `json:"postal_code" db:"postal_code" valid:"maxstringlength(20)"`
+	Region     string    `json:"region" db:"region" valid:"maxstringlength(100)"`
+	Country    string    `json:"country" db:"country" valid:"ISO3166Alpha2,required"`
+	Latitude   *float64  `json:"latitude" db:"latitude" valid:"-"`
+	Longitude  *float64  `json:"longitude" db:"longitude" valid:"-"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at" valid:"-"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at" valid:"-"`
+}
+
+// NearbyQuery looks for the companies with a location within RadiusKm kilometers of a
+// point.
+type NearbyQuery struct {
+	Latitude  float64
+	Longitude float64
+	RadiusKm  float64
+	Limit     int
+	Offset    int
+}
+
+// NearbyCompany is a company found by a radius search, with its location nearest to the
+// searched point and the great-circle distance to it.
+type NearbyCompany struct {
+	Company
+	Location   Location `json:"location" db:"location"`
+	DistanceKm float64  `json:"distance_km" db:"distance_km"`
+}
+
+// Contact is a person at a company. Phone is in E.164 format, + and the digits.
+type Contact struct {
+	ID        string    `json:"id" db:"id" valid:"-"`
+	CompanyID string    `json:"company_id" db:"company_id" valid:"-"`
+	Name      string    `json:"name" db:"name" valid:"stringlength(1|100),required"`
+	Role      string    `json:"role" db:"role" valid:"maxstringlength(100)"`
+	Email     string    `json:"email" db:"email" valid:"email,maxstringlength(254),required"`
+	Phone     string    `json:"phone" db:"phone" valid:"-"`
+	CreatedAt time.Time `json:"created_at" db:"created_at" valid:"-"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at" valid:"-"`
+}
+
+// ContactSearchResult is a contact matching a search, with the name of its company.
+type ContactSearchResult struct {
+	Contact
+	CompanyName string `json:"company_name" db:"company_name"`
 }
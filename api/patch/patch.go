@@ -0,0 +1,346 @@
+// Package patch applies RFC 7396 JSON Merge Patch and RFC 6902 JSON Patch documents
+// to JSON documents.
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	MergePatchContentType = "application/merge-patch+json"
+	JSONPatchContentType  = "application/json-patch+json"
+)
+
+// ErrTestFailed is returned when a JSON Patch test operation does not match the document.
+var ErrTestFailed = errors.New("test operation failed")
+
+// Patch changes a JSON document.
+type Patch interface {
+	Apply(document []byte) ([]byte, error)
+}
+
+// IsPatchContentType reports whether the media type is one of the supported patch formats.
+func IsPatchContentType(contentType string) bool {
+	return contentType == MergePatchContentType || contentType == JSONPatchContentType
+}
+
+// NewPatch parses a patch document of the given media type.
+func NewPatch(contentType string, body []byte) (Patch, error) {
+	switch contentType {
+	case MergePatchContentType:
+		var merge MergePatch
+		if err := json.Unmarshal(body, &merge.value); err != nil {
+			return nil, err
+		}
+		return merge, nil
+	case JSONPatchContentType:
+		var operations JSONPatch
+		if err := json.Unmarshal(body, &operations); err != nil {
+			return nil, err
+		}
+		for _, operation := range operations {
+			if err := operation.check(); err != nil {
+				return nil, err
+			}
+		}
+		return operations, nil
+	}
+	return nil, fmt.Errorf("unsupported patch format [%s]", contentType)
+}
+
+// MergePatch is an RFC 7396 JSON Merge Patch. Members set to null are removed,
+// objects are merged recursively and any other value replaces the target.
+type MergePatch struct {
+	value interface{}
+}
+
+func (p MergePatch) Apply(document []byte) ([]byte, error) {
+	var target interface{}
+	if err := json.Unmarshal(document, &target); err != nil {
+		return nil, err
+	}
+	return json.Marshal(merge(target, p.value))
+}
+
+func merge(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = merge(targetObject[key], value)
+	}
+	return targetObject
+}
+
+// Operation is one RFC 6902 operation. Value is kept raw so that an explicit null
+// can be told apart from a missing value.
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty" swaggertype:"object"`
+}
+
+func (o Operation) check() error {
+	switch o.Op {
+	case "add", "replace", "test":
+		if o.Value == nil {
+			return fmt.Errorf("%s operation on [%s] has no value", o.Op, o.Path)
+		}
+	case "move", "copy":
+		if _, err := parsePointer(o.From); err != nil {
+			return err
+		}
+	case "remove":
+	default:
+		return fmt.Errorf("unknown operation [%s]", o.Op)
+	}
+	_, err := parsePointer(o.Path)
+	return err
+}
+
+// JSONPatch is an RFC 6902 JSON Patch, a list of operations applied in order.
+// The document is left unchanged unless every operation succeeds.
+type JSONPatch []Operation
+
+func (p JSONPatch) Apply(document []byte) ([]byte, error) {
+	var target interface{}
+	if err := json.Unmarshal(document, &target); err != nil {
+		return nil, err
+	}
+	for _, operation := range p {
+		var err error
+		if target, err = operation.apply(target); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(target)
+}
+
+func (o Operation) apply(target interface{}) (interface{}, error) {
+	path, err := parsePointer(o.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch o.Op {
+	case "add":
+		value, err := o.value()
+		if err != nil {
+			return nil, err
+		}
+		return add(target, path, value)
+	case "remove":
+		target, _, err = remove(target, path)
+		return target, err
+	case "replace":
+		value, err := o.value()
+		if err != nil {
+			return nil, err
+		}
+		// the whole document always exists, replacing it is the same as adding it
+		if len(path) == 0 {
+			return value, nil
+		}
+		if target, _, err = remove(target, path); err != nil {
+			return nil, err
+		}
+		return add(target, path, value)
+	case "move":
+		from, _ := parsePointer(o.From)
+		if o.Path != o.From && strings.HasPrefix(o.Path, o.From+"/") {
+			return nil, fmt.Errorf("cannot move [%s] into itself", o.From)
+		}
+		target, value, err := remove(target, from)
+		if err != nil {
+			return nil, err
+		}
+		return add(target, path, value)
+	case "copy":
+		from, _ := parsePointer(o.From)
+		value, err := get(target, from)
+		if err != nil {
+			return nil, err
+		}
+		// the copy must not share maps or slices with its source
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		var duplicate interface{}
+		if err := json.Unmarshal(raw, &duplicate); err != nil {
+			return nil, err
+		}
+		return add(target, path, duplicate)
+	case "test":
+		expected, err := o.value()
+		if err != nil {
+			return nil, err
+		}
+		actual, err := get(target, path)
+		if err != nil || !reflect.DeepEqual(actual, expected) {
+			return nil, ErrTestFailed
+		}
+		return target, nil
+	}
+	return nil, fmt.Errorf("unknown operation [%s]", o.Op)
+}
+
+func (o Operation) value() (interface{}, error) {
+	var value interface{}
+	err := json.Unmarshal(o.Value, &value)
+	return value, err
+}
+
+// parsePointer splits an RFC 6901 JSON Pointer into its unescaped reference tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid path [%s]", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	unescape := strings.NewReplacer("~1", "/", "~0", "~")
+	for i, token := range tokens {
+		tokens[i] = unescape.Replace(token)
+	}
+	return tokens, nil
+}
+
+func get(target interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch node := target.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("path [%s] does not exist", token)
+			}
+			target = value
+		case []interface{}:
+			index, err := arrayIndex(token, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+			target = node[index]
+		default:
+			return nil, fmt.Errorf("path [%s] does not exist", token)
+		}
+	}
+	return target, nil
+}
+
+// add sets the value at path and returns the changed target. Array members are
+// inserted, "-" appends to the array.
+func add(target interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	token, rest := path[0], path[1:]
+
+	switch node := target.(type) {
+	case map[string]interface{}:
+		if len(rest) == 0 {
+			node[token] = value
+			return node, nil
+		}
+		child, ok := node[token]
+		if !ok {
+			return nil, fmt.Errorf("path [%s] does not exist", token)
+		}
+		child, err := add(child, rest, value)
+		if err != nil {
+			return nil, err
+		}
+		node[token] = child
+		return node, nil
+	case []interface{}:
+		if len(rest) == 0 {
+			index := len(node)
+			if token != "-" {
+				var err error
+				if index, err = arrayIndex(token, len(node)); err != nil {
+					return nil, err
+				}
+			}
+			node = append(node, nil)
+			copy(node[index+1:], node[index:])
+			node[index] = value
+			return node, nil
+		}
+		index, err := arrayIndex(token, len(node)-1)
+		if err != nil {
+			return nil, err
+		}
+		if node[index], err = add(node[index], rest, value); err != nil {
+			return nil, err
+		}
+		return node, nil
+	}
+	return nil, fmt.Errorf("path [%s] does not exist", token)
+}
+
+// remove deletes the value at path and returns the changed target and the removed value.
+func remove(target interface{}, path []string) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, nil, fmt.Errorf("cannot remove the whole document")
+	}
+	token, rest := path[0], path[1:]
+
+	switch node := target.(type) {
+	case map[string]interface{}:
+		child, ok := node[token]
+		if !ok {
+			return nil, nil, fmt.Errorf("path [%s] does not exist", token)
+		}
+		if len(rest) == 0 {
+			delete(node, token)
+			return node, child, nil
+		}
+		child, removed, err := remove(child, rest)
+		if err != nil {
+			return nil, nil, err
+		}
+		node[token] = child
+		return node, removed, nil
+	case []interface{}:
+		index, err := arrayIndex(token, len(node)-1)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(rest) == 0 {
+			removed := node[index]
+			return append(node[:index], node[index+1:]...), removed, nil
+		}
+		child, removed, err := remove(node[index], rest)
+		if err != nil {
+			return nil, nil, err
+		}
+		node[index] = child
+		return node, removed, nil
+	}
+	return nil, nil, fmt.Errorf("path [%s] does not exist", token)
+}
+
+// arrayIndex parses an array reference token no greater than max.
+func arrayIndex(token string, max int) (int, error) {
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || index > max || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index [%s]", token)
+	}
+	return index, nil
+}
@@ -0,0 +1,175 @@
+package patch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type PatchTestSuite struct {
+	suite.Suite
+}
+
+func TestPatch(t *testing.T) {
+	suite.Run(t, new(PatchTestSuite))
+}
+
+type patchCase struct {
+	name     string
+	document string
+	patch    string
+	expected string
+}
+
+func (suite *PatchTestSuite) apply(contentType string, cases []patchCase) {
+	for _, tc := range cases {
+		suite.Run(tc.name, func() {
+			p, err := NewPatch(contentType, []byte(tc.patch))
+			suite.Require().NoError(err)
+			patched, err := p.Apply([]byte(tc.document))
+			suite.Require().NoError(err)
+			suite.JSONEq(tc.expected, string(patched))
+		})
+	}
+}
+
+// The examples of RFC 6902 Appendix A that succeed.
+func (suite *PatchTestSuite) TestJSONPatchExamples() {
+	suite.apply(JSONPatchContentType, []patchCase{
+		{"A.1 adding an object member", `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`},
+		{"A.2 adding an array element", `{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{"A.3 removing an object member", `{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{"A.4 removing an array element", `{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{"A.5 replacing a value", `{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{"A.6 moving a value",
+			`{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`,
+			`[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			`{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{"A.7 moving an array element", `{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{"A.8 testing a value: success",
+			`{"baz":"qux","foo":["a",2,"c"]}`,
+			`[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`,
+			`{"baz":"qux","foo":["a",2,"c"]}`},
+		{"A.10 adding a nested member object", `{"foo":"bar"}`, `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`, `{"foo":"bar","child":{"grandchild":{}}}`},
+		{"A.11 ignoring unrecognized elements", `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux","xyz":123}]`, `{"foo":"bar","baz":"qux"}`},
+		{"A.14 escape ordering", `{"/":9,"~1":10}`, `[{"op":"test","path":"/~01","value":10}]`, `{"/":9,"~1":10}`},
+		{"A.16 adding an array value", `{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`, `{"foo":["bar",["abc","def"]]}`},
+	})
+}
+
+func (suite *PatchTestSuite) TestJSONPatchOperations() {
+	suite.apply(JSONPatchContentType, []patchCase{
+		{"add replaces an existing member", `{"foo":"bar"}`, `[{"op":"add","path":"/foo","value":"baz"}]`, `{"foo":"baz"}`},
+		{"add appends with -", `{"foo":[1,2]}`, `[{"op":"add","path":"/foo/-","value":3}]`, `{"foo":[1,2,3]}`},
+		{"add at the end index", `{"foo":[1,2]}`, `[{"op":"add","path":"/foo/2","value":3}]`, `{"foo":[1,2,3]}`},
+		{"add null value", `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":null}]`, `{"foo":"bar","baz":null}`},
+		{"add the whole document", `{"foo":"bar"}`, `[{"op":"add","path":"","value":{"baz":"qux"}}]`, `{"baz":"qux"}`},
+		{"replace the whole document", `{"foo":"bar"}`, `[{"op":"replace","path":"","value":{"baz":"qux"}}]`, `{"baz":"qux"}`},
+		{"replace an array element", `{"foo":[1,2,3]}`, `[{"op":"replace","path":"/foo/0","value":9}]`, `{"foo":[9,2,3]}`},
+		{"copy does not share its source",
+			`{"foo":{"bar":1}}`,
+			`[{"op":"copy","from":"/foo","path":"/baz"},{"op":"replace","path":"/baz/bar","value":2}]`,
+			`{"foo":{"bar":1},"baz":{"bar":2}}`},
+		{"copy an array element", `{"foo":["a","b"]}`, `[{"op":"copy","from":"/foo/0","path":"/foo/-"}]`, `{"foo":["a","b","a"]}`},
+		{"move to the root", `{"foo":{"bar":1}}`, `[{"op":"move","from":"/foo","path":""}]`, `{"bar":1}`},
+		{"escaped slash", `{"a/b":1}`, `[{"op":"replace","path":"/a~1b","value":2}]`, `{"a/b":2}`},
+		{"escaped tilde", `{"m~n":1}`, `[{"op":"remove","path":"/m~0n"}]`, `{}`},
+		{"test the whole document", `{"foo":[1]}`, `[{"op":"test","path":"","value":{"foo":[1]}}]`, `{"foo":[1]}`},
+		{"test null", `{"foo":null}`, `[{"op":"test","path":"/foo","value":null}]`, `{"foo":null}`},
+	})
+}
+
+func (suite *PatchTestSuite) TestJSONPatchFailures() {
+	cases := []struct {
+		name     string
+		document string
+		patch    string
+	}{
+		{"A.9 testing a value: error", `{"baz":"qux"}`, `[{"op":"test","path":"/baz","value":"bar"}]`},
+		{"A.12 adding to a nonexistent target", `{"foo":"bar"}`, `[{"op":"add","path":"/baz/bat","value":"qux"}]`},
+		{"A.15 comparing strings and numbers", `{"/":9,"~1":10}`, `[{"op":"test","path":"/~01","value":"10"}]`},
+		{"remove a missing member", `{"foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`},
+		{"replace a missing member", `{"foo":"bar"}`, `[{"op":"replace","path":"/baz","value":1}]`},
+		{"remove past the end of an array", `{"foo":[1]}`, `[{"op":"remove","path":"/foo/1"}]`},
+		{"remove with -", `{"foo":[1]}`, `[{"op":"remove","path":"/foo/-"}]`},
+		{"add past the end of an array", `{"foo":[1]}`, `[{"op":"add","path":"/foo/2","value":2}]`},
+		{"array index with a leading zero", `{"foo":[1,2]}`, `[{"op":"replace","path":"/foo/01","value":3}]`},
+		{"move into itself", `{"foo":{"bar":{}}}`, `[{"op":"move","from":"/foo","path":"/foo/bar/baz"}]`},
+		{"copy a missing member", `{"foo":1}`, `[{"op":"copy","from":"/bar","path":"/baz"}]`},
+	}
+	for _, tc := range cases {
+		suite.Run(tc.name, func() {
+			p, err := NewPatch(JSONPatchContentType, []byte(tc.patch))
+			suite.Require().NoError(err)
+			_, err = p.Apply([]byte(tc.document))
+			suite.Error(err)
+		})
+	}
+}
+
+func (suite *PatchTestSuite) TestJSONPatchTestFailureIsReported() {
+	p, err := NewPatch(JSONPatchContentType, []byte(`[{"op":"test","path":"/foo","value":"baz"}]`))
+	suite.Require().NoError(err)
+	_, err = p.Apply([]byte(`{"foo":"bar"}`))
+	suite.Equal(ErrTestFailed, err)
+}
+
+func (suite *PatchTestSuite) TestJSONPatchIsAtomic() {
+	document := []byte(`{"foo":"bar","list":[1,2]}`)
+	p, err := NewPatch(JSONPatchContentType, []byte(`[
+		{"op":"replace","path":"/foo","value":"baz"},
+		{"op":"remove","path":"/list/0"},
+		{"op":"test","path":"/foo","value":"bar"}
+	]`))
+	suite.Require().NoError(err)
+
+	patched, err := p.Apply(document)
+	suite.Equal(ErrTestFailed, err)
+	suite.Nil(patched)
+	suite.JSONEq(`{"foo":"bar","list":[1,2]}`, string(document))
+}
+
+func (suite *PatchTestSuite) TestInvalidJSONPatch() {
+	patches := []string{
+		`{"op":"add","path":"/foo","value":1}`,
+		`[{"op":"add","path":"/foo"}]`,
+		`[{"op":"replace","path":"/foo"}]`,
+		`[{"op":"test","path":"/foo"}]`,
+		`[{"op":"move","from":"foo","path":"/bar"}]`,
+		`[{"op":"remove","path":"foo"}]`,
+		`[{"op":"rename","path":"/foo"}]`,
+	}
+	for _, body := range patches {
+		_, err := NewPatch(JSONPatchContentType, []byte(body))
+		suite.Error(err, body)
+	}
+}
+
+// The examples of RFC 7396 Appendix A.
+func (suite *PatchTestSuite) TestMergePatchExamples() {
+	suite.apply(MergePatchContentType, []patchCase{
+		{"replace member", `{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{"add member", `{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{"remove member", `{"a":"b"}`, `{"a":null}`, `{}`},
+		{"remove one of two members", `{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{"replace array with string", `{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{"replace string with array", `{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{"merge nested object", `{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{"replace array of objects", `{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{"replace array", `["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{"replace object with array", `{"a":"b"}`, `["c"]`, `["c"]`},
+		{"replace with null", `{"a":"foo"}`, `null`, `null`},
+		{"replace with string", `{"a":"foo"}`, `"bar"`, `"bar"`},
+		{"keep an existing null member", `{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{"replace array with object", `[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{"create nested object", `{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	})
+}
+
+func (suite *PatchTestSuite) TestUnsupportedContentType() {
+	_, err := NewPatch("application/json", []byte(`{}`))
+	suite.Error(err)
+	suite.False(IsPatchContentType("application/json"))
+	suite.True(IsPatchContentType(MergePatchContentType))
+	suite.True(IsPatchContentType(JSONPatchContentType))
+}
@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/kumareswaramoorthi/companies/api/constants"
+	"github.com/kumareswaramoorthi/companies/api/logging"
+	"github.com/kumareswaramoorthi/companies/api/models"
+	"github.com/lib/pq"
+)
+
+type ContactRepository interface {
+	CreateContact(c *gin.Context, contact models.Contact) error
+	GetContact(c *gin.Context, companyID, id string) (models.Contact, error)
+	ListContacts(c *gin.Context, companyID string, limit, offset int) ([]models.Contact, error)
+	UpdateContact(c *gin.Context, contact models.Contact) error
+	DeleteContact(c *gin.Context, companyID, id string) error
+	SearchContacts(c *gin.Context, text string, limit, offset int) ([]models.ContactSearchResult, error)
+}
+
+type contactRepository struct {
+	db *sqlx.DB
+}
+
+func NewContactRepository(db *sqlx.DB) ContactRepository {
+	return contactRepository{db: db}
+}
+
+// ErrContactEmailExists is returned when a company would get two contacts with the same email.
+var ErrContactEmailExists = errors.New("company already has a contact with the email")
+
+// contactColumns is the select list matching models.Contact.
+const contactColumns = `id,company_id,name,role,email,phone,created_at,updated_at`
+
+const (
+	insertContact = `INSERT INTO company_contacts (id,company_id,name,role,email,phone) VALUES ($1,$2,$3,$4,$5,$6)`
+	updateContact = `UPDATE company_contacts SET name = $3, role = $4, email = $5, phone = $6 WHERE id = $1 AND company_id = $2`
+	deleteContact = `DELETE FROM company_contacts WHERE id = $1 AND company_id = $2`
+	getContact    = `SELECT ` + contactColumns + ` FROM company_contacts WHERE id = $1 AND company_id = $2`
+	listContacts  = `SELECT ` + contactColumns + ` FROM company_contacts WHERE company_id = $1 ORDER BY name, id LIMIT $2 OFFSET $3`
+)
+
+// searchContacts matches the name, role or email of the contacts of the companies that are
+// not in the trash, through the trigram index on the three of them.
+var searchContacts = `SELECT ` + qualifiedColumns("contacts", contactColumns, "") + `,companies.name AS company_name
+	FROM company_contacts contacts
+	JOIN companies ON companies.id = contacts.company_id AND companies.deleted_at IS NULL
+	WHERE (contacts.name || ' ' || contacts.role || ' ' || contacts.email) ILIKE $1
+	ORDER BY contacts.name, companies.name, contacts.id LIMIT $2 OFFSET $3`
+
+func (r contactRepository) CreateContact(c *gin.Context, contact models.Contact) error {
+
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Repository").
+		WithField(constants.Method, "CreateContact")
+
+	_, err := r.db.ExecContext(c.Request.Context(), insertContact, contact.ID, contact.CompanyID, contact.Name, contact.Role, contact.Email, contact.Phone)
+	if err != nil {
+		logger.Errorf("repository: CreateContact company ID [%s] error: %s", contact.CompanyID, err.Error())
+		return translateContactError(err)
+	}
+
+	logger.Debugf("created contact with ID: [%s]", contact.ID)
+	return nil
+}
+
+// GetContact returns a contact of a company, or sql.ErrNoRows.
+func (r contactRepository) GetContact(c *gin.Context, companyID, id string) (models.Contact, error) {
+
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Repository").
+		WithField(constants.Method, "GetContact")
+
+	var contact models.Contact
+	err := r.db.GetContext(c.Request.Context(), &contact, getContact, id, companyID)
+	if err != nil {
+		logger.Errorf("repository: GetContact ID [%s] error: %s", id, err.Error())
+		return models.Contact{}, err
+	}
+
+	logger.Debugf("found contact with ID: [%s]", id)
+	return contact, nil
+}
+
+// ListContacts returns one page of the contacts of a company, ordered by name.
+func (r contactRepository) ListContacts(c *gin.Context, companyID string, limit, offset int) ([]models.Contact, error) {
+
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Repository").
+		WithField(constants.Method, "ListContacts")
+
+	contacts := []models.Contact{}
+	err := r.db.SelectContext(c.Request.Context(), &contacts, listContacts, companyID, limit, offset)
+	if err != nil {
+		logger.Errorf("repository: ListContacts company ID [%s] error: %s", companyID, err.Error())
+		return nil, err
+	}
+
+	logger.Debugf("found %d contacts of company with ID: [%s]", len(contacts), companyID)
+	return contacts, nil
+}
+
+// UpdateContact replaces the name, role, email and phone of a contact. It returns
+// sql.ErrNoRows when the company has no such contact.
+func (r contactRepository) UpdateContact(c *gin.Context, contact models.Contact) error {
+
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Repository").
+		WithField(constants.Method, "UpdateContact")
+
+	result, err := r.db.ExecContext(c.Request.Context(), updateContact, contact.ID, contact.CompanyID, contact.Name, contact.Role, contact.Email, contact.Phone)
+	if err != nil {
+		logger.Errorf("repository: UpdateContact ID [%s] error: %s", contact.ID, err.Error())
+		return translateContactError(err)
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	logger.Debugf("updated contact with ID: [%s]", contact.ID)
+	return nil
+}
+
+// DeleteContact removes a contact of a company. It returns sql.ErrNoRows when the
+// company has no such contact.
+func (r contactRepository) DeleteContact(c *gin.Context, companyID, id string) error {
+
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Repository").
+		WithField(constants.Method, "DeleteContact")
+
+	result, err := r.db.ExecContext(c.Request.Context(), deleteContact, id, companyID)
+	if err != nil {
+		logger.Errorf("repository: DeleteContact ID [%s] error: %s", id, err.Error())
+		return err
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	logger.Debugf("deleted contact with ID: [%s]", id)
+	return nil
+}
+
+// SearchContacts returns one page of the contacts whose name, role or email contains
+// text, ignoring case, across all companies.
+func (r contactRepository) SearchContacts(c *gin.Context, text string, limit, offset int) ([]models.ContactSearchResult, error) {
+
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Repository").
+		WithField(constants.Method, "SearchContacts")
+
+	contacts := []models.ContactSearchResult{}
+	err := r.db.SelectContext(c.Request.Context(), &contacts, searchContacts, "%"+escapeLike(text)+"%", limit, offset)
+	if err != nil {
+		logger.Errorf("repository: SearchContacts text [%s] error: %s", text, err.Error())
+		return nil, err
+	}
+
+	logger.Debugf("found %d contacts matching [%s]", len(contacts), text)
+	return contacts, nil
+}
+
+// translateContactError maps a second contact with the same email in a company to
+// ErrContactEmailExists.
+func translateContactError(err error) error {
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" && pqErr.Constraint == "company_contacts_email_idx" {
+		return ErrContactEmailExists
+	}
+	return err
+}
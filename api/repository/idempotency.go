@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/kumareswaramoorthi/companies/api/constants"
+	"github.com/kumareswaramoorthi/companies/api/logging"
+	"github.com/kumareswaramoorthi/companies/api/models"
+)
+
+type IdempotencyRepository interface {
+	ReserveIdempotencyKey(c *gin.Context, key, requestHash string, ttl time.Duration) (bool, error)
+	GetIdempotencyKey(c *gin.Context, key string) (models.IdempotencyRecord, error)
+	SaveIdempotentResponse(c *gin.Context, key string, statusCode int, headers, body []byte) error
+	DeleteIdempotencyKey(c *gin.Context, key string) error
+	PurgeExpiredIdempotencyKeys(ctx context.Context) (int64, error)
+}
+
+type idempotencyRepository struct {
+	db *sqlx.DB
+}
+
+func NewIdempotencyRepository(db *sqlx.DB) IdempotencyRepository {
+	return idempotencyRepository{db: db}
+}
+
+const (
+	getIdempotencyKey           = `SELECT key,request_hash,status_code,response_headers,response_body,expires_at FROM idempotency_keys WHERE key = $1`
+	saveIdempotentResponse      = `UPDATE idempotency_keys SET status_code = $2, response_headers = $3, response_body = $4 WHERE key = $1`
+	deleteIdempotencyKey        = `DELETE FROM idempotency_keys WHERE key = $1`
+	purgeExpiredIdempotencyKeys = `DELETE FROM idempotency_keys WHERE expires_at < now()`
+	reserveIdempotencyKey       = `INSERT INTO idempotency_keys (key,request_hash,expires_at) VALUES ($1,$2,now() + make_interval(secs => $3))
+		ON CONFLICT (key) DO UPDATE SET request_hash = EXCLUDED.request_hash, status_code = NULL, response_headers = '{}',
+		response_body = NULL, created_at = now(), expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at < now()`
+)
+
+// ReserveIdempotencyKey claims the key for a new request. It returns false when the key
+// is held by an earlier request that has not expired yet.
+func (r idempotencyRepository) ReserveIdempotencyKey(c *gin.Context, key, requestHash string, ttl time.Duration) (bool, error) {
+
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Repository").
+		WithField(constants.Method, "ReserveIdempotencyKey")
+
+	result, err := r.db.ExecContext(c.Request.Context(), reserveIdempotencyKey, key, requestHash, ttl.Seconds())
+	if err != nil {
+		logger.Errorf("repository: ReserveIdempotencyKey key [%s] error: %s", key, err.Error())
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		logger.Errorf("repository: ReserveIdempotencyKey key [%s] rows affected error: %s", key, err.Error())
+		return false, err
+	}
+	return rows == 1, nil
+}
+
+func (r idempotencyRepository) GetIdempotencyKey(c *gin.Context, key string) (models.IdempotencyRecord, error) {
+
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Repository").
+		WithField(constants.Method, "GetIdempotencyKey")
+
+	var record models.IdempotencyRecord
+	err := r.db.GetContext(c.Request.Context(), &record, getIdempotencyKey, key)
+	if err != nil {
+		logger.Errorf("repository: GetIdempotencyKey key [%s] error: %s", key, err.Error())
+		return models.IdempotencyRecord{}, err
+	}
+	return record, nil
+}
+
+func (r idempotencyRepository) SaveIdempotentResponse(c *gin.Context, key string, statusCode int, headers, body []byte) error {
+
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Repository").
+		WithField(constants.Method, "SaveIdempotentResponse")
+
+	_, err := r.db.ExecContext(c.Request.Context(), saveIdempotentResponse, key, statusCode, headers, body)
+	if err != nil {
+		logger.Errorf("repository: SaveIdempotentResponse key [%s] error: %s", key, err.Error())
+		return err
+	}
+	return nil
+}
+
+func (r idempotencyRepository) DeleteIdempotencyKey(c *gin.Context, key string) error {
+
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Repository").
+		WithField(constants.Method, "DeleteIdempotencyKey")
+
+	_, err := r.db.ExecContext(c.Request.Context(), deleteIdempotencyKey, key)
+	if err != nil {
+		logger.Errorf("repository: DeleteIdempotencyKey key [%s] error: %s", key, err.Error())
+		return err
+	}
+	return nil
+}
+
+// PurgeExpiredIdempotencyKeys deletes the keys whose TTL has passed. It runs in the
+// background, outside of any request.
+func (r idempotencyRepository) PurgeExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+
+	logger := logging.GetLogger(ctx).
+		WithField(constants.Interface, "Repository").
+		WithField(constants.Method, "PurgeExpiredIdempotencyKeys")
+
+	result, err := r.db.ExecContext(ctx, purgeExpiredIdempotencyKeys)
+	if err != nil {
+		logger.Errorf("repository: PurgeExpiredIdempotencyKeys error: %s", err.Error())
+		return 0, err
+	}
+	return result.RowsAffected()
+}
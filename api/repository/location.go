@@ -0,0 +1,223 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/kumareswaramoorthi/companies/api/constants"
+	"github.com/kumareswaramoorthi/companies/api/logging"
+	"github.com/kumareswaramoorthi/companies/api/models"
+	"github.com/lib/pq"
+)
+
+type LocationRepository interface {
+	CreateLocation(c *gin.Context, location models.Location) error
+	GetLocation(c *gin.Context, companyID, id string) (models.Location, error)
+	ListLocations(c *gin.Context, companyID string) ([]models.Location, error)
+	UpdateLocation(c *gin.Context, location models.Location) error
+	DeleteLocation(c *gin.Context, companyID, id string) error
+	ListNearbyCompanies(c *gin.Context, query models.NearbyQuery) ([]models.NearbyCompany, error)
+}
+
+type locationRepository struct {
+	db *sqlx.DB
+}
+
+func NewLocationRepository(db *sqlx.DB) LocationRepository {
+	return locationRepository{db: db}
+}
+
+// ErrHeadquartersExists is returned when a company would get a second headquarters.
+var ErrHeadquartersExists = errors.New("company already has a headquarters")
+
+// locationColumns is the select list matching models.Location.
+const locationColumns = `id,company_id,type,street,city,postal_code,region,country,latitude,longitude,created_at,updated_at`
+
+// distanceKm is the haversine great-circle distance from the point ($1, $2) to a location,
+// on the sphere the bounding band of ListNearbyCompanies is computed on.
+// least keeps rounding errors from taking asin out of its domain.
+var distanceKm = fmt.Sprintf(`2 * %g * asin(least(1, sqrt(
+		power(sin(radians(latitude - $1) / 2), 2) + cos(radians($1)) * cos(radians(latitude)) * power(sin(radians(longitude - $2) / 2), 2))))`,
+	constants.EarthRadiusKm)
+
+const (
+	insertLocation = `INSERT INTO company_locations (id,company_id,type,street,city,postal_code,region,country,latitude,longitude)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)`
+	updateLocation = `UPDATE company_locations SET type = $3, street = $4, city = $5, postal_code = $6, region = $7, country = $8,
+		latitude = $9, longitude = $10 WHERE id = $1 AND company_id = $2`
+	deleteLocation = `DELETE FROM company_locations WHERE id = $1 AND company_id = $2`
+	getLocation    = `SELECT ` + locationColumns + ` FROM company_locations WHERE id = $1 AND company_id = $2`
+	listLocations  = `SELECT ` + locationColumns + ` FROM company_locations WHERE company_id = $1
+		ORDER BY type = 'headquarters' DESC, country, city, id`
+)
+
+// listNearbyCompanies finds the locations within $5 kilometers of the point ($1, $2),
+// first narrowed down to the latitudes between $3 and $4, and keeps the nearest one of
+// every company that is not in the trash.
+var listNearbyCompanies = `WITH nearest AS (
+		SELECT DISTINCT ON (company_id) id, company_id, distance_km FROM (
+			SELECT id, company_id, ` + distanceKm + ` AS distance_km
+			FROM company_locations WHERE latitude BETWEEN $3 AND $4
+		) distances WHERE distance_km <= $5
+		ORDER BY company_id, distance_km, id
+	)
+	SELECT ` + qualifiedColumns("companies", companyColumns, "") + `,
+		` + qualifiedColumns("locations", locationColumns, "location.") + `,nearest.distance_km
+	FROM nearest
+	JOIN companies ON companies.id = nearest.company_id AND companies.deleted_at IS NULL
+	JOIN company_locations locations ON locations.id = nearest.id
+	ORDER BY nearest.distance_km, companies.name, companies.id LIMIT $6 OFFSET $7`
+
+// qualifiedColumns reads a select list from table for queries joining tables with columns
+// of the same names, naming each column after prefix and its name when prefix is set.
+func qualifiedColumns(table, columns, prefix string) string {
+	qualified := strings.Split(columns, ",")
+	for i, column := range qualified {
+		qualified[i] = table + "." + column
+		if prefix != "" {
+			qualified[i] += ` AS "` + prefix + column + `"`
+		}
+	}
+	return strings.Join(qualified, ",")
+}
+
+func (r locationRepository) CreateLocation(c *gin.Context, location models.Location) error {
+
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Repository").
+		WithField(constants.Method, "CreateLocation")
+
+	_, err := r.db.ExecContext(c.Request.Context(), insertLocation, location.ID, location.CompanyID, location.Type, location.Street,
+		location.City, location.PostalCode, location.Region, location.Country, location.Latitude, location.Longitude)
+	if err != nil {
+		logger.Errorf("repository: CreateLocation company ID [%s] error: %s", location.CompanyID, err.Error())
+		return translateLocationError(err)
+	}
+
+	logger.Debugf("created location with ID: [%s]", location.ID)
+	return nil
+}
+
+// GetLocation returns a location of a company, or sql.ErrNoRows.
+func (r locationRepository) GetLocation(c *gin.Context, companyID, id string) (models.Location, error) {
+
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Repository").
+		WithField(constants.Method, "GetLocation")
+
+	var location models.Location
+	err := r.db.GetContext(c.Request.Context(), &location, getLocation, id, companyID)
+	if err != nil {
+		logger.Errorf("repository: GetLocation ID [%s] error: %s", id, err.Error())
+		return models.Location{}, err
+	}
+
+	logger.Debugf("found location with ID: [%s]", id)
+	return location, nil
+}
+
+// ListLocations returns the locations of a company, its headquarters first.
+func (r locationRepository) ListLocations(c *gin.Context, companyID string) ([]models.Location, error) {
+
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Repository").
+		WithField(constants.Method, "ListLocations")
+
+	locations := []models.Location{}
+	err := r.db.SelectContext(c.Request.Context(), &locations, listLocations, companyID)
+	if err != nil {
+		logger.Errorf("repository: ListLocations company ID [%s] error: %s", companyID, err.Error())
+		return nil, err
+	}
+
+	logger.Debugf("found %d locations of company with ID: [%s]", len(locations), companyID)
+	return locations, nil
+}
+
+// UpdateLocation replaces the address and the coordinates of a location. It returns
+// sql.ErrNoRows when the company has no such location.
+func (r locationRepository) UpdateLocation(c *gin.Context, location models.Location) error {
+
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Repository").
+		WithField(constants.Method, "UpdateLocation")
+
+	result, err := r.db.ExecContext(c.Request.Context(), updateLocation, location.ID, location.CompanyID, location.Type, location.Street,
+		location.City, location.PostalCode, location.Region, location.Country, location.Latitude, location.Longitude)
+	if err != nil {
+		logger.Errorf("repository: UpdateLocation ID [%s] error: %s", location.ID, err.Error())
+		return translateLocationError(err)
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	logger.Debugf("updated location with ID: [%s]", location.ID)
+	return nil
+}
+
+// DeleteLocation removes a location of a company. It returns sql.ErrNoRows when the
+// company has no such location.
+func (r locationRepository) DeleteLocation(c *gin.Context, companyID, id string) error {
+
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Repository").
+		WithField(constants.Method, "DeleteLocation")
+
+	result, err := r.db.ExecContext(c.Request.Context(), deleteLocation, id, companyID)
+	if err != nil {
+		logger.Errorf("repository: DeleteLocation ID [%s] error: %s", id, err.Error())
+		return err
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	logger.Debugf("deleted location with ID: [%s]", id)
+	return nil
+}
+
+// ListNearbyCompanies returns one page of the companies with a location within the radius
+// of the query, nearest first.
+func (r locationRepository) ListNearbyCompanies(c *gin.Context, query models.NearbyQuery) ([]models.NearbyCompany, error) {
+
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Repository").
+		WithField(constants.Method, "ListNearbyCompanies")
+
+	// no location further than the radius north or south of the point can be within it
+	latitudeDelta := query.RadiusKm / constants.EarthRadiusKm * 180 / math.Pi
+	minLatitude := math.Max(-90, query.Latitude-latitudeDelta)
+	maxLatitude := math.Min(90, query.Latitude+latitudeDelta)
+
+	companies := []models.NearbyCompany{}
+	err := r.db.SelectContext(c.Request.Context(), &companies, listNearbyCompanies, query.Latitude, query.Longitude,
+		minLatitude, maxLatitude, query.RadiusKm, query.Limit, query.Offset)
+	if err != nil {
+		logger.Errorf("repository: ListNearbyCompanies error: %s", err.Error())
+		return nil, err
+	}
+
+	logger.Debugf("found %d companies within %g km of (%g, %g)", len(companies), query.RadiusKm, query.Latitude, query.Longitude)
+	return companies, nil
+}
+
+// translateLocationError maps the second headquarters of a company to ErrHeadquartersExists.
+func translateLocationError(err error) error {
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" && pqErr.Constraint == "company_locations_headquarters_idx" {
+		return ErrHeadquartersExists
+	}
+	return err
+}
@@ -5,7 +5,9 @@
 package mocks
 
 import (
+        context "context"
         reflect "reflect"
+        time "time"
 
         gin "github.com/gin-gonic/gin"
         gomock "github.com/golang/mock/gomock"
@@ -65,6 +67,21 @@ func (mr *MockRepositoryMockRecorder) CheckCompanyExistsByName(c, name interface
         return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckCompanyExistsByName", reflect.TypeOf((*MockRepository)(nil).CheckCompanyExistsByName), c, name)
 }
 
+// CreateCompanies mocks base method.
+func (m *MockRepository) CreateCompanies(c *gin.Context, companies []models.Company, atomic bool) ([]error, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "CreateCompanies", c, companies, atomic)
+        ret0, _ := ret[0].([]error)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// CreateCompanies indicates an expected call of CreateCompanies.
+func (mr *MockRepositoryMockRecorder) CreateCompanies(c, companies, atomic interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCompanies", reflect.TypeOf((*MockRepository)(nil).CreateCompanies), c, companies, atomic)
+}
+
 // CreateCompany mocks base method.
 func (m *MockRepository) CreateCompany(c *gin.Context, company models.Company) error {
         m.ctrl.T.Helper()
@@ -79,18 +96,47 @@ func (mr *MockRepositoryMockRecorder) CreateCompany(c, company interface{}) *gom
         return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCompany", reflect.TypeOf((*MockRepository)(nil).CreateCompany), c, company)
 }
 
+// DeleteCompanies mocks base method.
+func (m *MockRepository) DeleteCompanies(c *gin.Context, selector models.CompanySelector) (int64, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "DeleteCompanies", c, selector)
+        ret0, _ := ret[0].(int64)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// DeleteCompanies indicates an expected call of DeleteCompanies.
+func (mr *MockRepositoryMockRecorder) DeleteCompanies(c, selector interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCompanies", reflect.TypeOf((*MockRepository)(nil).DeleteCompanies), c, selector)
+}
+
 // DeleteCompany mocks base method.
-func (m *MockRepository) DeleteCompany(c *gin.Context, id string) error {
+func (m *MockRepository) DeleteCompany(c *gin.Context, id string, versions []int, cascade bool) error {
         m.ctrl.T.Helper()
-        ret := m.ctrl.Call(m, "DeleteCompany", c, id)
+        ret := m.ctrl.Call(m, "DeleteCompany", c, id, versions, cascade)
         ret0, _ := ret[0].(error)
         return ret0
 }
 
 // DeleteCompany indicates an expected call of DeleteCompany.
-func (mr *MockRepositoryMockRecorder) DeleteCompany(c, id interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) DeleteCompany(c, id, versions, cascade interface{}) *gomock.Call {
         mr.mock.ctrl.T.Helper()
-        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCompany", reflect.TypeOf((*MockRepository)(nil).DeleteCompany), c, id)
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCompany", reflect.TypeOf((*MockRepository)(nil).DeleteCompany), c, id, versions, cascade)
+}
+
+// ExportCompanies mocks base method.
+func (m *MockRepository) ExportCompanies(c *gin.Context, query models.CompanyListQuery, fn func(models.Company) error) error {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "ExportCompanies", c, query, fn)
+        ret0, _ := ret[0].(error)
+        return ret0
+}
+
+// ExportCompanies indicates an expected call of ExportCompanies.
+func (mr *MockRepositoryMockRecorder) ExportCompanies(c, query, fn interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportCompanies", reflect.TypeOf((*MockRepository)(nil).ExportCompanies), c, query, fn)
 }
 
 // GetCompany mocks base method.
@@ -108,16 +154,300 @@ func (mr *MockRepositoryMockRecorder) GetCompany(c, id interface{}) *gomock.Call
         return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCompany", reflect.TypeOf((*MockRepository)(nil).GetCompany), c, id)
 }
 
+// GetCompanyAsOf mocks base method.
+func (m *MockRepository) GetCompanyAsOf(c *gin.Context, id string, asOf time.Time) (models.CompanyVersion, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "GetCompanyAsOf", c, id, asOf)
+        ret0, _ := ret[0].(models.CompanyVersion)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// GetCompanyAsOf indicates an expected call of GetCompanyAsOf.
+func (mr *MockRepositoryMockRecorder) GetCompanyAsOf(c, id, asOf interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCompanyAsOf", reflect.TypeOf((*MockRepository)(nil).GetCompanyAsOf), c, id, asOf)
+}
+
+// GetCompanyByName mocks base method.
+func (m *MockRepository) GetCompanyByName(c *gin.Context, name string) (models.Company, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "GetCompanyByName", c, name)
+        ret0, _ := ret[0].(models.Company)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// GetCompanyByName indicates an expected call of GetCompanyByName.
+func (mr *MockRepositoryMockRecorder) GetCompanyByName(c, name interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCompanyByName", reflect.TypeOf((*MockRepository)(nil).GetCompanyByName), c, name)
+}
+
+// GetCompanyFields mocks base method.
+func (m *MockRepository) GetCompanyFields(c *gin.Context, id string, fields []string) (models.Company, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "GetCompanyFields", c, id, fields)
+        ret0, _ := ret[0].(models.Company)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// GetCompanyFields indicates an expected call of GetCompanyFields.
+func (mr *MockRepositoryMockRecorder) GetCompanyFields(c, id, fields interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCompanyFields", reflect.TypeOf((*MockRepository)(nil).GetCompanyFields), c, id, fields)
+}
+
+// GetCompanyHistory mocks base method.
+func (m *MockRepository) GetCompanyHistory(c *gin.Context, id string, limit, offset int) (models.CompanyRevisionPage, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "GetCompanyHistory", c, id, limit, offset)
+        ret0, _ := ret[0].(models.CompanyRevisionPage)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// GetCompanyHistory indicates an expected call of GetCompanyHistory.
+func (mr *MockRepositoryMockRecorder) GetCompanyHistory(c, id, limit, offset interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCompanyHistory", reflect.TypeOf((*MockRepository)(nil).GetCompanyHistory), c, id, limit, offset)
+}
+
+// GetCompanyRevision mocks base method.
+func (m *MockRepository) GetCompanyRevision(c *gin.Context, id string, revision int) (models.CompanyRevision, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "GetCompanyRevision", c, id, revision)
+        ret0, _ := ret[0].(models.CompanyRevision)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// GetCompanyRevision indicates an expected call of GetCompanyRevision.
+func (mr *MockRepositoryMockRecorder) GetCompanyRevision(c, id, revision interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCompanyRevision", reflect.TypeOf((*MockRepository)(nil).GetCompanyRevision), c, id, revision)
+}
+
+// GetCompanyStats mocks base method.
+func (m *MockRepository) GetCompanyStats(c *gin.Context, query models.CompanyStatsQuery) (models.CompanyStats, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "GetCompanyStats", c, query)
+        ret0, _ := ret[0].(models.CompanyStats)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// GetCompanyStats indicates an expected call of GetCompanyStats.
+func (mr *MockRepositoryMockRecorder) GetCompanyStats(c, query interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCompanyStats", reflect.TypeOf((*MockRepository)(nil).GetCompanyStats), c, query)
+}
+
+// GetCompanySubtree mocks base method.
+func (m *MockRepository) GetCompanySubtree(c *gin.Context, id string) ([]models.CompanyNode, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "GetCompanySubtree", c, id)
+        ret0, _ := ret[0].([]models.CompanyNode)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// GetCompanySubtree indicates an expected call of GetCompanySubtree.
+func (mr *MockRepositoryMockRecorder) GetCompanySubtree(c, id interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCompanySubtree", reflect.TypeOf((*MockRepository)(nil).GetCompanySubtree), c, id)
+}
+
+// ListCompanies mocks base method.
+func (m *MockRepository) ListCompanies(c *gin.Context, query models.CompanyListQuery) (models.CompanyPage, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "ListCompanies", c, query)
+        ret0, _ := ret[0].(models.CompanyPage)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// ListCompanies indicates an expected call of ListCompanies.
+func (mr *MockRepositoryMockRecorder) ListCompanies(c, query interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCompanies", reflect.TypeOf((*MockRepository)(nil).ListCompanies), c, query)
+}
+
+// ListCompaniesAsOf mocks base method.
+func (m *MockRepository) ListCompaniesAsOf(c *gin.Context, query models.CompanyListQuery) (models.CompanyVersionPage, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "ListCompaniesAsOf", c, query)
+        ret0, _ := ret[0].(models.CompanyVersionPage)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// ListCompaniesAsOf indicates an expected call of ListCompaniesAsOf.
+func (mr *MockRepositoryMockRecorder) ListCompaniesAsOf(c, query interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCompaniesAsOf", reflect.TypeOf((*MockRepository)(nil).ListCompaniesAsOf), c, query)
+}
+
+// ListCompaniesRevisions mocks base method.
+func (m *MockRepository) ListCompaniesRevisions(c *gin.Context, ids []string, limit int) ([]models.CompanyRevision, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "ListCompaniesRevisions", c, ids, limit)
+        ret0, _ := ret[0].([]models.CompanyRevision)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// ListCompaniesRevisions indicates an expected call of ListCompaniesRevisions.
+func (mr *MockRepositoryMockRecorder) ListCompaniesRevisions(c, ids, limit interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCompaniesRevisions", reflect.TypeOf((*MockRepository)(nil).ListCompaniesRevisions), c, ids, limit)
+}
+
+// ListCompanyAncestors mocks base method.
+func (m *MockRepository) ListCompanyAncestors(c *gin.Context, id string) ([]models.CompanyNode, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "ListCompanyAncestors", c, id)
+        ret0, _ := ret[0].([]models.CompanyNode)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// ListCompanyAncestors indicates an expected call of ListCompanyAncestors.
+func (mr *MockRepositoryMockRecorder) ListCompanyAncestors(c, id interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCompanyAncestors", reflect.TypeOf((*MockRepository)(nil).ListCompanyAncestors), c, id)
+}
+
+// ListCompanyChildren mocks base method.
+func (m *MockRepository) ListCompanyChildren(c *gin.Context, id string, limit, offset int) ([]models.Company, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "ListCompanyChildren", c, id, limit, offset)
+        ret0, _ := ret[0].([]models.Company)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// ListCompanyChildren indicates an expected call of ListCompanyChildren.
+func (mr *MockRepositoryMockRecorder) ListCompanyChildren(c, id, limit, offset interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCompanyChildren", reflect.TypeOf((*MockRepository)(nil).ListCompanyChildren), c, id, limit, offset)
+}
+
+// ListDeletedCompanies mocks base method.
+func (m *MockRepository) ListDeletedCompanies(c *gin.Context, limit, offset int) ([]models.DeletedCompany, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "ListDeletedCompanies", c, limit, offset)
+        ret0, _ := ret[0].([]models.DeletedCompany)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// ListDeletedCompanies indicates an expected call of ListDeletedCompanies.
+func (mr *MockRepositoryMockRecorder) ListDeletedCompanies(c, limit, offset interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeletedCompanies", reflect.TypeOf((*MockRepository)(nil).ListDeletedCompanies), c, limit, offset)
+}
+
+// PurgeDeletedCompanies mocks base method.
+func (m *MockRepository) PurgeDeletedCompanies(ctx context.Context, retention time.Duration) (int64, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "PurgeDeletedCompanies", ctx, retention)
+        ret0, _ := ret[0].(int64)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// PurgeDeletedCompanies indicates an expected call of PurgeDeletedCompanies.
+func (mr *MockRepositoryMockRecorder) PurgeDeletedCompanies(ctx, retention interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeletedCompanies", reflect.TypeOf((*MockRepository)(nil).PurgeDeletedCompanies), ctx, retention)
+}
+
+// RestoreCompany mocks base method.
+func (m *MockRepository) RestoreCompany(c *gin.Context, id string) error {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "RestoreCompany", c, id)
+        ret0, _ := ret[0].(error)
+        return ret0
+}
+
+// RestoreCompany indicates an expected call of RestoreCompany.
+func (mr *MockRepositoryMockRecorder) RestoreCompany(c, id interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreCompany", reflect.TypeOf((*MockRepository)(nil).RestoreCompany), c, id)
+}
+
+// SearchCompanies mocks base method.
+func (m *MockRepository) SearchCompanies(c *gin.Context, text string, limit, offset int) ([]models.CompanySearchResult, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "SearchCompanies", c, text, limit, offset)
+        ret0, _ := ret[0].([]models.CompanySearchResult)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// SearchCompanies indicates an expected call of SearchCompanies.
+func (mr *MockRepositoryMockRecorder) SearchCompanies(c, text, limit, offset interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchCompanies", reflect.TypeOf((*MockRepository)(nil).SearchCompanies), c, text, limit, offset)
+}
+
+// SuggestCompanies mocks base method.
+func (m *MockRepository) SuggestCompanies(c *gin.Context, prefix string, limit int) ([]models.CompanySuggestion, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "SuggestCompanies", c, prefix, limit)
+        ret0, _ := ret[0].([]models.CompanySuggestion)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// SuggestCompanies indicates an expected call of SuggestCompanies.
+func (mr *MockRepositoryMockRecorder) SuggestCompanies(c, prefix, limit interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SuggestCompanies", reflect.TypeOf((*MockRepository)(nil).SuggestCompanies), c, prefix, limit)
+}
+
+// UpdateCompanies mocks base method.
+func (m *MockRepository) UpdateCompanies(c *gin.Context, selector models.CompanySelector, updateFields map[string]interface{}) (int64, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "UpdateCompanies", c, selector, updateFields)
+        ret0, _ := ret[0].(int64)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// UpdateCompanies indicates an expected call of UpdateCompanies.
+func (mr *MockRepositoryMockRecorder) UpdateCompanies(c, selector, updateFields interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCompanies", reflect.TypeOf((*MockRepository)(nil).UpdateCompanies), c, selector, updateFields)
+}
+
 // UpdateCompany mocks base method.
-func (m *MockRepository) UpdateCompany(c *gin.Context, updateFields map[string]interface{}, id string) error {
+func (m *MockRepository) UpdateCompany(c *gin.Context, updateFields map[string]interface{}, id string, versions []int) error {
         m.ctrl.T.Helper()
-        ret := m.ctrl.Call(m, "UpdateCompany", c, updateFields, id)
+        ret := m.ctrl.Call(m, "UpdateCompany", c, updateFields, id, versions)
         ret0, _ := ret[0].(error)
         return ret0
 }
 
 // UpdateCompany indicates an expected call of UpdateCompany.
-func (mr *MockRepositoryMockRecorder) UpdateCompany(c, updateFields, id interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) UpdateCompany(c, updateFields, id, versions interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCompany", reflect.TypeOf((*MockRepository)(nil).UpdateCompany), c, updateFields, id, versions)
+}
+
+// UpsertCompany mocks base method.
+func (m *MockRepository) UpsertCompany(c *gin.Context, company models.Company, onConflict string) (string, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "UpsertCompany", c, company, onConflict)
+        ret0, _ := ret[0].(string)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// UpsertCompany indicates an expected call of UpsertCompany.
+func (mr *MockRepositoryMockRecorder) UpsertCompany(c, company, onConflict interface{}) *gomock.Call {
         mr.mock.ctrl.T.Helper()
-        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCompany", reflect.TypeOf((*MockRepository)(nil).UpdateCompany), c, updateFields, id)
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertCompany", reflect.TypeOf((*MockRepository)(nil).UpsertCompany), c, company, onConflict)
 }
\ No newline at end of file
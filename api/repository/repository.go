@@ -1,9 +1,14 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-contrib/requestid"
 	"github.com/gin-gonic/gin"
@@ -11,17 +16,58 @@ import (
 	"github.com/kumareswaramoorthi/companies/api/constants"
 	"github.com/kumareswaramoorthi/companies/api/logging"
 	"github.com/kumareswaramoorthi/companies/api/models"
+	"github.com/kumareswaramoorthi/companies/api/utils"
+	"github.com/lib/pq"
 )
 
 type Repository interface {
 	CreateCompany(c *gin.Context, company models.Company) error
+	CreateCompanies(c *gin.Context, companies []models.Company, atomic bool) ([]error, error)
 	GetCompany(c *gin.Context, id string) (models.Company, error)
-	DeleteCompany(c *gin.Context, id string) error
+	GetCompanyFields(c *gin.Context, id string, fields []string) (models.Company, error)
+	GetCompanyByName(c *gin.Context, name string) (models.Company, error)
+	DeleteCompany(c *gin.Context, id string, versions []int, cascade bool) error
 	CheckCompanyExistsByName(c *gin.Context, name string) (bool, error)
 	CheckCompanyExistsByID(c *gin.Context, id string) (bool, error)
-	UpdateCompany(c *gin.Context, updateFields map[string]interface{}, id string) error
+	UpdateCompany(c *gin.Context, updateFields map[string]interface{}, id string, versions []int) error
+	UpdateCompanies(c *gin.Context, selector models.CompanySelector, updateFields map[string]interface{}) (int64, error)
+	DeleteCompanies(c *gin.Context, selector models.CompanySelector) (int64, error)
+	ListCompanies(c *gin.Context, query models.CompanyListQuery) (models.CompanyPage, error)
+	SearchCompanies(c *gin.Context, text string, limit, offset int) ([]models.CompanySearchResult, error)
+	SuggestCompanies(c *gin.Context, prefix string, limit int) ([]models.CompanySuggestion, error)
+	UpsertCompany(c *gin.Context, company models.Company, onConflict string) (string, error)
+	ExportCompanies(c *gin.Context, query models.CompanyListQuery, fn func(models.Company) error) error
+	ListDeletedCompanies(c *gin.Context, limit, offset int) ([]models.DeletedCompany, error)
+	RestoreCompany(c *gin.Context, id string) error
+	PurgeDeletedCompanies(ctx context.Context, retention time.Duration) (int64, error)
+	GetCompanyHistory(c *gin.Context, id string, limit, offset int) (models.CompanyRevisionPage, error)
+	ListCompaniesRevisions(c *gin.Context, ids []string, limit int) ([]models.CompanyRevision, error)
+	GetCompanyRevision(c *gin.Context, id string, revision int) (models.CompanyRevision, error)
+	GetCompanyAsOf(c *gin.Context, id string, asOf time.Time) (models.CompanyVersion, error)
+	ListCompaniesAsOf(c *gin.Context, query models.CompanyListQuery) (models.CompanyVersionPage, error)
+	GetCompanyStats(c *gin.Context, query models.CompanyStatsQuery) (models.CompanyStats, error)
+	ListCompanyAncestors(c *gin.Context, id string) ([]models.CompanyNode, error)
+	ListCompanyChildren(c *gin.Context, id string, limit, offset int) ([]models.Company, error)
+	GetCompanySubtree(c *gin.Context, id string) ([]models.CompanyNode, error)
 }
 
+var (
+	// ErrCompanyNameExists is returned when a write violates the unique company name.
+	ErrCompanyNameExists = errors.New("company name already exists")
+	// ErrCompanyIDExists is returned when a write reuses an existing company ID.
+	ErrCompanyIDExists = errors.New("company ID already exists")
+	// ErrCompanyInTrash is returned when a write would overwrite a company in the trash.
+	ErrCompanyInTrash = errors.New("company is in the trash")
+	// ErrVersionMismatch is returned when a conditional write finds the company at another version.
+	ErrVersionMismatch = errors.New("company version does not match")
+	// ErrParentNotFound is returned when the parent of a company does not exist or is in the trash.
+	ErrParentNotFound = errors.New("parent company does not exist")
+	// ErrHierarchyCycle is returned when a company would become a subsidiary of itself.
+	ErrHierarchyCycle = errors.New("company cannot be a subsidiary of itself or of one of its subsidiaries")
+	// ErrCompanyHasSubsidiaries is returned when a company with subsidiaries is deleted without them.
+	ErrCompanyHasSubsidiaries = errors.New("company has subsidiaries")
+)
+
 type repository struct {
 	db *sqlx.DB
 }
@@ -30,12 +76,120 @@ func NewRepository(db *sqlx.DB) Repository {
 	return repository{db: db}
 }
 
+// companyColumns is the select list matching models.Company.
+const companyColumns = `id,name,description,amount_of_employees,registered,type,version,created_at,updated_at,parent_id`
+
+// companyRevisionColumns is the select list matching models.CompanyRevision.
+const companyRevisionColumns = `company_id,revision,operation,snapshot,changed_fields,actor,reverted_from,created_at`
+
+// companiesAsOf shadows the companies table with the latest revision of every company
+// made up to a point in time, given by the placeholder number it is formatted with.
+// The revision snapshots hold the companies columns, so the usual queries and filters
+// run unchanged against it.
+const companiesAsOf = `WITH companies AS (
+		SELECT DISTINCT ON (company_id) company_id AS id,
+			snapshot->>'name' AS name,
+			coalesce(snapshot->>'description', '') AS description,
+			(snapshot->>'amount_of_employees')::integer AS amount_of_employees,
+			(snapshot->>'registered')::boolean AS registered,
+			snapshot->>'type' AS type,
+			coalesce((snapshot->>'version')::integer, 1) AS version,
+			coalesce((snapshot->>'created_at')::timestamptz, min(created_at) OVER (PARTITION BY company_id)) AS created_at,
+			coalesce((snapshot->>'updated_at')::timestamptz, created_at) AS updated_at,
+			(snapshot->>'deleted_at')::timestamptz AS deleted_at,
+			(snapshot->>'parent_id')::uuid AS parent_id,
+			revision,
+			created_at AS revision_at
+		FROM company_revisions
+		WHERE created_at <= $%d
+		ORDER BY company_id, revision DESC
+	) `
+
+// notDeleted hides soft deleted companies, which keep a deleted_at tombstone until they are purged.
+const notDeleted = `deleted_at IS NULL`
+
 const (
-	insertCompany            = `INSERT INTO companies (id,name,description,amount_of_employees,registered,type) VALUES ($1,$2,$3,$4,$5,$6)`
-	getCompany               = `SELECT * FROM companies WHERE id  = $1`
-	checkCompanyExistsByName = `SELECT EXISTS(SELECT 1 FROM companies where name = $1)`
-	checkCompanyExistsByID   = `SELECT EXISTS(SELECT 1 FROM companies where id = $1)`
-	deleteCompany            = `DELETE  FROM companies WHERE id  = $1`
+	insertCompany            = `INSERT INTO companies (id,name,description,amount_of_employees,registered,type,parent_id) VALUES ($1,$2,$3,$4,$5,$6,$7)`
+	insertCompanyOrSkip      = insertCompany + ` ON CONFLICT DO NOTHING`
+	savepointBulkItem        = `SAVEPOINT bulk_item`
+	releaseBulkItem          = `RELEASE SAVEPOINT bulk_item`
+	rollbackBulkItem         = `ROLLBACK TO SAVEPOINT bulk_item`
+	getCompany               = `SELECT ` + companyColumns + ` FROM companies WHERE id  = $1 AND ` + notDeleted
+	getCompanyByName         = `SELECT ` + companyColumns + ` FROM companies WHERE lower(name) = lower($1) AND ` + notDeleted + ` ORDER BY name = $1 DESC LIMIT 1`
+	checkCompanyExistsByName = `SELECT EXISTS(SELECT 1 FROM companies where name = $1 AND ` + notDeleted + `)`
+	checkCompanyExistsByID   = `SELECT EXISTS(SELECT 1 FROM companies where id = $1 AND ` + notDeleted + `)`
+	deleteCompany            = `UPDATE companies SET deleted_at = now() WHERE id  = $1 AND ` + notDeleted
+	deleteCompanies          = `UPDATE companies SET deleted_at = now()`
+	subtreeRoot              = `SELECT id FROM companies WHERE id = $1 AND ` + notDeleted
+	restoreCompany           = `UPDATE companies SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL`
+	purgeDeletedCompanies    = `DELETE FROM companies WHERE deleted_at < now() - make_interval(secs => $1)`
+	getCompanyFields         = `SELECT %s FROM companies WHERE id  = $1 AND ` + notDeleted
+	listCompanies            = `SELECT %s FROM companies`
+	listCompanyVersions      = `SELECT %s,revision,revision_at FROM companies`
+	getCompanyVersion        = `SELECT ` + companyColumns + `,revision,revision_at FROM companies WHERE id = $1 AND ` + notDeleted
+	countCompanies           = `SELECT COUNT(*) FROM companies`
+	countRegisteredCompanies = `SELECT COUNT(*), COUNT(*) FILTER (WHERE registered) FROM companies`
+	groupCompanies           = `SELECT %s::text AS value, COUNT(*) AS count FROM companies%s GROUP BY 1 ORDER BY count DESC, value`
+	bucketCompanies          = `SELECT width_bucket(amount_of_employees, $%d::integer[]) AS bucket, COUNT(*) AS count FROM companies%s GROUP BY 1`
+	setRevisionActor         = `SELECT set_config('companies.actor', $1, true), set_config('companies.reverted_from', $2, true)`
+	getCompanyRevision       = `SELECT ` + companyRevisionColumns + ` FROM company_revisions WHERE company_id = $1 AND revision = $2`
+	countCompanyRevisions    = `SELECT COUNT(*) FROM company_revisions WHERE company_id = $1`
+	searchCompanies          = `SELECT ` + companyColumns + `,
+		ts_rank(search_vector, query) AS rank,
+		ts_headline('english', name, query, 'HighlightAll=true') AS name_highlight,
+		ts_headline('english', coalesce(description, ''), query, 'MaxFragments=2, MaxWords=30, MinWords=10') AS snippet
+		FROM companies, websearch_to_tsquery('english', $1) query
+		WHERE search_vector @@ query AND deleted_at IS NULL
+		ORDER BY rank DESC, id
+		LIMIT $2 OFFSET $3`
+	suggestCompanies = `SELECT id, name, similarity(name, $1) AS score
+		FROM companies
+		WHERE (name ILIKE $2 OR name % $1) AND deleted_at IS NULL
+		ORDER BY name ILIKE $2 DESC, score DESC, name
+		LIMIT $3`
+	listDeletedCompanies = `SELECT ` + companyColumns + `,deleted_at FROM companies WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC, id
+		LIMIT $1 OFFSET $2`
+	upsertCompany = insertCompany + ` ON CONFLICT (id) DO UPDATE SET
+		name = EXCLUDED.name, description = EXCLUDED.description, amount_of_employees = EXCLUDED.amount_of_employees,
+		registered = EXCLUDED.registered, type = EXCLUDED.type, parent_id = EXCLUDED.parent_id
+		WHERE companies.deleted_at IS NULL
+		RETURNING (xmax = 0) AS inserted`
+	listCompanyRevisions = `SELECT ` + companyRevisionColumns + `
+		FROM company_revisions WHERE company_id = $1
+		ORDER BY revision DESC
+		LIMIT $2 OFFSET $3`
+	listCompaniesRevisions = `SELECT ` + companyRevisionColumns + ` FROM (
+			SELECT *, row_number() OVER (PARTITION BY company_id ORDER BY revision DESC) AS position
+			FROM company_revisions WHERE company_id = ANY($1)
+		) revisions
+		WHERE position <= $2
+		ORDER BY company_id, revision DESC`
+	deleteCompanySubtree = `WITH RECURSIVE subtree AS (
+			%s
+			UNION ALL
+			SELECT companies.id FROM subtree JOIN companies ON companies.parent_id = subtree.id
+			WHERE companies.deleted_at IS NULL
+		)
+		UPDATE companies SET deleted_at = now() WHERE id IN (SELECT id FROM subtree)`
+	listCompanyAncestors = `WITH RECURSIVE ancestors AS (
+			SELECT parent.*, 1 AS depth FROM companies child JOIN companies parent ON parent.id = child.parent_id
+			WHERE child.id = $1 AND child.deleted_at IS NULL AND parent.deleted_at IS NULL
+			UNION ALL
+			SELECT parent.*, ancestors.depth + 1 FROM ancestors JOIN companies parent ON parent.id = ancestors.parent_id
+			WHERE parent.deleted_at IS NULL
+		)
+		SELECT ` + companyColumns + `,depth FROM ancestors ORDER BY depth`
+	listCompanyChildren = `SELECT ` + companyColumns + ` FROM companies WHERE parent_id = $1 AND deleted_at IS NULL
+		ORDER BY name, id
+		LIMIT $2 OFFSET $3`
+	getCompanySubtree = `WITH RECURSIVE subtree AS (
+			SELECT companies.*, 0 AS depth FROM companies WHERE id = $1 AND deleted_at IS NULL
+			UNION ALL
+			SELECT companies.*, subtree.depth + 1 FROM subtree JOIN companies ON companies.parent_id = subtree.id
+			WHERE companies.deleted_at IS NULL
+		)
+		SELECT ` + companyColumns + `,depth FROM subtree ORDER BY depth, name, id`
 )
 
 func (r repository) CreateCompany(c *gin.Context, company models.Company) error {
@@ -45,16 +199,136 @@ func (r repository) CreateCompany(c *gin.Context, company models.Company) error
 		WithField(constants.Interface, "Repository").
 		WithField(constants.Method, "CreateCompany")
 
-	_, err := r.db.ExecContext(c.Request.Context(), insertCompany, company.ID, company.Name, company.Description, company.AmountOfEmployees, company.Registered, company.Type)
+	_, err := r.execAsActor(c, insertCompany, company.ID, company.Name, company.Description, company.AmountOfEmployees, company.Registered, company.Type, company.ParentID)
 	if err != nil {
 		logger.Errorf("repository: CreateCompany ID [%s]", err.Error())
-		return err
+		return translateWriteError(err)
 	}
 
 	logger.Debugf("created company with ID: [%s]", company.ID)
 	return nil
 }
 
+// CreateCompanies inserts the companies in one transaction and returns the error of
+// each failed item. In atomic mode the first failure rolls the whole batch back,
+// otherwise every item runs under its own savepoint and the others are committed.
+func (r repository) CreateCompanies(c *gin.Context, companies []models.Company, atomic bool) ([]error, error) {
+
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Repository").
+		WithField(constants.Method, "CreateCompanies")
+
+	ctx := c.Request.Context()
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.Errorf("repository: CreateCompanies begin error: %s", err.Error())
+		return nil, err
+	}
+	if err = setActor(c, tx); err != nil {
+		_ = tx.Rollback()
+		logger.Errorf("repository: CreateCompanies set actor error: %s", err.Error())
+		return nil, err
+	}
+
+	itemErrs := make([]error, len(companies))
+	for i, company := range companies {
+		if !atomic {
+			if _, err = tx.ExecContext(ctx, savepointBulkItem); err != nil {
+				_ = tx.Rollback()
+				logger.Errorf("repository: CreateCompanies savepoint error: %s", err.Error())
+				return nil, err
+			}
+		}
+
+		_, err = tx.ExecContext(ctx, insertCompany, company.ID, company.Name, company.Description, company.AmountOfEmployees, company.Registered, company.Type, company.ParentID)
+		if err == nil {
+			if !atomic {
+				if _, err = tx.ExecContext(ctx, releaseBulkItem); err != nil {
+					_ = tx.Rollback()
+					logger.Errorf("repository: CreateCompanies release error: %s", err.Error())
+					return nil, err
+				}
+			}
+			continue
+		}
+
+		logger.Errorf("repository: CreateCompanies item [%d] ID [%s] error: %s", i, company.ID, err.Error())
+		itemErrs[i] = translateWriteError(err)
+		if atomic {
+			_ = tx.Rollback()
+			return itemErrs, nil
+		}
+		if _, err = tx.ExecContext(ctx, rollbackBulkItem); err != nil {
+			_ = tx.Rollback()
+			logger.Errorf("repository: CreateCompanies rollback to savepoint error: %s", err.Error())
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.Errorf("repository: CreateCompanies commit error: %s", err.Error())
+		return nil, err
+	}
+
+	logger.Debugf("created %d companies in bulk", len(companies))
+	return itemErrs, nil
+}
+
+// UpsertCompany writes one imported company and returns whether it was created, updated or skipped.
+// A conflict on the ID or name is skipped or fails the write depending on onConflict. Overwrite
+// only matches the existing company by ID, so it still fails on another company's name, and
+// returns ErrCompanyInTrash rather than restoring a company in the trash.
+func (r repository) UpsertCompany(c *gin.Context, company models.Company, onConflict string) (string, error) {
+
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Repository").
+		WithField(constants.Method, "UpsertCompany")
+
+	ctx := c.Request.Context()
+	args := []interface{}{company.ID, company.Name, company.Description, company.AmountOfEmployees, company.Registered, company.Type, company.ParentID}
+
+	status := models.BulkItemCreated
+	err := r.withActor(c, func(tx *sqlx.Tx) error {
+		switch onConflict {
+		case models.ImportConflictOverwrite:
+			var inserted bool
+			err := tx.QueryRowxContext(ctx, upsertCompany, args...).Scan(&inserted)
+			if err == sql.ErrNoRows {
+				return ErrCompanyInTrash
+			}
+			if err != nil {
+				return err
+			}
+			if !inserted {
+				status = models.BulkItemUpdated
+			}
+			return nil
+		case models.ImportConflictSkip:
+			result, err := tx.ExecContext(ctx, insertCompanyOrSkip, args...)
+			if err != nil {
+				return err
+			}
+			rows, err := result.RowsAffected()
+			if err != nil {
+				return err
+			}
+			if rows == 0 {
+				status = models.BulkItemSkipped
+			}
+			return nil
+		}
+		_, err := tx.ExecContext(ctx, insertCompany, args...)
+		return err
+	})
+	if err != nil {
+		logger.Errorf("repository: UpsertCompany ID [%s] error: %s", company.ID, err.Error())
+		return "", translateWriteError(err)
+	}
+	return status, nil
+}
+
 func (r repository) GetCompany(c *gin.Context, id string) (models.Company, error) {
 
 	logger := logging.GetLogger(c).
@@ -78,20 +352,81 @@ func (r repository) GetCompany(c *gin.Context, id string) (models.Company, error
 	return company, nil
 }
 
-func (r repository) DeleteCompany(c *gin.Context, id string) error {
+// GetCompanyFields returns a company with only the given fields read, all of them when
+// fields is empty. The id, version and updated_at the cache headers need are always read.
+func (r repository) GetCompanyFields(c *gin.Context, id string, fields []string) (models.Company, error) {
+
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Repository").
+		WithField(constants.Method, "GetCompanyFields")
+
+	var company models.Company
+	query := fmt.Sprintf(getCompanyFields, selectColumns(fields, "id", "version", "updated_at"))
+	err := r.db.GetContext(c.Request.Context(), &company, query, id)
+
+	switch {
+	case err == sql.ErrNoRows:
+		logger.Errorf("no rows found for ID: [%s]", id)
+		return models.Company{}, err
+	case err != nil:
+		logger.Errorf("repository: GetCompanyFields ID [%s] error: %s", id, err.Error())
+		return models.Company{}, err
+	}
+
+	logger.Debugf("found company for ID: [%s]", id)
+	return company, nil
+}
+
+func (r repository) GetCompanyByName(c *gin.Context, name string) (models.Company, error) {
+
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Repository").
+		WithField(constants.Method, "GetCompanyByName")
+
+	var company models.Company
+	err := r.db.GetContext(c.Request.Context(), &company, getCompanyByName, name)
+
+	switch {
+	case err == sql.ErrNoRows:
+		logger.Errorf("no rows found for name: [%s]", name)
+		return models.Company{}, err
+	case err != nil:
+		logger.Errorf("repository: GetCompanyByName name [%s] error: %s", name, err.Error())
+		return models.Company{}, err
+	}
+
+	logger.Debugf("found company for name: [%s]", name)
+	return company, nil
+}
+
+// DeleteCompany moves a company to the trash. When versions is not nil, the company is
+// only deleted at one of these versions, otherwise ErrVersionMismatch is returned.
+// With cascade its subsidiaries go to the trash with it, otherwise a company with
+// subsidiaries is kept and ErrCompanyHasSubsidiaries is returned.
+func (r repository) DeleteCompany(c *gin.Context, id string, versions []int, cascade bool) error {
 
 	logger := logging.GetLogger(c).
 		WithField(constants.ReqID, requestid.Get(c)).
 		WithField(constants.Interface, "Repository").
 		WithField(constants.Method, "DeleteCompany")
 
-	result, err := r.db.ExecContext(c.Request.Context(), deleteCompany, id)
+	sql, args := withVersions(deleteCompany, []interface{}{id}, versions)
+	if cascade {
+		sql, args = withVersions(subtreeRoot, []interface{}{id}, versions)
+		sql = fmt.Sprintf(deleteCompanySubtree, sql)
+	}
+	result, err := r.execAsActor(c, sql, args...)
 	if err != nil {
 		logger.Errorf("repository: DeleteCompany ID [%s] error: %s", id, err.Error())
-		return err
+		return translateWriteError(err)
 	}
 
 	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 && versions != nil {
+		return ErrVersionMismatch
+	}
 	if rowsAffected == 0 {
 		return fmt.Errorf("no company found for ID [%s]", id)
 	}
@@ -100,6 +435,136 @@ func (r repository) DeleteCompany(c *gin.Context, id string) error {
 	return nil
 }
 
+// ListDeletedCompanies returns the companies in the trash, most recently deleted first.
+func (r repository) ListDeletedCompanies(c *gin.Context, limit, offset int) ([]models.DeletedCompany, error) {
+
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Repository").
+		WithField(constants.Method, "ListDeletedCompanies")
+
+	companies := []models.DeletedCompany{}
+	err := r.db.SelectContext(c.Request.Context(), &companies, listDeletedCompanies, limit, offset)
+	if err != nil {
+		logger.Errorf("repository: ListDeletedCompanies error: %s", err.Error())
+		return nil, err
+	}
+
+	logger.Debugf("listed %d deleted companies", len(companies))
+	return companies, nil
+}
+
+// RestoreCompany takes a company out of the trash. It returns sql.ErrNoRows when the
+// company is not in the trash and ErrCompanyNameExists when its name was taken meanwhile.
+func (r repository) RestoreCompany(c *gin.Context, id string) error {
+
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Repository").
+		WithField(constants.Method, "RestoreCompany")
+
+	result, err := r.execAsActor(c, restoreCompany, id)
+	if err != nil {
+		logger.Errorf("repository: RestoreCompany ID [%s] error: %s", id, err.Error())
+		return translateWriteError(err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	logger.Debugf("restored company with ID %s", id)
+	return nil
+}
+
+// PurgeDeletedCompanies hard deletes the companies that have been in the trash for
+// longer than retention. It runs in the background, outside of any request.
+func (r repository) PurgeDeletedCompanies(ctx context.Context, retention time.Duration) (int64, error) {
+
+	logger := logging.GetLogger(ctx).
+		WithField(constants.Interface, "Repository").
+		WithField(constants.Method, "PurgeDeletedCompanies")
+
+	result, err := r.db.ExecContext(ctx, purgeDeletedCompanies, retention.Seconds())
+	if err != nil {
+		logger.Errorf("repository: PurgeDeletedCompanies error: %s", err.Error())
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// GetCompanyHistory returns one page of the revisions of a company, newest first, and
+// the number of revisions. The history is kept when the company is deleted.
+func (r repository) GetCompanyHistory(c *gin.Context, id string, limit, offset int) (models.CompanyRevisionPage, error) {
+
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Repository").
+		WithField(constants.Method, "GetCompanyHistory")
+
+	page := models.CompanyRevisionPage{Data: []models.CompanyRevision{}}
+
+	err := r.db.GetContext(c.Request.Context(), &page.TotalCount, countCompanyRevisions, id)
+	if err != nil {
+		logger.Errorf("repository: GetCompanyHistory ID [%s] count error: %s", id, err.Error())
+		return models.CompanyRevisionPage{}, err
+	}
+
+	err = r.db.SelectContext(c.Request.Context(), &page.Data, listCompanyRevisions, id, limit, offset)
+	if err != nil {
+		logger.Errorf("repository: GetCompanyHistory ID [%s] error: %s", id, err.Error())
+		return models.CompanyRevisionPage{}, err
+	}
+
+	logger.Debugf("found %d revisions for ID: [%s]", len(page.Data), id)
+	return page, nil
+}
+
+// ListCompaniesRevisions returns up to limit of the latest revisions of each of the given
+// companies, ordered by company and newest revision first.
+func (r repository) ListCompaniesRevisions(c *gin.Context, ids []string, limit int) ([]models.CompanyRevision, error) {
+
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Repository").
+		WithField(constants.Method, "ListCompaniesRevisions")
+
+	revisions := []models.CompanyRevision{}
+	err := r.db.SelectContext(c.Request.Context(), &revisions, listCompaniesRevisions, pq.Array(ids), limit)
+	if err != nil {
+		logger.Errorf("repository: ListCompaniesRevisions error: %s", err.Error())
+		return nil, err
+	}
+
+	logger.Debugf("found %d revisions of %d companies", len(revisions), len(ids))
+	return revisions, nil
+}
+
+// GetCompanyRevision returns one revision of a company, or sql.ErrNoRows when it does not exist.
+func (r repository) GetCompanyRevision(c *gin.Context, id string, revision int) (models.CompanyRevision, error) {
+
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Repository").
+		WithField(constants.Method, "GetCompanyRevision")
+
+	var companyRevision models.CompanyRevision
+	err := r.db.GetContext(c.Request.Context(), &companyRevision, getCompanyRevision, id, revision)
+
+	switch {
+	case err == sql.ErrNoRows:
+		logger.Errorf("no revision [%d] found for ID: [%s]", revision, id)
+		return models.CompanyRevision{}, err
+	case err != nil:
+		logger.Errorf("repository: GetCompanyRevision ID [%s] revision [%d] error: %s", id, revision, err.Error())
+		return models.CompanyRevision{}, err
+	}
+
+	logger.Debugf("found revision [%d] for ID: [%s]", revision, id)
+	return companyRevision, nil
+}
+
 func (r repository) CheckCompanyExistsByName(c *gin.Context, name string) (bool, error) {
 
 	logger := logging.GetLogger(c).
@@ -136,24 +601,411 @@ func (r repository) CheckCompanyExistsByID(c *gin.Context, id string) (bool, err
 	return exists, nil
 }
 
-func (r repository) UpdateCompany(c *gin.Context, updateFields map[string]interface{}, id string) error {
+// UpdateCompany applies the changes to a company. When versions is not nil, the company is
+// only updated at one of these versions, otherwise ErrVersionMismatch is returned.
+func (r repository) UpdateCompany(c *gin.Context, updateFields map[string]interface{}, id string, versions []int) error {
 
 	logger := logging.GetLogger(c).
 		WithField(constants.ReqID, requestid.Get(c)).
 		WithField(constants.Interface, "Repository").
 		WithField(constants.Method, "PatchCompany")
 
-	sql, args := buildUpdateSql(c, id, updateFields)
-	_, err := r.db.DB.Exec(sql, args...)
+	query, args := buildUpdateSql(c, id, updateFields)
+	query, args = withVersions(query, args, versions)
+	result, err := r.execAsActor(c, query, args...)
 	if err != nil {
 		logger.Errorf("repository: PatchCompany ID [%s] error: %s", id, err.Error())
-		return err
+		return translateWriteError(err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 && versions != nil {
+		return ErrVersionMismatch
+	}
+	// the company was deleted since the caller checked it exists
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
 	}
 
 	logger.Debugf("updated company with ID: [%s]", id)
 	return nil
 }
 
+// UpdateCompanies applies the same changes to every selected company. It is a single
+// statement, so either all the selected companies are updated or none is.
+func (r repository) UpdateCompanies(c *gin.Context, selector models.CompanySelector, updateFields map[string]interface{}) (int64, error) {
+
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Repository").
+		WithField(constants.Method, "UpdateCompanies")
+
+	sql, args := buildBulkUpdateSql(selector, updateFields)
+	result, err := r.execAsActor(c, sql, args...)
+	if err != nil {
+		logger.Errorf("repository: UpdateCompanies error: %s", err.Error())
+		return 0, translateWriteError(err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	logger.Debugf("updated %d companies", rowsAffected)
+	return rowsAffected, nil
+}
+
+// DeleteCompanies deletes every selected company in a single statement.
+func (r repository) DeleteCompanies(c *gin.Context, selector models.CompanySelector) (int64, error) {
+
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Repository").
+		WithField(constants.Method, "DeleteCompanies")
+
+	conditions, args := buildSelectorConditions(selector, nil)
+	result, err := r.execAsActor(c, deleteCompanies+whereClause(conditions), args...)
+	if err != nil {
+		logger.Errorf("repository: DeleteCompanies error: %s", err.Error())
+		return 0, translateWriteError(err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	logger.Debugf("deleted %d companies", rowsAffected)
+	return rowsAffected, nil
+}
+
+func (r repository) ListCompanies(c *gin.Context, query models.CompanyListQuery) (models.CompanyPage, error) {
+
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Repository").
+		WithField(constants.Method, "ListCompanies")
+
+	page := models.CompanyPage{Data: []models.Company{}}
+
+	countSql, countArgs := buildCountSql(query.Filter, nil)
+	err := r.db.GetContext(c.Request.Context(), &page.TotalCount, countSql, countArgs...)
+	if err != nil {
+		logger.Errorf("repository: ListCompanies count error: %s", err.Error())
+		return models.CompanyPage{}, err
+	}
+
+	listSql, listArgs := buildListSql(query)
+	err = r.db.SelectContext(c.Request.Context(), &page.Data, listSql, listArgs...)
+	if err != nil {
+		logger.Errorf("repository: ListCompanies error: %s", err.Error())
+		return models.CompanyPage{}, err
+	}
+
+	// one extra row is fetched to know whether there is a next page
+	if len(page.Data) > query.Limit {
+		page.Data = page.Data[:query.Limit]
+		page.NextCursor, err = nextCursor(query, page.Data[len(page.Data)-1])
+		if err != nil {
+			logger.Errorf("repository: ListCompanies cursor error: %s", err.Error())
+			return models.CompanyPage{}, err
+		}
+	}
+
+	logger.Debugf("listed %d of %d companies", len(page.Data), page.TotalCount)
+	return page, nil
+}
+
+// GetCompanyAsOf returns a company as it was at asOf, read from its latest revision made
+// up to then. It returns sql.ErrNoRows when the company did not exist or was deleted at asOf.
+func (r repository) GetCompanyAsOf(c *gin.Context, id string, asOf time.Time) (models.CompanyVersion, error) {
+
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Repository").
+		WithField(constants.Method, "GetCompanyAsOf")
+
+	var company models.CompanyVersion
+	err := r.db.GetContext(c.Request.Context(), &company, fmt.Sprintf(companiesAsOf, 2)+getCompanyVersion, id, asOf)
+
+	switch {
+	case err == sql.ErrNoRows:
+		logger.Errorf("no rows found for ID: [%s] as of [%s]", id, asOf)
+		return models.CompanyVersion{}, err
+	case err != nil:
+		logger.Errorf("repository: GetCompanyAsOf ID [%s] error: %s", id, err.Error())
+		return models.CompanyVersion{}, err
+	}
+
+	logger.Debugf("found revision [%d] for ID: [%s] as of [%s]", company.Revision, id, asOf)
+	return company, nil
+}
+
+// ListCompaniesAsOf lists the companies as they were at query.AsOf, with the same filters,
+// sorting and pagination as ListCompanies.
+func (r repository) ListCompaniesAsOf(c *gin.Context, query models.CompanyListQuery) (models.CompanyVersionPage, error) {
+
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Repository").
+		WithField(constants.Method, "ListCompaniesAsOf")
+
+	page := models.CompanyVersionPage{Data: []models.CompanyVersion{}, AsOf: *query.AsOf}
+
+	countSql, countArgs := buildCountSql(query.Filter, query.AsOf)
+	err := r.db.GetContext(c.Request.Context(), &page.TotalCount, countSql, countArgs...)
+	if err != nil {
+		logger.Errorf("repository: ListCompaniesAsOf count error: %s", err.Error())
+		return models.CompanyVersionPage{}, err
+	}
+
+	listSql, listArgs := buildListSql(query)
+	err = r.db.SelectContext(c.Request.Context(), &page.Data, listSql, listArgs...)
+	if err != nil {
+		logger.Errorf("repository: ListCompaniesAsOf error: %s", err.Error())
+		return models.CompanyVersionPage{}, err
+	}
+
+	if len(page.Data) > query.Limit {
+		page.Data = page.Data[:query.Limit]
+		page.NextCursor, err = nextCursor(query, page.Data[len(page.Data)-1].Company)
+		if err != nil {
+			logger.Errorf("repository: ListCompaniesAsOf cursor error: %s", err.Error())
+			return models.CompanyVersionPage{}, err
+		}
+	}
+
+	logger.Debugf("listed %d of %d companies as of [%s]", len(page.Data), page.TotalCount, page.AsOf)
+	return page, nil
+}
+
+// ExportCompanies calls fn for every company matching the query, in sort order.
+// Rows are scanned one at a time as the database returns them.
+func (r repository) ExportCompanies(c *gin.Context, query models.CompanyListQuery, fn func(models.Company) error) error {
+
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Repository").
+		WithField(constants.Method, "ExportCompanies")
+
+	query.Limit, query.After = 0, nil
+	exportSql, exportArgs := buildListSql(query)
+	rows, err := r.db.QueryxContext(c.Request.Context(), exportSql, exportArgs...)
+	if err != nil {
+		logger.Errorf("repository: ExportCompanies error: %s", err.Error())
+		return err
+	}
+	defer rows.Close()
+
+	exported := 0
+	for rows.Next() {
+		var company models.Company
+		if err = rows.StructScan(&company); err != nil {
+			logger.Errorf("repository: ExportCompanies scan error: %s", err.Error())
+			return err
+		}
+		if err = fn(company); err != nil {
+			return err
+		}
+		exported++
+	}
+	if err = rows.Err(); err != nil {
+		logger.Errorf("repository: ExportCompanies rows error: %s", err.Error())
+		return err
+	}
+
+	logger.Debugf("exported %d companies", exported)
+	return nil
+}
+
+// GetCompanyStats counts the companies matching the query filter, by registration, by the
+// group_by column and by amount_of_employees bucket. The counts are read from one snapshot.
+func (r repository) GetCompanyStats(c *gin.Context, query models.CompanyStatsQuery) (models.CompanyStats, error) {
+
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Repository").
+		WithField(constants.Method, "GetCompanyStats")
+
+	tx, err := r.db.BeginTxx(c.Request.Context(), &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		logger.Errorf("repository: GetCompanyStats begin error: %s", err.Error())
+		return models.CompanyStats{}, err
+	}
+	defer tx.Rollback()
+
+	conditions, args := buildFilterConditions(query.Filter, nil)
+	where := whereClause(conditions)
+	stats := models.CompanyStats{GroupBy: query.GroupBy, Groups: []models.CompanyStatsGroup{}}
+
+	err = tx.QueryRowxContext(c.Request.Context(), countRegisteredCompanies+where, args...).Scan(&stats.TotalCount, &stats.RegisteredCount)
+	if err != nil {
+		logger.Errorf("repository: GetCompanyStats count error: %s", err.Error())
+		return models.CompanyStats{}, err
+	}
+
+	err = tx.SelectContext(c.Request.Context(), &stats.Groups, fmt.Sprintf(groupCompanies, query.GroupBy, where), args...)
+	if err != nil {
+		logger.Errorf("repository: GetCompanyStats group by [%s] error: %s", query.GroupBy, err.Error())
+		return models.CompanyStats{}, err
+	}
+
+	var bucketCounts []struct {
+		Bucket int `db:"bucket"`
+		Count  int `db:"count"`
+	}
+	bucketArgs := append(args, pq.Array(query.Buckets))
+	err = tx.SelectContext(c.Request.Context(), &bucketCounts, fmt.Sprintf(bucketCompanies, len(bucketArgs), where), bucketArgs...)
+	if err != nil {
+		logger.Errorf("repository: GetCompanyStats histogram error: %s", err.Error())
+		return models.CompanyStats{}, err
+	}
+	counts := map[int]int{}
+	for _, bucketCount := range bucketCounts {
+		counts[bucketCount.Bucket] = bucketCount.Count
+	}
+	stats.Histogram = histogramBuckets(query.Buckets, counts)
+
+	if err = tx.Commit(); err != nil {
+		logger.Errorf("repository: GetCompanyStats commit error: %s", err.Error())
+		return models.CompanyStats{}, err
+	}
+
+	logger.Debugf("computed stats of %d companies", stats.TotalCount)
+	return stats, nil
+}
+
+// ListCompanyAncestors returns the parent of a company, its parent and so on up to the
+// root of its group, nearest first.
+func (r repository) ListCompanyAncestors(c *gin.Context, id string) ([]models.CompanyNode, error) {
+
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Repository").
+		WithField(constants.Method, "ListCompanyAncestors")
+
+	ancestors := []models.CompanyNode{}
+	err := r.db.SelectContext(c.Request.Context(), &ancestors, listCompanyAncestors, id)
+	if err != nil {
+		logger.Errorf("repository: ListCompanyAncestors ID [%s] error: %s", id, err.Error())
+		return nil, err
+	}
+
+	logger.Debugf("found %d ancestors of company with ID: [%s]", len(ancestors), id)
+	return ancestors, nil
+}
+
+// ListCompanyChildren returns the direct subsidiaries of a company, ordered by name.
+func (r repository) ListCompanyChildren(c *gin.Context, id string, limit, offset int) ([]models.Company, error) {
+
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Repository").
+		WithField(constants.Method, "ListCompanyChildren")
+
+	children := []models.Company{}
+	err := r.db.SelectContext(c.Request.Context(), &children, listCompanyChildren, id, limit, offset)
+	if err != nil {
+		logger.Errorf("repository: ListCompanyChildren ID [%s] error: %s", id, err.Error())
+		return nil, err
+	}
+
+	logger.Debugf("found %d children of company with ID: [%s]", len(children), id)
+	return children, nil
+}
+
+// GetCompanySubtree returns a company at depth 0 followed by all its subsidiaries, level
+// by level. It returns sql.ErrNoRows when the company does not exist.
+func (r repository) GetCompanySubtree(c *gin.Context, id string) ([]models.CompanyNode, error) {
+
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Repository").
+		WithField(constants.Method, "GetCompanySubtree")
+
+	nodes := []models.CompanyNode{}
+	err := r.db.SelectContext(c.Request.Context(), &nodes, getCompanySubtree, id)
+	if err != nil {
+		logger.Errorf("repository: GetCompanySubtree ID [%s] error: %s", id, err.Error())
+		return nil, err
+	}
+	if len(nodes) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	logger.Debugf("found %d subsidiaries of company with ID: [%s]", len(nodes)-1, id)
+	return nodes, nil
+}
+
+func (r repository) SearchCompanies(c *gin.Context, text string, limit, offset int) ([]models.CompanySearchResult, error) {
+
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Repository").
+		WithField(constants.Method, "SearchCompanies")
+
+	results := []models.CompanySearchResult{}
+	err := r.db.SelectContext(c.Request.Context(), &results, searchCompanies, text, limit, offset)
+	if err != nil {
+		logger.Errorf("repository: SearchCompanies text [%s] error: %s", text, err.Error())
+		return nil, err
+	}
+
+	logger.Debugf("found %d companies for text: [%s]", len(results), text)
+	return results, nil
+}
+
+func (r repository) SuggestCompanies(c *gin.Context, prefix string, limit int) ([]models.CompanySuggestion, error) {
+
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Repository").
+		WithField(constants.Method, "SuggestCompanies")
+
+	suggestions := []models.CompanySuggestion{}
+	err := r.db.SelectContext(c.Request.Context(), &suggestions, suggestCompanies, prefix, escapeLike(prefix)+"%", limit)
+	if err != nil {
+		logger.Errorf("repository: SuggestCompanies prefix [%s] error: %s", prefix, err.Error())
+		return nil, err
+	}
+
+	logger.Debugf("found %d suggestions for prefix: [%s]", len(suggestions), prefix)
+	return suggestions, nil
+}
+
+// withActor runs fn in a transaction whose company revisions are attributed to the
+// authenticated user, and commits it unless fn fails.
+func (r repository) withActor(c *gin.Context, fn func(tx *sqlx.Tx) error) error {
+	tx, err := r.db.BeginTxx(c.Request.Context(), nil)
+	if err != nil {
+		return err
+	}
+	if err = setActor(c, tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err = fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// execAsActor runs a single company write with withActor.
+func (r repository) execAsActor(c *gin.Context, query string, args ...interface{}) (sql.Result, error) {
+	var result sql.Result
+	err := r.withActor(c, func(tx *sqlx.Tx) (err error) {
+		result, err = tx.ExecContext(c.Request.Context(), query, args...)
+		return err
+	})
+	return result, err
+}
+
+// setActor stores the authenticated user, and the revision being reverted to if any, in tx
+// for the record_company_revision trigger, which writes a company_revisions row for every
+// company insert or update.
+func setActor(c *gin.Context, tx *sqlx.Tx) error {
+	var revertedFrom string
+	if revision := c.GetInt(constants.RevertedFrom); revision > 0 {
+		revertedFrom = strconv.Itoa(revision)
+	}
+	_, err := tx.ExecContext(c.Request.Context(), setRevisionActor, c.GetString(constants.UserEmail), revertedFrom)
+	return err
+}
+
 func buildUpdateSql(c *gin.Context, id string, updateFields map[string]interface{}) (string, []interface{}) {
 	var (
 		setValues   []string
@@ -169,5 +1021,271 @@ func buildUpdateSql(c *gin.Context, id string, updateFields map[string]interface
 	args = append(args, id)
 	setClause := strings.Join(setValues, ", ")
 
-	return fmt.Sprintf(`UPDATE companies SET %s  WHERE id = $%d `, setClause, fieldsCount), args
+	return fmt.Sprintf(`UPDATE companies SET %s  WHERE id = $%d AND %s`, setClause, fieldsCount, notDeleted), args
+}
+
+func buildBulkUpdateSql(selector models.CompanySelector, updateFields map[string]interface{}) (string, []interface{}) {
+	fields := make([]string, 0, len(updateFields))
+	for field := range updateFields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	var (
+		setValues []string
+		args      []interface{}
+	)
+	for _, field := range fields {
+		args = append(args, updateFields[field])
+		setValues = append(setValues, fmt.Sprintf(`%s = $%d`, field, len(args)))
+	}
+
+	conditions, args := buildSelectorConditions(selector, args)
+	return fmt.Sprintf(`UPDATE companies SET %s%s`, strings.Join(setValues, ", "), whereClause(conditions)), args
+}
+
+// buildFilterConditions returns the WHERE conditions for the given filter, numbering
+// its placeholders after the already collected args. Soft deleted companies never match.
+func buildFilterConditions(filter models.CompanyFilter, args []interface{}) ([]string, []interface{}) {
+	conditions := []string{notDeleted}
+
+	if len(filter.Types) > 0 {
+		var placeholders []string
+		for _, companyType := range filter.Types {
+			args = append(args, companyType)
+			placeholders = append(placeholders, fmt.Sprintf(`$%d`, len(args)))
+		}
+		conditions = append(conditions, fmt.Sprintf(`type IN (%s)`, strings.Join(placeholders, ", ")))
+	}
+	if filter.Registered != nil {
+		args = append(args, *filter.Registered)
+		conditions = append(conditions, fmt.Sprintf(`registered = $%d`, len(args)))
+	}
+	if filter.MinAmountOfEmployees != nil {
+		args = append(args, *filter.MinAmountOfEmployees)
+		conditions = append(conditions, fmt.Sprintf(`amount_of_employees >= $%d`, len(args)))
+	}
+	if filter.MaxAmountOfEmployees != nil {
+		args = append(args, *filter.MaxAmountOfEmployees)
+		conditions = append(conditions, fmt.Sprintf(`amount_of_employees <= $%d`, len(args)))
+	}
+	if filter.MinCreatedAt != nil {
+		args = append(args, *filter.MinCreatedAt)
+		conditions = append(conditions, fmt.Sprintf(`created_at >= $%d`, len(args)))
+	}
+	if filter.MaxCreatedAt != nil {
+		args = append(args, *filter.MaxCreatedAt)
+		conditions = append(conditions, fmt.Sprintf(`created_at <= $%d`, len(args)))
+	}
+	if filter.MinUpdatedAt != nil {
+		args = append(args, *filter.MinUpdatedAt)
+		conditions = append(conditions, fmt.Sprintf(`updated_at >= $%d`, len(args)))
+	}
+	if filter.MaxUpdatedAt != nil {
+		args = append(args, *filter.MaxUpdatedAt)
+		conditions = append(conditions, fmt.Sprintf(`updated_at <= $%d`, len(args)))
+	}
+
+	return conditions, args
+}
+
+// buildSelectorConditions returns the WHERE conditions matching the selected companies.
+func buildSelectorConditions(selector models.CompanySelector, args []interface{}) ([]string, []interface{}) {
+	var conditions []string
+
+	if len(selector.IDs) > 0 {
+		var placeholders []string
+		for _, id := range selector.IDs {
+			args = append(args, id)
+			placeholders = append(placeholders, fmt.Sprintf(`$%d`, len(args)))
+		}
+		conditions = append(conditions, fmt.Sprintf(`id IN (%s)`, strings.Join(placeholders, ", ")))
+	}
+
+	filterConditions, args := buildFilterConditions(selector.Filter, args)
+	return append(conditions, filterConditions...), args
+}
+
+func whereClause(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conditions, " AND ")
+}
+
+func buildCountSql(filter models.CompanyFilter, asOf *time.Time) (string, []interface{}) {
+	conditions, args := buildFilterConditions(filter, nil)
+	return withAsOf(countCompanies+whereClause(conditions), args, asOf)
+}
+
+func buildListSql(query models.CompanyListQuery) (string, []interface{}) {
+	conditions, args := buildFilterConditions(query.Filter, nil)
+
+	direction, comparison := "ASC", ">"
+	if query.SortDesc {
+		direction, comparison = "DESC", "<"
+	}
+
+	if query.After != nil {
+		if query.SortBy == "id" {
+			args = append(args, query.After.ID)
+			conditions = append(conditions, fmt.Sprintf(`id %s $%d`, comparison, len(args)))
+		} else {
+			args = append(args, query.After.Value, query.After.ID)
+			conditions = append(conditions, fmt.Sprintf(`(%s, id) %s ($%d, $%d)`, query.SortBy, comparison, len(args)-1, len(args)))
+		}
+	}
+
+	orderBy := fmt.Sprintf(` ORDER BY %s %s`, query.SortBy, direction)
+	if query.SortBy != "id" {
+		orderBy += fmt.Sprintf(`, id %s`, direction)
+	}
+
+	// the cursor of the next page is read from the id and the sort column
+	listSql := fmt.Sprintf(listCompanies, selectColumns(query.Fields, "id", query.SortBy))
+	if query.AsOf != nil {
+		listSql = fmt.Sprintf(listCompanyVersions, selectColumns(query.Fields, "id", query.SortBy))
+	}
+	listSql += whereClause(conditions) + orderBy
+	// an export reads every matching row
+	if query.Limit > 0 {
+		args = append(args, query.Limit+1)
+		listSql = fmt.Sprintf(`%s LIMIT $%d`, listSql, len(args))
+	}
+	return withAsOf(listSql, args, query.AsOf)
+}
+
+// selectColumns is the select list of the given company fields and the required ones, in
+// the order of companyColumns. Every column is selected when fields is empty.
+func selectColumns(fields []string, required ...string) string {
+	if len(fields) == 0 {
+		return companyColumns
+	}
+
+	wanted := map[string]bool{}
+	for _, field := range fields {
+		wanted[field] = true
+	}
+	for _, field := range required {
+		wanted[field] = true
+	}
+
+	var columns []string
+	for _, column := range strings.Split(companyColumns, ",") {
+		if wanted[column] {
+			columns = append(columns, column)
+		}
+	}
+	return strings.Join(columns, ",")
+}
+
+// withVersions restricts a write to the given company versions, unless versions is nil.
+// An empty, non-nil versions never matches.
+func withVersions(query string, args []interface{}, versions []int) (string, []interface{}) {
+	if versions == nil {
+		return query, args
+	}
+	args = append(args, pq.Array(versions))
+	return fmt.Sprintf(`%s AND version = ANY($%d)`, query, len(args)), args
+}
+
+// withAsOf runs the query against the companies as they were at asOf, see companiesAsOf.
+func withAsOf(query string, args []interface{}, asOf *time.Time) (string, []interface{}) {
+	if asOf == nil {
+		return query, args
+	}
+	args = append(args, *asOf)
+	return fmt.Sprintf(companiesAsOf, len(args)) + query, args
+}
+
+// nextCursor is the cursor of the page following the one ending with last.
+func nextCursor(query models.CompanyListQuery, last models.Company) (string, error) {
+	return utils.EncodeCursor(models.Cursor{
+		Sort:  sortParam(query),
+		Value: sortValue(last, query.SortBy),
+		ID:    last.ID,
+	})
+}
+
+// sortParam is the sort query parameter a list query was built from.
+func sortParam(query models.CompanyListQuery) string {
+	if query.SortDesc {
+		return "-" + query.SortBy
+	}
+	return query.SortBy
+}
+
+func sortValue(company models.Company, column string) interface{} {
+	switch column {
+	case "name":
+		return company.Name
+	case "description":
+		return company.Description
+	case "amount_of_employees":
+		return company.AmountOfEmployees
+	case "registered":
+		return company.Registered
+	case "type":
+		return company.Type
+	case "created_at":
+		return company.CreatedAt
+	case "updated_at":
+		return company.UpdatedAt
+	}
+	return company.ID
+}
+
+// histogramBuckets turns counts keyed by width_bucket index into the buckets starting at
+// the given lower bounds. Index 0 counts the companies below the first bound, its bucket
+// is only listed when it is not empty.
+func histogramBuckets(bounds []int, counts map[int]int) []models.EmployeeBucket {
+	buckets := []models.EmployeeBucket{}
+	if len(bounds) == 0 {
+		return buckets
+	}
+	if counts[0] > 0 {
+		buckets = append(buckets, models.EmployeeBucket{Max: &bounds[0], Count: counts[0]})
+	}
+	for i := range bounds {
+		bucket := models.EmployeeBucket{Min: &bounds[i], Count: counts[i+1]}
+		if i+1 < len(bounds) {
+			bucket.Max = &bounds[i+1]
+		}
+		buckets = append(buckets, bucket)
+	}
+	return buckets
+}
+
+// escapeLike escapes the LIKE wildcards of a user supplied pattern.
+func escapeLike(pattern string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(pattern)
+}
+
+// translateWriteError maps unique violations to ErrCompanyNameExists or ErrCompanyIDExists,
+// and the violations of the company hierarchy checked by the companies_check_hierarchy
+// trigger to ErrParentNotFound, ErrHierarchyCycle or ErrCompanyHasSubsidiaries.
+func translateWriteError(err error) error {
+	pqErr, ok := err.(*pq.Error)
+	if !ok {
+		return err
+	}
+	switch pqErr.Code {
+	case "23505":
+		if strings.Contains(pqErr.Constraint, "name") {
+			return ErrCompanyNameExists
+		}
+		return ErrCompanyIDExists
+	case "23503":
+		// the foreign keys of contacts, locations and shareholdings are not about the parent
+		if pqErr.Constraint == "companies_parent_id_fkey" {
+			return ErrParentNotFound
+		}
+	case "23514":
+		if pqErr.Constraint == "companies_parent_cycle" {
+			return ErrHierarchyCycle
+		}
+	case "23001":
+		return ErrCompanyHasSubsidiaries
+	}
+	return err
 }
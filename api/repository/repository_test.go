@@ -1,35 +1,47 @@
 package repository
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"net/http"
 	"net/http/httptest"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
 	"github.com/jmoiron/sqlx"
+	"github.com/kumareswaramoorthi/companies/api/constants"
 	"github.com/kumareswaramoorthi/companies/api/models"
+	"github.com/kumareswaramoorthi/companies/api/utils"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/suite"
 )
 
 const (
-	TestGetCompany               = `SELECT * FROM companies WHERE id  = $1`
-	TestInsertCompany            = `INSERT INTO companies (id,name,description,amount_of_employees,registered,type) VALUES ($1,$2,$3,$4,$5,$6)`
-	TestDeleteCompany            = `DELETE  FROM companies WHERE id  = $1`
-	TestcheckCompanyExistsByName = `SELECT EXISTS(SELECT 1 FROM companies where name = $1)`
-	TestcheckCompanyExistsByID   = `SELECT EXISTS(SELECT 1 FROM companies where id = $1)`
+	TestGetCompany               = `SELECT id,name,description,amount_of_employees,registered,type,version,created_at,updated_at,parent_id FROM companies WHERE id  = $1 AND deleted_at IS NULL`
+	TestInsertCompany            = `INSERT INTO companies (id,name,description,amount_of_employees,registered,type,parent_id) VALUES ($1,$2,$3,$4,$5,$6,$7)`
+	TestDeleteCompany            = `UPDATE companies SET deleted_at = now() WHERE id  = $1 AND deleted_at IS NULL`
+	TestcheckCompanyExistsByName = `SELECT EXISTS(SELECT 1 FROM companies where name = $1 AND deleted_at IS NULL)`
+	TestcheckCompanyExistsByID   = `SELECT EXISTS(SELECT 1 FROM companies where id = $1 AND deleted_at IS NULL)`
+	TestSetRevisionActor         = `SELECT set_config('companies.actor', $1, true), set_config('companies.reverted_from', $2, true)`
+	TestActor                    = "admin@company.com"
 )
 
 type RepositoryTestSuite struct {
 	suite.Suite
-	mockCtrl   *gomock.Controller
-	sqlMock    sqlmock.Sqlmock
-	repository Repository
-	context    *gin.Context
-	recorder   *httptest.ResponseRecorder
+	mockCtrl              *gomock.Controller
+	sqlMock               sqlmock.Sqlmock
+	repository            Repository
+	idempotencyRepository IdempotencyRepository
+	shareholdingRepo      ShareholdingRepository
+	locationRepo          LocationRepository
+	contactRepo           ContactRepository
+	context               *gin.Context
+	recorder              *httptest.ResponseRecorder
 }
 
 func TestRepositoryTestSuite(t *testing.T) {
@@ -43,8 +55,25 @@ func (suite *RepositoryTestSuite) SetupTest() {
 	suite.recorder = httptest.NewRecorder()
 	suite.context, _ = gin.CreateTestContext(suite.recorder)
 	suite.context.Request, _ = http.NewRequest("GET", "", nil)
+	suite.context.Set(constants.UserEmail, TestActor)
 	suite.sqlMock = mock
 	suite.repository = NewRepository(sqlxDB)
+	suite.idempotencyRepository = NewIdempotencyRepository(sqlxDB)
+	suite.shareholdingRepo = NewShareholdingRepository(sqlxDB)
+	suite.locationRepo = NewLocationRepository(sqlxDB)
+	suite.contactRepo = NewContactRepository(sqlxDB)
+}
+
+// expectActor expects the transaction a company write runs in to be opened and
+// attributed to the authenticated user.
+func (suite *RepositoryTestSuite) expectActor() {
+	suite.sqlMock.ExpectBegin()
+	suite.expectActorSet()
+}
+
+func (suite *RepositoryTestSuite) expectActorSet() {
+	suite.sqlMock.ExpectExec(regexp.QuoteMeta(TestSetRevisionActor)).
+		WithArgs(TestActor, "").WillReturnResult(sqlmock.NewResult(0, 1))
 }
 
 func (suite *RepositoryTestSuite) TearDownTest() {
@@ -93,6 +122,42 @@ func (suite *RepositoryTestSuite) TestGetCompanyShouldFailWhenDatabaseQueryFails
 	suite.Equal(dbErr, err)
 }
 
+func (suite *RepositoryTestSuite) TestGetCompanyFieldsSelectsOnlyRequestedColumns() {
+	id := "041d2027-e6fa-4d6d-836d-eedb235c82bc"
+	suite.sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT id,name,type,version,updated_at FROM companies WHERE id  = $1 AND deleted_at IS NULL`)).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "type", "version", "updated_at"}).
+			AddRow(id, "xyz", "Corporations", 2, time.Now()))
+
+	company, err := suite.repository.GetCompanyFields(suite.context, id, []string{"type", "name"})
+	suite.Nil(err)
+	suite.Equal("xyz", company.Name)
+	suite.Empty(company.Description)
+	suite.Equal(2, company.Version)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestGetCompanyByNameSuccess() {
+	id := "041d2027-e6fa-4d6d-836d-eedb235c82bc"
+	rows := sqlmock.NewRows([]string{"id", "name", "description", "amount_of_employees", "registered", "type"}).
+		AddRow(id, "Xyz", "test company", 100, true, "Corporations")
+	suite.sqlMock.ExpectQuery(regexp.QuoteMeta(`WHERE lower(name) = lower($1)`)).
+		WithArgs("xyz").WillReturnRows(rows)
+
+	company, err := suite.repository.GetCompanyByName(suite.context, "xyz")
+	suite.Nil(err)
+	suite.Equal("Xyz", company.Name)
+	suite.Equal(id, company.ID)
+}
+
+func (suite *RepositoryTestSuite) TestGetCompanyByNameShouldFailWhenNoRows() {
+	suite.sqlMock.ExpectQuery(regexp.QuoteMeta(`WHERE lower(name) = lower($1)`)).
+		WithArgs("xyz").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err := suite.repository.GetCompanyByName(suite.context, "xyz")
+	suite.Equal(sql.ErrNoRows, err)
+}
+
 func (suite *RepositoryTestSuite) TestCreateCompanySuccess() {
 	inputdetails := models.Company{
 		ID:                "041d2027-e6fa-4d6d-836d-eedb235c82bc",
@@ -102,8 +167,10 @@ func (suite *RepositoryTestSuite) TestCreateCompanySuccess() {
 		Registered:        true,
 		Type:              "Corporations"}
 
+	suite.expectActor()
 	suite.sqlMock.ExpectExec(regexp.QuoteMeta(TestInsertCompany)).
-		WithArgs(inputdetails.ID, inputdetails.Name, inputdetails.Description, inputdetails.AmountOfEmployees, inputdetails.Registered, inputdetails.Type).WillReturnResult(sqlmock.NewResult(1, 1))
+		WithArgs(inputdetails.ID, inputdetails.Name, inputdetails.Description, inputdetails.AmountOfEmployees, inputdetails.Registered, inputdetails.Type, inputdetails.ParentID).WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.sqlMock.ExpectCommit()
 	if err := suite.sqlMock.ExpectationsWereMet(); err != nil {
 		suite.Error(errors.New("there were unfulfilled expectations"), err)
 	}
@@ -122,8 +189,10 @@ func (suite *RepositoryTestSuite) TestCreateCompanyShouldFailWhenDatabaseQueryFa
 		Type:              "Corporations"}
 
 	dbErr := errors.New("ID invalid identifier")
+	suite.expectActor()
 	suite.sqlMock.ExpectExec(regexp.QuoteMeta(TestInsertCompany)).
-		WithArgs(inputdetails.ID, inputdetails.Name, inputdetails.Description, inputdetails.AmountOfEmployees, inputdetails.Registered, inputdetails.Type).WillReturnError(dbErr)
+		WithArgs(inputdetails.ID, inputdetails.Name, inputdetails.Description, inputdetails.AmountOfEmployees, inputdetails.Registered, inputdetails.Type, inputdetails.ParentID).WillReturnError(dbErr)
+	suite.sqlMock.ExpectRollback()
 	if err := suite.sqlMock.ExpectationsWereMet(); err != nil {
 		suite.Error(errors.New("there were unfulfilled expectations"), err)
 	}
@@ -131,14 +200,129 @@ func (suite *RepositoryTestSuite) TestCreateCompanyShouldFailWhenDatabaseQueryFa
 	suite.Equal(dbErr, err)
 }
 
+func (suite *RepositoryTestSuite) TestCreateCompaniesAtomicSuccess() {
+	companies := []models.Company{
+		{ID: "041d2027-e6fa-4d6d-836d-eedb235c82bc", Name: "abc", AmountOfEmployees: 10, Registered: true, Type: "Corporations"},
+		{ID: "9b2e5a4c-1f3d-4e8a-9c7b-2d6f8e0a1b3c", Name: "xyz", AmountOfEmployees: 20, Registered: true, Type: "NonProfit"},
+	}
+	suite.sqlMock.ExpectBegin()
+	suite.expectActorSet()
+	for _, company := range companies {
+		suite.sqlMock.ExpectExec(regexp.QuoteMeta(TestInsertCompany)).
+			WithArgs(company.ID, company.Name, company.Description, company.AmountOfEmployees, company.Registered, company.Type, company.ParentID).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+	}
+	suite.sqlMock.ExpectCommit()
+
+	itemErrs, err := suite.repository.CreateCompanies(suite.context, companies, true)
+	suite.Nil(err)
+	suite.Equal([]error{nil, nil}, itemErrs)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestCreateCompaniesAtomicRollsBackOnFailure() {
+	companies := []models.Company{
+		{ID: "041d2027-e6fa-4d6d-836d-eedb235c82bc", Name: "abc", AmountOfEmployees: 10, Registered: true, Type: "Corporations"},
+		{ID: "9b2e5a4c-1f3d-4e8a-9c7b-2d6f8e0a1b3c", Name: "abc", AmountOfEmployees: 20, Registered: true, Type: "NonProfit"},
+	}
+	suite.sqlMock.ExpectBegin()
+	suite.expectActorSet()
+	suite.sqlMock.ExpectExec(regexp.QuoteMeta(TestInsertCompany)).WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.sqlMock.ExpectExec(regexp.QuoteMeta(TestInsertCompany)).
+		WillReturnError(&pq.Error{Code: "23505", Constraint: "companies_name_key"})
+	suite.sqlMock.ExpectRollback()
+
+	itemErrs, err := suite.repository.CreateCompanies(suite.context, companies, true)
+	suite.Nil(err)
+	suite.Equal([]error{nil, ErrCompanyNameExists}, itemErrs)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestCreateCompaniesPartialKeepsSucceededItems() {
+	companies := []models.Company{
+		{ID: "041d2027-e6fa-4d6d-836d-eedb235c82bc", Name: "abc", AmountOfEmployees: 10, Registered: true, Type: "Corporations"},
+		{ID: "9b2e5a4c-1f3d-4e8a-9c7b-2d6f8e0a1b3c", Name: "xyz", AmountOfEmployees: 20, Registered: true, Type: "NonProfit"},
+	}
+	suite.sqlMock.ExpectBegin()
+	suite.expectActorSet()
+	suite.sqlMock.ExpectExec(regexp.QuoteMeta(`SAVEPOINT bulk_item`)).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.sqlMock.ExpectExec(regexp.QuoteMeta(TestInsertCompany)).
+		WillReturnError(&pq.Error{Code: "23505", Constraint: "companies_pkey"})
+	suite.sqlMock.ExpectExec(regexp.QuoteMeta(`ROLLBACK TO SAVEPOINT bulk_item`)).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.sqlMock.ExpectExec(regexp.QuoteMeta(`SAVEPOINT bulk_item`)).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.sqlMock.ExpectExec(regexp.QuoteMeta(TestInsertCompany)).WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.sqlMock.ExpectExec(regexp.QuoteMeta(`RELEASE SAVEPOINT bulk_item`)).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.sqlMock.ExpectCommit()
+
+	itemErrs, err := suite.repository.CreateCompanies(suite.context, companies, false)
+	suite.Nil(err)
+	suite.Equal([]error{ErrCompanyIDExists, nil}, itemErrs)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestUpsertCompanySkipsConflict() {
+	company := models.Company{ID: "041d2027-e6fa-4d6d-836d-eedb235c82bc", Name: "abc", AmountOfEmployees: 10, Registered: true, Type: "Corporations"}
+	suite.expectActor()
+	suite.sqlMock.ExpectExec(regexp.QuoteMeta(TestInsertCompany+` ON CONFLICT DO NOTHING`)).
+		WithArgs(company.ID, company.Name, company.Description, company.AmountOfEmployees, company.Registered, company.Type, company.ParentID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.sqlMock.ExpectCommit()
+
+	status, err := suite.repository.UpsertCompany(suite.context, company, models.ImportConflictSkip)
+	suite.Nil(err)
+	suite.Equal(models.BulkItemSkipped, status)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestUpsertCompanyOverwritesExisting() {
+	company := models.Company{ID: "041d2027-e6fa-4d6d-836d-eedb235c82bc", Name: "abc", AmountOfEmployees: 10, Registered: true, Type: "Corporations"}
+	suite.expectActor()
+	suite.sqlMock.ExpectQuery(regexp.QuoteMeta(TestInsertCompany+` ON CONFLICT (id) DO UPDATE SET`)).
+		WithArgs(company.ID, company.Name, company.Description, company.AmountOfEmployees, company.Registered, company.Type, company.ParentID).
+		WillReturnRows(sqlmock.NewRows([]string{"inserted"}).AddRow(false))
+	suite.sqlMock.ExpectCommit()
+
+	status, err := suite.repository.UpsertCompany(suite.context, company, models.ImportConflictOverwrite)
+	suite.Nil(err)
+	suite.Equal(models.BulkItemUpdated, status)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestUpsertCompanyDoesNotOverwriteTrashedCompany() {
+	company := models.Company{ID: "041d2027-e6fa-4d6d-836d-eedb235c82bc", Name: "abc", AmountOfEmployees: 10, Registered: true, Type: "Corporations"}
+	suite.expectActor()
+	suite.sqlMock.ExpectQuery(regexp.QuoteMeta(`WHERE companies.deleted_at IS NULL RETURNING (xmax = 0) AS inserted`)).
+		WithArgs(company.ID, company.Name, company.Description, company.AmountOfEmployees, company.Registered, company.Type, company.ParentID).
+		WillReturnRows(sqlmock.NewRows([]string{"inserted"}))
+	suite.sqlMock.ExpectRollback()
+
+	_, err := suite.repository.UpsertCompany(suite.context, company, models.ImportConflictOverwrite)
+	suite.Equal(ErrCompanyInTrash, err)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestUpsertCompanyFailsOnConflict() {
+	company := models.Company{ID: "041d2027-e6fa-4d6d-836d-eedb235c82bc", Name: "abc", AmountOfEmployees: 10, Registered: true, Type: "Corporations"}
+	suite.expectActor()
+	suite.sqlMock.ExpectExec(regexp.QuoteMeta(TestInsertCompany)).
+		WillReturnError(&pq.Error{Code: "23505", Constraint: "companies_pkey"})
+	suite.sqlMock.ExpectRollback()
+
+	_, err := suite.repository.UpsertCompany(suite.context, company, models.ImportConflictFail)
+	suite.Equal(ErrCompanyIDExists, err)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
 func (suite *RepositoryTestSuite) TestDeleteCompanySuccess() {
 	id := "041d2027-e6fa-4d6d-836d-eedb235c82bc"
+	suite.expectActor()
 	suite.sqlMock.ExpectExec(regexp.QuoteMeta(TestDeleteCompany)).
 		WithArgs(id).WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.sqlMock.ExpectCommit()
 	if err := suite.sqlMock.ExpectationsWereMet(); err != nil {
 		suite.Error(errors.New("there were unfulfilled expectations"), err)
 	}
-	err := suite.repository.DeleteCompany(suite.context, id)
+	err := suite.repository.DeleteCompany(suite.context, id, nil, false)
 	suite.Nil(err)
 }
 
@@ -175,13 +359,726 @@ func (suite *RepositoryTestSuite) TestCheckCompanyExistsByIDSuccess() {
 func (suite *RepositoryTestSuite) TestUpdateCompanySuccess() {
 	id := "041d2027-e6fa-4d6d-836d-eedb235c82bc"
 	name := "xyz"
+	suite.expectActor()
 	suite.sqlMock.ExpectExec(regexp.QuoteMeta(`UPDATE companies SET  name = $1   WHERE id = $2 `)).
 		WithArgs(name, id).WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.sqlMock.ExpectCommit()
 	if err := suite.sqlMock.ExpectationsWereMet(); err != nil {
 		suite.Error(errors.New("there were unfulfilled expectations"), err)
 	}
 	req := make(map[string]interface{})
 	req["name"] = name
-	err := suite.repository.UpdateCompany(suite.context, req, id)
+	err := suite.repository.UpdateCompany(suite.context, req, id, nil)
+	suite.Nil(err)
+}
+
+func (suite *RepositoryTestSuite) TestListCompaniesSuccess() {
+	rows := sqlmock.NewRows([]string{"id", "name", "description", "amount_of_employees", "registered", "type"}).
+		AddRow("041d2027-e6fa-4d6d-836d-eedb235c82bc", "abc", "test company", 100, true, "Corporations").
+		AddRow("9b2e5a4c-1f3d-4e8a-9c7b-2d6f8e0a1b3c", "xyz", "test company", 10, true, "Corporations")
+	suite.sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM companies WHERE deleted_at IS NULL AND type IN ($1)`)).
+		WithArgs("Corporations").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	suite.sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT id,name,description,amount_of_employees,registered,type,version,created_at,updated_at,parent_id FROM companies WHERE deleted_at IS NULL AND type IN ($1) ORDER BY name ASC, id ASC LIMIT $2`)).
+		WithArgs("Corporations", 2).WillReturnRows(rows)
+
+	query := models.CompanyListQuery{
+		Filter: models.CompanyFilter{Types: []string{"Corporations"}},
+		SortBy: "name",
+		Limit:  1,
+	}
+	page, err := suite.repository.ListCompanies(suite.context, query)
+	suite.Nil(err)
+	suite.Equal(2, page.TotalCount)
+	suite.Len(page.Data, 1)
+	suite.Equal("abc", page.Data[0].Name)
+	suite.NotEmpty(page.NextCursor)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestExportCompaniesStreamsEveryRow() {
+	rows := sqlmock.NewRows([]string{"id", "name", "description", "amount_of_employees", "registered", "type"}).
+		AddRow("9b2e5a4c-1f3d-4e8a-9c7b-2d6f8e0a1b3c", "xyz", "test company", 10, true, "Corporations").
+		AddRow("041d2027-e6fa-4d6d-836d-eedb235c82bc", "abc", "test company", 100, true, "Corporations")
+	suite.sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT id,name,description,amount_of_employees,registered,type,version,created_at,updated_at,parent_id FROM companies WHERE deleted_at IS NULL AND type IN ($1) ORDER BY name DESC, id DESC`)).
+		WithArgs("Corporations").WillReturnRows(rows)
+
+	query := models.CompanyListQuery{
+		Filter:   models.CompanyFilter{Types: []string{"Corporations"}},
+		SortBy:   "name",
+		SortDesc: true,
+	}
+	var names []string
+	err := suite.repository.ExportCompanies(suite.context, query, func(company models.Company) error {
+		names = append(names, company.Name)
+		return nil
+	})
+	suite.Nil(err)
+	suite.Equal([]string{"xyz", "abc"}, names)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestListCompaniesAfterCursor() {
+	registered := true
+	suite.sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM companies WHERE deleted_at IS NULL AND registered = $1`)).
+		WithArgs(registered).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	suite.sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT id,name,description,amount_of_employees,registered,type,version,created_at,updated_at,parent_id FROM companies WHERE deleted_at IS NULL AND registered = $1 AND (amount_of_employees, id) < ($2, $3) ORDER BY amount_of_employees DESC, id DESC LIMIT $4`)).
+		WithArgs(registered, 100, "041d2027-e6fa-4d6d-836d-eedb235c82bc", 21).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "amount_of_employees", "registered", "type"}))
+
+	query := models.CompanyListQuery{
+		Filter:   models.CompanyFilter{Registered: &registered},
+		SortBy:   "amount_of_employees",
+		SortDesc: true,
+		Limit:    20,
+		After:    &models.Cursor{Sort: "-amount_of_employees", Value: 100, ID: "041d2027-e6fa-4d6d-836d-eedb235c82bc"},
+	}
+	page, err := suite.repository.ListCompanies(suite.context, query)
+	suite.Nil(err)
+	suite.Empty(page.Data)
+	suite.Empty(page.NextCursor)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestListCompaniesByTimestamps() {
+	createdSince := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	updatedAt := time.Date(2023, 2, 1, 12, 0, 0, 0, time.UTC)
+	suite.sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM companies WHERE deleted_at IS NULL AND created_at >= $1`)).
+		WithArgs(createdSince).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	suite.sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT id,name,description,amount_of_employees,registered,type,version,created_at,updated_at,parent_id FROM companies WHERE deleted_at IS NULL AND created_at >= $1 ORDER BY updated_at DESC, id DESC LIMIT $2`)).
+		WithArgs(createdSince, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "updated_at"}).
+			AddRow("041d2027-e6fa-4d6d-836d-eedb235c82bc", "abc", updatedAt).
+			AddRow("9b2e5a4c-1f3d-4e8a-9c7b-2d6f8e0a1b3c", "xyz", createdSince))
+
+	query := models.CompanyListQuery{
+		Filter:   models.CompanyFilter{MinCreatedAt: &createdSince},
+		SortBy:   "updated_at",
+		SortDesc: true,
+		Limit:    1,
+	}
+	page, err := suite.repository.ListCompanies(suite.context, query)
+	suite.Nil(err)
+	suite.Len(page.Data, 1)
+	cursor, err := utils.DecodeCursor(page.NextCursor)
+	suite.Nil(err)
+	suite.Equal("-updated_at", cursor.Sort)
+	suite.Equal(updatedAt.Format(time.RFC3339Nano), cursor.Value)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestListCompaniesSelectsRequestedFields() {
+	suite.sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM companies WHERE deleted_at IS NULL`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	suite.sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT id,name,amount_of_employees FROM companies WHERE deleted_at IS NULL ORDER BY amount_of_employees ASC, id ASC LIMIT $1`)).
+		WithArgs(21).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "amount_of_employees"}).
+			AddRow("041d2027-e6fa-4d6d-836d-eedb235c82bc", "abc", 100))
+
+	query := models.CompanyListQuery{SortBy: "amount_of_employees", Limit: 20, Fields: []string{"name"}}
+	page, err := suite.repository.ListCompanies(suite.context, query)
+	suite.Nil(err)
+	suite.Len(page.Data, 1)
+	suite.Equal("abc", page.Data[0].Name)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestListCompaniesShouldFailWhenCountFails() {
+	dbErr := errors.New("connection refused")
+	suite.sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM companies`)).WillReturnError(dbErr)
+
+	_, err := suite.repository.ListCompanies(suite.context, models.CompanyListQuery{SortBy: "id", Limit: 20})
+	suite.Equal(dbErr, err)
+}
+
+func (suite *RepositoryTestSuite) TestSearchCompaniesSuccess() {
+	rows := sqlmock.NewRows([]string{"id", "name", "description", "amount_of_employees", "registered", "type", "rank", "name_highlight", "snippet"}).
+		AddRow("041d2027-e6fa-4d6d-836d-eedb235c82bc", "xyz", "solar panel maker", 100, true, "Corporations", 0.6, "xyz", "<b>solar</b> panel maker")
+	suite.sqlMock.ExpectQuery(regexp.QuoteMeta(`websearch_to_tsquery('english', $1)`)).
+		WithArgs("solar", 20, 0).WillReturnRows(rows)
+
+	results, err := suite.repository.SearchCompanies(suite.context, "solar", 20, 0)
+	suite.Nil(err)
+	suite.Len(results, 1)
+	suite.Equal("xyz", results[0].Name)
+	suite.Equal(0.6, results[0].Rank)
+	suite.Equal("<b>solar</b> panel maker", results[0].Snippet)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestSearchCompaniesShouldFailWhenDatabaseQueryFails() {
+	dbErr := errors.New("syntax error in tsquery")
+	suite.sqlMock.ExpectQuery(regexp.QuoteMeta(`websearch_to_tsquery('english', $1)`)).WillReturnError(dbErr)
+
+	_, err := suite.repository.SearchCompanies(suite.context, "solar", 20, 0)
+	suite.Equal(dbErr, err)
+}
+
+func (suite *RepositoryTestSuite) TestSuggestCompaniesSuccess() {
+	rows := sqlmock.NewRows([]string{"id", "name", "score"}).
+		AddRow("041d2027-e6fa-4d6d-836d-eedb235c82bc", "acme_1", 0.5)
+	suite.sqlMock.ExpectQuery(regexp.QuoteMeta(`WHERE (name ILIKE $2 OR name % $1) AND deleted_at IS NULL`)).
+		WithArgs("acme_", `acme\_%`, 10).WillReturnRows(rows)
+
+	suggestions, err := suite.repository.SuggestCompanies(suite.context, "acme_", 10)
+	suite.Nil(err)
+	suite.Equal([]models.CompanySuggestion{{ID: "041d2027-e6fa-4d6d-836d-eedb235c82bc", Name: "acme_1", Score: 0.5}}, suggestions)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestSuggestCompaniesShouldFailWhenDatabaseQueryFails() {
+	dbErr := errors.New("operator does not exist")
+	suite.sqlMock.ExpectQuery(regexp.QuoteMeta(`WHERE (name ILIKE $2 OR name % $1) AND deleted_at IS NULL`)).WillReturnError(dbErr)
+
+	_, err := suite.repository.SuggestCompanies(suite.context, "acme", 10)
+	suite.Equal(dbErr, err)
+}
+
+func (suite *RepositoryTestSuite) TestUpdateCompaniesSuccess() {
+	registered := true
+	selector := models.CompanySelector{
+		IDs:    []string{"041d2027-e6fa-4d6d-836d-eedb235c82bc"},
+		Filter: models.CompanyFilter{Registered: &registered},
+	}
+	suite.expectActor()
+	suite.sqlMock.ExpectExec(regexp.QuoteMeta(`UPDATE companies SET registered = $1, type = $2 WHERE id IN ($3) AND deleted_at IS NULL AND registered = $4`)).
+		WithArgs(false, "NonProfit", "041d2027-e6fa-4d6d-836d-eedb235c82bc", true).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.sqlMock.ExpectCommit()
+
+	rowsAffected, err := suite.repository.UpdateCompanies(suite.context, selector, map[string]interface{}{"type": "NonProfit", "registered": false})
+	suite.Nil(err)
+	suite.Equal(int64(1), rowsAffected)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestUpdateCompaniesShouldFailOnDuplicateName() {
+	selector := models.CompanySelector{Filter: models.CompanyFilter{Types: []string{"NonProfit"}}}
+	suite.expectActor()
+	suite.sqlMock.ExpectExec(regexp.QuoteMeta(`UPDATE companies SET name = $1 WHERE deleted_at IS NULL AND type IN ($2)`)).
+		WithArgs("xyz", "NonProfit").WillReturnError(&pq.Error{Code: "23505", Constraint: "companies_name_key"})
+	suite.sqlMock.ExpectRollback()
+
+	_, err := suite.repository.UpdateCompanies(suite.context, selector, map[string]interface{}{"name": "xyz"})
+	suite.Equal(ErrCompanyNameExists, err)
+}
+
+func (suite *RepositoryTestSuite) TestDeleteCompaniesSuccess() {
+	selector := models.CompanySelector{IDs: []string{"041d2027-e6fa-4d6d-836d-eedb235c82bc", "9b2e5a4c-1f3d-4e8a-9c7b-2d6f8e0a1b3c"}}
+	suite.expectActor()
+	suite.sqlMock.ExpectExec(regexp.QuoteMeta(`UPDATE companies SET deleted_at = now() WHERE id IN ($1, $2) AND deleted_at IS NULL`)).
+		WithArgs(selector.IDs[0], selector.IDs[1]).WillReturnResult(sqlmock.NewResult(0, 2))
+	suite.sqlMock.ExpectCommit()
+
+	rowsAffected, err := suite.repository.DeleteCompanies(suite.context, selector)
+	suite.Nil(err)
+	suite.Equal(int64(2), rowsAffected)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestReserveIdempotencyKeySuccess() {
+	suite.sqlMock.ExpectExec(regexp.QuoteMeta(`INSERT INTO idempotency_keys (key,request_hash,expires_at)`)).
+		WithArgs("retry-1", "hash", float64(86400)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	reserved, err := suite.idempotencyRepository.ReserveIdempotencyKey(suite.context, "retry-1", "hash", 24*time.Hour)
+	suite.Nil(err)
+	suite.True(reserved)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestReserveIdempotencyKeyHeldByEarlierRequest() {
+	suite.sqlMock.ExpectExec(regexp.QuoteMeta(`INSERT INTO idempotency_keys (key,request_hash,expires_at)`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	reserved, err := suite.idempotencyRepository.ReserveIdempotencyKey(suite.context, "retry-1", "hash", 24*time.Hour)
+	suite.Nil(err)
+	suite.False(reserved)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestGetIdempotencyKeySuccess() {
+	expiresAt := time.Now().Add(time.Hour)
+	rows := sqlmock.NewRows([]string{"key", "request_hash", "status_code", "response_headers", "response_body", "expires_at"}).
+		AddRow("retry-1", "hash", 201, []byte(`{"Location":"/api/v1/company/1"}`), []byte(`{}`), expiresAt)
+	suite.sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT key,request_hash,status_code,response_headers,response_body,expires_at FROM idempotency_keys WHERE key = $1`)).
+		WithArgs("retry-1").WillReturnRows(rows)
+
+	record, err := suite.idempotencyRepository.GetIdempotencyKey(suite.context, "retry-1")
+	suite.Nil(err)
+	suite.Equal("hash", record.RequestHash)
+	suite.Equal(201, *record.StatusCode)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestListDeletedCompaniesSuccess() {
+	deletedAt := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "name", "description", "amount_of_employees", "registered", "type", "deleted_at"}).
+		AddRow("041d2027-e6fa-4d6d-836d-eedb235c82bc", "abc", "test company", 100, true, "Corporations", deletedAt)
+	suite.sqlMock.ExpectQuery(regexp.QuoteMeta(`FROM companies WHERE deleted_at IS NOT NULL`)).
+		WithArgs(20, 0).WillReturnRows(rows)
+
+	companies, err := suite.repository.ListDeletedCompanies(suite.context, 20, 0)
+	suite.Nil(err)
+	suite.Len(companies, 1)
+	suite.Equal("abc", companies[0].Name)
+	suite.Equal(deletedAt, companies[0].DeletedAt)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestRestoreCompanyNotInTrash() {
+	suite.expectActor()
+	suite.sqlMock.ExpectExec(regexp.QuoteMeta(`UPDATE companies SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL`)).
+		WithArgs("041d2027-e6fa-4d6d-836d-eedb235c82bc").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.sqlMock.ExpectCommit()
+
+	err := suite.repository.RestoreCompany(suite.context, "041d2027-e6fa-4d6d-836d-eedb235c82bc")
+	suite.Equal(sql.ErrNoRows, err)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestRestoreCompanyFailsIfNameTaken() {
+	suite.expectActor()
+	suite.sqlMock.ExpectExec(regexp.QuoteMeta(`UPDATE companies SET deleted_at = NULL`)).
+		WillReturnError(&pq.Error{Code: "23505", Constraint: "companies_name_key"})
+	suite.sqlMock.ExpectRollback()
+
+	err := suite.repository.RestoreCompany(suite.context, "041d2027-e6fa-4d6d-836d-eedb235c82bc")
+	suite.Equal(ErrCompanyNameExists, err)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestPurgeDeletedCompaniesSuccess() {
+	suite.sqlMock.ExpectExec(regexp.QuoteMeta(`DELETE FROM companies WHERE deleted_at < now() - make_interval(secs => $1)`)).
+		WithArgs(float64(3600)).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	purged, err := suite.repository.PurgeDeletedCompanies(context.Background(), time.Hour)
+	suite.Nil(err)
+	suite.Equal(int64(3), purged)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestGetCompanyHistorySuccess() {
+	id := "041d2027-e6fa-4d6d-836d-eedb235c82bc"
+	createdAt := time.Now()
+	suite.sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM company_revisions WHERE company_id = $1`)).
+		WithArgs(id).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	rows := sqlmock.NewRows([]string{"company_id", "revision", "operation", "snapshot", "changed_fields", "actor", "reverted_from", "created_at"}).
+		AddRow(id, 2, "update", []byte(`{"id":"`+id+`","name":"xyz"}`), "{name}", TestActor, nil, createdAt)
+	suite.sqlMock.ExpectQuery(regexp.QuoteMeta(`FROM company_revisions WHERE company_id = $1
+		ORDER BY revision DESC
+		LIMIT $2 OFFSET $3`)).
+		WithArgs(id, 1, 0).WillReturnRows(rows)
+
+	page, err := suite.repository.GetCompanyHistory(suite.context, id, 1, 0)
+	suite.Nil(err)
+	suite.Equal(2, page.TotalCount)
+	suite.Len(page.Data, 1)
+	suite.Equal(2, page.Data[0].Revision)
+	suite.Equal("update", page.Data[0].Operation)
+	suite.Equal([]string{"name"}, []string(page.Data[0].ChangedFields))
+	suite.Equal(TestActor, *page.Data[0].Actor)
+	suite.JSONEq(`{"id":"`+id+`","name":"xyz"}`, string(page.Data[0].Snapshot))
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestListCompaniesRevisions() {
+	ids := []string{"041d2027-e6fa-4d6d-836d-eedb235c82bc", "9b2e5a4c-1f3d-4e8a-9c7b-2d6f8e0a1b3c"}
+	rows := sqlmock.NewRows([]string{"company_id", "revision", "operation", "snapshot", "changed_fields", "actor", "reverted_from", "created_at"}).
+		AddRow(ids[0], 2, "update", []byte(`{}`), "{name}", TestActor, nil, time.Now()).
+		AddRow(ids[1], 1, "create", []byte(`{}`), "{}", TestActor, nil, time.Now())
+	suite.sqlMock.ExpectQuery(`FROM company_revisions WHERE company_id = ANY\(\$1\).*WHERE position <= \$2`).
+		WithArgs(pq.Array(ids), 5).WillReturnRows(rows)
+
+	revisions, err := suite.repository.ListCompaniesRevisions(suite.context, ids, 5)
+	suite.Nil(err)
+	suite.Len(revisions, 2)
+	suite.Equal(ids[1], revisions[1].CompanyID)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestUpdateCompanyRecordsRevertedRevision() {
+	id := "041d2027-e6fa-4d6d-836d-eedb235c82bc"
+	suite.context.Set(constants.RevertedFrom, 3)
+	suite.sqlMock.ExpectBegin()
+	suite.sqlMock.ExpectExec(regexp.QuoteMeta(TestSetRevisionActor)).
+		WithArgs(TestActor, "3").WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.sqlMock.ExpectExec(regexp.QuoteMeta(`UPDATE companies SET  name = $1   WHERE id = $2 `)).
+		WithArgs("xyz", id).WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.sqlMock.ExpectCommit()
+
+	err := suite.repository.UpdateCompany(suite.context, map[string]interface{}{"name": "xyz"}, id, nil)
+	suite.Nil(err)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestGetCompanyRevisionNotFound() {
+	suite.sqlMock.ExpectQuery(regexp.QuoteMeta(`FROM company_revisions WHERE company_id = $1 AND revision = $2`)).
+		WithArgs("041d2027-e6fa-4d6d-836d-eedb235c82bc", 7).
+		WillReturnRows(sqlmock.NewRows([]string{"company_id"}))
+
+	_, err := suite.repository.GetCompanyRevision(suite.context, "041d2027-e6fa-4d6d-836d-eedb235c82bc", 7)
+	suite.Equal(sql.ErrNoRows, err)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestGetCompanyAsOfSuccess() {
+	id := "041d2027-e6fa-4d6d-836d-eedb235c82bc"
+	asOf := time.Date(2023, 1, 31, 0, 0, 0, 0, time.UTC)
+	revisionAt := asOf.Add(-time.Hour)
+	rows := sqlmock.NewRows([]string{"id", "name", "description", "amount_of_employees", "registered", "type", "revision", "revision_at"}).
+		AddRow(id, "xyz", "test company", 100, true, "Corporations", 3, revisionAt)
+	suite.sqlMock.ExpectQuery(`WITH companies AS \(.*WHERE created_at <= \$2.*\) SELECT id,name,description,amount_of_employees,registered,type,version,created_at,updated_at,parent_id,revision,revision_at FROM companies WHERE id = \$1 AND deleted_at IS NULL`).
+		WithArgs(id, asOf).WillReturnRows(rows)
+
+	company, err := suite.repository.GetCompanyAsOf(suite.context, id, asOf)
+	suite.Nil(err)
+	suite.Equal("xyz", company.Name)
+	suite.Equal(3, company.Revision)
+	suite.Equal(revisionAt, company.RevisionAt)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestListCompaniesAsOfSuccess() {
+	asOf := time.Date(2023, 1, 31, 0, 0, 0, 0, time.UTC)
+	query := models.CompanyListQuery{
+		Filter: models.CompanyFilter{Types: []string{"Corporations"}},
+		SortBy: "name",
+		Limit:  1,
+		AsOf:   &asOf,
+	}
+	suite.sqlMock.ExpectQuery(`WITH companies AS \(.*WHERE created_at <= \$2.*\) SELECT COUNT\(\*\) FROM companies WHERE deleted_at IS NULL AND type IN \(\$1\)`).
+		WithArgs("Corporations", asOf).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	rows := sqlmock.NewRows([]string{"id", "name", "description", "amount_of_employees", "registered", "type", "revision", "revision_at"}).
+		AddRow("041d2027-e6fa-4d6d-836d-eedb235c82bc", "abc", "", 10, true, "Corporations", 1, asOf).
+		AddRow("9b2e5a4c-1f3d-4e8a-9c7b-2d6f8e0a1b3c", "xyz", "", 20, true, "Corporations", 2, asOf)
+	suite.sqlMock.ExpectQuery(`WITH companies AS \(.*WHERE created_at <= \$3.*\) SELECT .*,revision,revision_at FROM companies WHERE deleted_at IS NULL AND type IN \(\$1\) ORDER BY name ASC, id ASC LIMIT \$2`).
+		WithArgs("Corporations", 2, asOf).WillReturnRows(rows)
+
+	page, err := suite.repository.ListCompaniesAsOf(suite.context, query)
+	suite.Nil(err)
+	suite.Equal(2, page.TotalCount)
+	suite.Equal(asOf, page.AsOf)
+	suite.Len(page.Data, 1)
+	suite.Equal(1, page.Data[0].Revision)
+	suite.NotEmpty(page.NextCursor)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestUpdateCompanyFailsOnStaleVersion() {
+	id := "041d2027-e6fa-4d6d-836d-eedb235c82bc"
+	suite.expectActor()
+	suite.sqlMock.ExpectExec(regexp.QuoteMeta(`UPDATE companies SET  name = $1   WHERE id = $2 AND deleted_at IS NULL AND version = ANY($3)`)).
+		WithArgs("xyz", id, "{3}").WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.sqlMock.ExpectCommit()
+
+	err := suite.repository.UpdateCompany(suite.context, map[string]interface{}{"name": "xyz"}, id, []int{3})
+	suite.Equal(ErrVersionMismatch, err)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestUpdateCompanyNotFound() {
+	id := "041d2027-e6fa-4d6d-836d-eedb235c82bc"
+	suite.expectActor()
+	suite.sqlMock.ExpectExec(regexp.QuoteMeta(`UPDATE companies SET  name = $1   WHERE id = $2 AND deleted_at IS NULL`)).
+		WithArgs("xyz", id).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.sqlMock.ExpectCommit()
+
+	err := suite.repository.UpdateCompany(suite.context, map[string]interface{}{"name": "xyz"}, id, nil)
+	suite.Equal(sql.ErrNoRows, err)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestDeleteCompanyAtMatchingVersion() {
+	id := "041d2027-e6fa-4d6d-836d-eedb235c82bc"
+	suite.expectActor()
+	suite.sqlMock.ExpectExec(regexp.QuoteMeta(TestDeleteCompany+` AND version = ANY($2)`)).
+		WithArgs(id, "{2,3}").WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.sqlMock.ExpectCommit()
+
+	err := suite.repository.DeleteCompany(suite.context, id, []int{2, 3}, false)
+	suite.Nil(err)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestGetCompanyStatsSuccess() {
+	suite.sqlMock.ExpectBegin()
+	suite.sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*), COUNT(*) FILTER (WHERE registered) FROM companies WHERE deleted_at IS NULL AND type IN ($1)`)).
+		WithArgs("Corporations").WillReturnRows(sqlmock.NewRows([]string{"count", "count"}).AddRow(5, 3))
+	suite.sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT registered::text AS value, COUNT(*) AS count FROM companies WHERE deleted_at IS NULL AND type IN ($1) GROUP BY 1 ORDER BY count DESC, value`)).
+		WithArgs("Corporations").WillReturnRows(sqlmock.NewRows([]string{"value", "count"}).AddRow("true", 3).AddRow("false", 2))
+	suite.sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT width_bucket(amount_of_employees, $2::integer[]) AS bucket, COUNT(*) AS count FROM companies WHERE deleted_at IS NULL AND type IN ($1) GROUP BY 1`)).
+		WithArgs("Corporations", pq.Array([]int{10, 100})).
+		WillReturnRows(sqlmock.NewRows([]string{"bucket", "count"}).AddRow(0, 1).AddRow(2, 4))
+	suite.sqlMock.ExpectCommit()
+
+	query := models.CompanyStatsQuery{
+		Filter:  models.CompanyFilter{Types: []string{"Corporations"}},
+		GroupBy: "registered",
+		Buckets: []int{10, 100},
+	}
+	stats, err := suite.repository.GetCompanyStats(suite.context, query)
+	suite.Nil(err)
+	suite.Equal(5, stats.TotalCount)
+	suite.Equal(3, stats.RegisteredCount)
+	suite.Equal([]models.CompanyStatsGroup{{Value: "true", Count: 3}, {Value: "false", Count: 2}}, stats.Groups)
+	suite.Len(stats.Histogram, 3)
+	suite.Nil(stats.Histogram[0].Min)
+	suite.Equal(10, *stats.Histogram[0].Max)
+	suite.Equal(1, stats.Histogram[0].Count)
+	suite.Equal(0, stats.Histogram[1].Count)
+	suite.Equal(100, *stats.Histogram[2].Min)
+	suite.Nil(stats.Histogram[2].Max)
+	suite.Equal(4, stats.Histogram[2].Count)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestUpdateCompanyRejectsHierarchyCycle() {
+	id := "041d2027-e6fa-4d6d-836d-eedb235c82bc"
+	parentID := "9b2e5a4c-1f3d-4e8a-9c7b-2d6f8e0a1b3c"
+	suite.expectActor()
+	suite.sqlMock.ExpectExec(regexp.QuoteMeta(`UPDATE companies SET  parent_id = $1   WHERE id = $2 AND deleted_at IS NULL`)).
+		WithArgs(parentID, id).WillReturnError(&pq.Error{Code: "23514", Constraint: "companies_parent_cycle"})
+	suite.sqlMock.ExpectRollback()
+
+	err := suite.repository.UpdateCompany(suite.context, map[string]interface{}{"parent_id": parentID}, id, nil)
+	suite.Equal(ErrHierarchyCycle, err)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestUpdateCompanyRejectsMissingParent() {
+	id := "041d2027-e6fa-4d6d-836d-eedb235c82bc"
+	parentID := "9b2e5a4c-1f3d-4e8a-9c7b-2d6f8e0a1b3c"
+	suite.expectActor()
+	suite.sqlMock.ExpectExec(regexp.QuoteMeta(`UPDATE companies SET  parent_id = $1   WHERE id = $2 AND deleted_at IS NULL`)).
+		WithArgs(parentID, id).WillReturnError(&pq.Error{Code: "23503", Constraint: "companies_parent_id_fkey"})
+	suite.sqlMock.ExpectRollback()
+
+	err := suite.repository.UpdateCompany(suite.context, map[string]interface{}{"parent_id": parentID}, id, nil)
+	suite.Equal(ErrParentNotFound, err)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestUpdateCompanyKeepsOtherForeignKeyErrors() {
+	id := "041d2027-e6fa-4d6d-836d-eedb235c82bc"
+	fkErr := &pq.Error{Code: "23503", Constraint: "company_contacts_company_id_fkey"}
+	suite.expectActor()
+	suite.sqlMock.ExpectExec(regexp.QuoteMeta(`UPDATE companies SET  name = $1   WHERE id = $2 AND deleted_at IS NULL`)).
+		WithArgs("xyz", id).WillReturnError(fkErr)
+	suite.sqlMock.ExpectRollback()
+
+	err := suite.repository.UpdateCompany(suite.context, map[string]interface{}{"name": "xyz"}, id, nil)
+	suite.Equal(fkErr, err)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestDeleteCompanyWithSubsidiaries() {
+	id := "041d2027-e6fa-4d6d-836d-eedb235c82bc"
+	suite.expectActor()
+	suite.sqlMock.ExpectExec(regexp.QuoteMeta(TestDeleteCompany)).
+		WithArgs(id).WillReturnError(&pq.Error{Code: "23001", Constraint: "companies_subsidiaries"})
+	suite.sqlMock.ExpectRollback()
+
+	err := suite.repository.DeleteCompany(suite.context, id, nil, false)
+	suite.Equal(ErrCompanyHasSubsidiaries, err)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestDeleteCompanyCascadesToSubsidiaries() {
+	id := "041d2027-e6fa-4d6d-836d-eedb235c82bc"
+	suite.expectActor()
+	suite.sqlMock.ExpectExec(regexp.QuoteMeta(`WITH RECURSIVE subtree AS (
+			SELECT id FROM companies WHERE id = $1 AND deleted_at IS NULL AND version = ANY($2)
+			UNION ALL`)).
+		WithArgs(id, "{2}").WillReturnResult(sqlmock.NewResult(0, 3))
+	suite.sqlMock.ExpectCommit()
+
+	err := suite.repository.DeleteCompany(suite.context, id, []int{2}, true)
+	suite.Nil(err)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestListCompanyAncestorsSuccess() {
+	id := "041d2027-e6fa-4d6d-836d-eedb235c82bc"
+	parentID := "9b2e5a4c-1f3d-4e8a-9c7b-2d6f8e0a1b3c"
+	suite.sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT id,name,description,amount_of_employees,registered,type,version,created_at,updated_at,parent_id,depth FROM ancestors ORDER BY depth`)).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "parent_id", "depth"}).
+			AddRow(parentID, "abc", nil, 1))
+
+	ancestors, err := suite.repository.ListCompanyAncestors(suite.context, id)
+	suite.Nil(err)
+	suite.Len(ancestors, 1)
+	suite.Equal(parentID, ancestors[0].ID)
+	suite.Nil(ancestors[0].ParentID)
+	suite.Equal(1, ancestors[0].Depth)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestListCompanyAncestorsStopsAtTrashedParent() {
+	id := "041d2027-e6fa-4d6d-836d-eedb235c82bc"
+	suite.sqlMock.ExpectQuery(`WHERE child.id = \$1 AND child.deleted_at IS NULL AND parent.deleted_at IS NULL\s+UNION ALL\s+.+\s+WHERE parent.deleted_at IS NULL`).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "parent_id", "depth"}))
+
+	ancestors, err := suite.repository.ListCompanyAncestors(suite.context, id)
+	suite.Nil(err)
+	suite.Empty(ancestors)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestGetCompanySubtreeNotFound() {
+	id := "041d2027-e6fa-4d6d-836d-eedb235c82bc"
+	suite.sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT id,name,description,amount_of_employees,registered,type,version,created_at,updated_at,parent_id,depth FROM subtree ORDER BY depth, name, id`)).
+		WithArgs(id).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "parent_id", "depth"}))
+
+	_, err := suite.repository.GetCompanySubtree(suite.context, id)
+	suite.Equal(sql.ErrNoRows, err)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestCreateShareholdingExceedingTotal() {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	shareholding := models.Shareholding{
+		ID:            "5f0c7a3e-8d2b-4c1a-9e6f-3b7d2a1c0e9f",
+		CompanyID:     "041d2027-e6fa-4d6d-836d-eedb235c82bc",
+		ShareholderID: "9b2e5a4c-1f3d-4e8a-9c7b-2d6f8e0a1b3c",
+		Percentage:    60,
+		EffectiveFrom: from,
+	}
+	suite.sqlMock.ExpectExec(regexp.QuoteMeta(`INSERT INTO company_shareholdings (id,company_id,shareholder_id,percentage,effective_from,effective_to) VALUES ($1,$2,$3,$4,$5,$6)`)).
+		WithArgs(shareholding.ID, shareholding.CompanyID, shareholding.ShareholderID, 60.0, from, nil).
+		WillReturnError(&pq.Error{Code: "23514", Constraint: "company_shareholdings_total"})
+
+	err := suite.shareholdingRepo.CreateShareholding(suite.context, shareholding)
+	suite.Equal(ErrShareholdingsExceedTotal, err)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestUpdateShareholdingOverlapping() {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	shareholding := models.Shareholding{
+		ID:            "5f0c7a3e-8d2b-4c1a-9e6f-3b7d2a1c0e9f",
+		CompanyID:     "041d2027-e6fa-4d6d-836d-eedb235c82bc",
+		Percentage:    25,
+		EffectiveFrom: from,
+	}
+	suite.sqlMock.ExpectExec(regexp.QuoteMeta(`UPDATE company_shareholdings SET percentage = $3, effective_from = $4, effective_to = $5 WHERE id = $1 AND company_id = $2`)).
+		WithArgs(shareholding.ID, shareholding.CompanyID, 25.0, from, nil).
+		WillReturnError(&pq.Error{Code: "23P01", Constraint: "company_shareholdings_overlap"})
+
+	err := suite.shareholdingRepo.UpdateShareholding(suite.context, shareholding)
+	suite.Equal(ErrShareholdingOverlaps, err)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestDeleteShareholdingNotFound() {
+	suite.sqlMock.ExpectExec(regexp.QuoteMeta(`DELETE FROM company_shareholdings WHERE id = $1 AND company_id = $2`)).
+		WithArgs("5f0c7a3e-8d2b-4c1a-9e6f-3b7d2a1c0e9f", "041d2027-e6fa-4d6d-836d-eedb235c82bc").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := suite.shareholdingRepo.DeleteShareholding(suite.context, "041d2027-e6fa-4d6d-836d-eedb235c82bc", "5f0c7a3e-8d2b-4c1a-9e6f-3b7d2a1c0e9f")
+	suite.Equal(sql.ErrNoRows, err)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestListOwnershipGraphSuccess() {
+	id := "041d2027-e6fa-4d6d-836d-eedb235c82bc"
+	shareholderID := "9b2e5a4c-1f3d-4e8a-9c7b-2d6f8e0a1b3c"
+	asOf := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	suite.sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT id,company_id,shareholder_id,shareholder_name,percentage,effective_from,effective_to,created_at FROM graph`)).
+		WithArgs(id, asOf).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "company_id", "shareholder_id", "shareholder_name", "percentage"}).
+			AddRow("5f0c7a3e-8d2b-4c1a-9e6f-3b7d2a1c0e9f", id, shareholderID, "abc", 51.5))
+
+	graph, err := suite.shareholdingRepo.ListOwnershipGraph(suite.context, id, asOf)
+	suite.Nil(err)
+	suite.Len(graph, 1)
+	suite.Equal(shareholderID, graph[0].ShareholderID)
+	suite.Equal(51.5, graph[0].Percentage)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestCreateLocationSecondHeadquarters() {
+	latitude, longitude := 13.0827, 80.2707
+	location := models.Location{
+		ID:        "5f0c7a3e-8d2b-4c1a-9e6f-3b7d2a1c0e9f",
+		CompanyID: "041d2027-e6fa-4d6d-836d-eedb235c82bc",
+		Type:      constants.LocationHeadquarters,
+		Street:    "1 Main Street",
+		City:      "Chennai",
+		Country:   "IN",
+		Latitude:  &latitude,
+		Longitude: &longitude,
+	}
+	suite.sqlMock.ExpectExec(regexp.QuoteMeta(`INSERT INTO company_locations (id,company_id,type,street,city,postal_code,region,country,latitude,longitude)`)).
+		WithArgs(location.ID, location.CompanyID, "headquarters", "1 Main Street", "Chennai", "", "", "IN", 13.0827, 80.2707).
+		WillReturnError(&pq.Error{Code: "23505", Constraint: "company_locations_headquarters_idx"})
+
+	err := suite.locationRepo.CreateLocation(suite.context, location)
+	suite.Equal(ErrHeadquartersExists, err)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestUpdateLocationNotFound() {
+	location := models.Location{ID: "5f0c7a3e-8d2b-4c1a-9e6f-3b7d2a1c0e9f", CompanyID: "041d2027-e6fa-4d6d-836d-eedb235c82bc"}
+	suite.sqlMock.ExpectExec(regexp.QuoteMeta(`UPDATE company_locations SET type = $3`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := suite.locationRepo.UpdateLocation(suite.context, location)
+	suite.Equal(sql.ErrNoRows, err)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestListNearbyCompaniesSuccess() {
+	id := "041d2027-e6fa-4d6d-836d-eedb235c82bc"
+	locationID := "5f0c7a3e-8d2b-4c1a-9e6f-3b7d2a1c0e9f"
+	query := models.NearbyQuery{Latitude: 13, Longitude: 80, RadiusKm: 111.19508, Limit: 20}
+	suite.sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT companies.id,companies.name,companies.description,companies.amount_of_employees,companies.registered,companies.type,companies.version,companies.created_at,companies.updated_at,companies.parent_id,
+		locations.id AS "location.id",locations.company_id AS "location.company_id"`)).
+		WithArgs(13.0, 80.0, sqlmock.AnyArg(), sqlmock.AnyArg(), 111.19508, 20, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "type", "location.id", "location.company_id", "location.type", "location.latitude", "distance_km"}).
+			AddRow(id, "xyz", "Corporations", locationID, id, "branch", 13.0827, 9.2))
+
+	companies, err := suite.locationRepo.ListNearbyCompanies(suite.context, query)
+	suite.Nil(err)
+	suite.Len(companies, 1)
+	suite.Equal(id, companies[0].ID)
+	suite.Equal("Corporations", companies[0].Type)
+	suite.Equal(locationID, companies[0].Location.ID)
+	suite.Equal("branch", companies[0].Location.Type)
+	suite.Equal(9.2, companies[0].DistanceKm)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestCreateContactEmailExists() {
+	contact := models.Contact{
+		ID:        "5f0c7a3e-8d2b-4c1a-9e6f-3b7d2a1c0e9f",
+		CompanyID: "041d2027-e6fa-4d6d-836d-eedb235c82bc",
+		Name:      "Asha Rao",
+		Role:      "CFO",
+		Email:     "asha@xyz.com",
+		Phone:     "+914412345678",
+	}
+	suite.sqlMock.ExpectExec(regexp.QuoteMeta(`INSERT INTO company_contacts (id,company_id,name,role,email,phone) VALUES ($1,$2,$3,$4,$5,$6)`)).
+		WithArgs(contact.ID, contact.CompanyID, "Asha Rao", "CFO", "asha@xyz.com", "+914412345678").
+		WillReturnError(&pq.Error{Code: "23505", Constraint: "company_contacts_email_idx"})
+
+	err := suite.contactRepo.CreateContact(suite.context, contact)
+	suite.Equal(ErrContactEmailExists, err)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *RepositoryTestSuite) TestSearchContactsEscapesWildcards() {
+	suite.sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT contacts.id,contacts.company_id,contacts.name,contacts.role,contacts.email,contacts.phone,contacts.created_at,contacts.updated_at,companies.name AS company_name`)).
+		WithArgs(`%100\%%`, 20, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email", "company_name"}).
+			AddRow("5f0c7a3e-8d2b-4c1a-9e6f-3b7d2a1c0e9f", "Asha Rao", "asha@xyz.com", "xyz"))
+
+	contacts, err := suite.contactRepo.SearchContacts(suite.context, "100%", 20, 0)
 	suite.Nil(err)
+	suite.Len(contacts, 1)
+	suite.Equal("Asha Rao", contacts[0].Name)
+	suite.Equal("xyz", contacts[0].CompanyName)
+	suite.Nil(suite.sqlMock.ExpectationsWereMet())
 }
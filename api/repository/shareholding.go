@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/kumareswaramoorthi/companies/api/constants"
+	"github.com/kumareswaramoorthi/companies/api/logging"
+	"github.com/kumareswaramoorthi/companies/api/models"
+	"github.com/lib/pq"
+)
+
+type ShareholdingRepository interface {
+	CreateShareholding(c *gin.Context, shareholding models.Shareholding) error
+	GetShareholding(c *gin.Context, companyID, id string) (models.Shareholding, error)
+	ListShareholdings(c *gin.Context, companyID string, asOf *time.Time) ([]models.Shareholding, error)
+	UpdateShareholding(c *gin.Context, shareholding models.Shareholding) error
+	DeleteShareholding(c *gin.Context, companyID, id string) error
+	ListOwnershipGraph(c *gin.Context, companyID string, asOf time.Time) ([]models.Shareholding, error)
+}
+
+type shareholdingRepository struct {
+	db *sqlx.DB
+}
+
+func NewShareholdingRepository(db *sqlx.DB) ShareholdingRepository {
+	return shareholdingRepository{db: db}
+}
+
+var (
+	// ErrShareholderNotFound is returned when a shareholding refers to a company that does not exist.
+	ErrShareholderNotFound = errors.New("shareholder company does not exist")
+	// ErrShareholdingOverlaps is returned when a shareholder would hold two stakes in a company at once.
+	ErrShareholdingOverlaps = errors.New("shareholding overlaps another stake of the shareholder")
+	// ErrShareholdingsExceedTotal is returned when the stakes in a company would add up to more than 100 percent.
+	ErrShareholdingsExceedTotal = errors.New("shareholdings add up to more than 100 percent")
+)
+
+// shareholdingColumns is the select list matching models.Shareholding, reading the
+// shareholder name from the companies table joined as shareholder.
+const shareholdingColumns = `shareholdings.id,shareholdings.company_id,shareholdings.shareholder_id,shareholder.name AS shareholder_name,
+	shareholdings.percentage,shareholdings.effective_from,shareholdings.effective_to,shareholdings.created_at`
+
+const (
+	insertShareholding = `INSERT INTO company_shareholdings (id,company_id,shareholder_id,percentage,effective_from,effective_to) VALUES ($1,$2,$3,$4,$5,$6)`
+	updateShareholding = `UPDATE company_shareholdings SET percentage = $3, effective_from = $4, effective_to = $5 WHERE id = $1 AND company_id = $2`
+	deleteShareholding = `DELETE FROM company_shareholdings WHERE id = $1 AND company_id = $2`
+	getShareholding    = `SELECT ` + shareholdingColumns + ` FROM company_shareholdings shareholdings
+		JOIN companies shareholder ON shareholder.id = shareholdings.shareholder_id
+		WHERE shareholdings.id = $1 AND shareholdings.company_id = $2`
+	listShareholdings = `SELECT ` + shareholdingColumns + ` FROM company_shareholdings shareholdings
+		JOIN companies shareholder ON shareholder.id = shareholdings.shareholder_id AND shareholder.deleted_at IS NULL
+		WHERE shareholdings.company_id = $1 AND ($2::timestamptz IS NULL OR shareholdings.effective_from <= $2 AND (shareholdings.effective_to IS NULL OR shareholdings.effective_to > $2))
+		ORDER BY shareholdings.effective_from DESC, shareholdings.percentage DESC, shareholdings.id`
+	// listOwnershipGraph walks up from a company through its shareholders, theirs and so
+	// on. UNION drops the stakes already walked, so circular holdings end the walk.
+	listOwnershipGraph = `WITH RECURSIVE active AS (
+			SELECT ` + shareholdingColumns + ` FROM company_shareholdings shareholdings
+			JOIN companies shareholder ON shareholder.id = shareholdings.shareholder_id AND shareholder.deleted_at IS NULL
+			WHERE shareholdings.effective_from <= $2 AND (shareholdings.effective_to IS NULL OR shareholdings.effective_to > $2)
+		), graph AS (
+			SELECT * FROM active WHERE company_id = $1
+			UNION
+			SELECT active.* FROM active JOIN graph ON active.company_id = graph.shareholder_id
+		)
+		SELECT id,company_id,shareholder_id,shareholder_name,percentage,effective_from,effective_to,created_at FROM graph`
+)
+
+func (r shareholdingRepository) CreateShareholding(c *gin.Context, shareholding models.Shareholding) error {
+
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Repository").
+		WithField(constants.Method, "CreateShareholding")
+
+	_, err := r.db.ExecContext(c.Request.Context(), insertShareholding, shareholding.ID, shareholding.CompanyID,
+		shareholding.ShareholderID, shareholding.Percentage, shareholding.EffectiveFrom, shareholding.EffectiveTo)
+	if err != nil {
+		logger.Errorf("repository: CreateShareholding company ID [%s] error: %s", shareholding.CompanyID, err.Error())
+		return translateShareholdingError(err)
+	}
+
+	logger.Debugf("created shareholding with ID: [%s]", shareholding.ID)
+	return nil
+}
+
+// GetShareholding returns a stake held in a company, or sql.ErrNoRows.
+func (r shareholdingRepository) GetShareholding(c *gin.Context, companyID, id string) (models.Shareholding, error) {
+
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Repository").
+		WithField(constants.Method, "GetShareholding")
+
+	var shareholding models.Shareholding
+	err := r.db.GetContext(c.Request.Context(), &shareholding, getShareholding, id, companyID)
+	if err != nil {
+		logger.Errorf("repository: GetShareholding ID [%s] error: %s", id, err.Error())
+		return models.Shareholding{}, err
+	}
+
+	logger.Debugf("found shareholding with ID: [%s]", id)
+	return shareholding, nil
+}
+
+// ListShareholdings returns the stakes held in a company by companies that are not in
+// the trash, only those in effect at asOf when it is not nil.
+func (r shareholdingRepository) ListShareholdings(c *gin.Context, companyID string, asOf *time.Time) ([]models.Shareholding, error) {
+
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Repository").
+		WithField(constants.Method, "ListShareholdings")
+
+	shareholdings := []models.Shareholding{}
+	err := r.db.SelectContext(c.Request.Context(), &shareholdings, listShareholdings, companyID, asOf)
+	if err != nil {
+		logger.Errorf("repository: ListShareholdings company ID [%s] error: %s", companyID, err.Error())
+		return nil, err
+	}
+
+	logger.Debugf("found %d shareholdings in company with ID: [%s]", len(shareholdings), companyID)
+	return shareholdings, nil
+}
+
+// UpdateShareholding changes the percentage and the period of a stake. It returns
+// sql.ErrNoRows when the company has no such stake.
+func (r shareholdingRepository) UpdateShareholding(c *gin.Context, shareholding models.Shareholding) error {
+
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Repository").
+		WithField(constants.Method, "UpdateShareholding")
+
+	result, err := r.db.ExecContext(c.Request.Context(), updateShareholding, shareholding.ID, shareholding.CompanyID,
+		shareholding.Percentage, shareholding.EffectiveFrom, shareholding.EffectiveTo)
+	if err != nil {
+		logger.Errorf("repository: UpdateShareholding ID [%s] error: %s", shareholding.ID, err.Error())
+		return translateShareholdingError(err)
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	logger.Debugf("updated shareholding with ID: [%s]", shareholding.ID)
+	return nil
+}
+
+// DeleteShareholding removes a stake held in a company. It returns sql.ErrNoRows when
+// the company has no such stake.
+func (r shareholdingRepository) DeleteShareholding(c *gin.Context, companyID, id string) error {
+
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Repository").
+		WithField(constants.Method, "DeleteShareholding")
+
+	result, err := r.db.ExecContext(c.Request.Context(), deleteShareholding, id, companyID)
+	if err != nil {
+		logger.Errorf("repository: DeleteShareholding ID [%s] error: %s", id, err.Error())
+		return err
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	logger.Debugf("deleted shareholding with ID: [%s]", id)
+	return nil
+}
+
+// ListOwnershipGraph returns every stake in effect at asOf on the ownership chains above
+// a company: the stakes held in it, the stakes held in its shareholders and so on.
+func (r shareholdingRepository) ListOwnershipGraph(c *gin.Context, companyID string, asOf time.Time) ([]models.Shareholding, error) {
+
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Repository").
+		WithField(constants.Method, "ListOwnershipGraph")
+
+	shareholdings := []models.Shareholding{}
+	err := r.db.SelectContext(c.Request.Context(), &shareholdings, listOwnershipGraph, companyID, asOf)
+	if err != nil {
+		logger.Errorf("repository: ListOwnershipGraph company ID [%s] error: %s", companyID, err.Error())
+		return nil, err
+	}
+
+	logger.Debugf("found %d shareholdings above company with ID: [%s]", len(shareholdings), companyID)
+	return shareholdings, nil
+}
+
+// translateShareholdingError maps the constraint violations of company_shareholdings to
+// ErrShareholderNotFound, ErrShareholdingOverlaps or ErrShareholdingsExceedTotal.
+func translateShareholdingError(err error) error {
+	pqErr, ok := err.(*pq.Error)
+	if !ok {
+		return err
+	}
+	switch pqErr.Code {
+	case "23503":
+		return ErrShareholderNotFound
+	case "23P01":
+		return ErrShareholdingOverlaps
+	case "23514":
+		if pqErr.Constraint == "company_shareholdings_total" {
+			return ErrShareholdingsExceedTotal
+		}
+	}
+	return err
+}
@@ -1,17 +1,22 @@
 package router
 
 import (
+	"context"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/gin-contrib/requestid"
 	"github.com/gin-gonic/gin"
+	"github.com/kumareswaramoorthi/companies/api/constants"
 	"github.com/kumareswaramoorthi/companies/api/controller"
 	"github.com/kumareswaramoorthi/companies/api/database"
+	"github.com/kumareswaramoorthi/companies/api/jobs"
 	"github.com/kumareswaramoorthi/companies/api/logging"
 	"github.com/kumareswaramoorthi/companies/api/middleware"
 	"github.com/kumareswaramoorthi/companies/api/repository"
 	"github.com/kumareswaramoorthi/companies/api/service"
+	"github.com/kumareswaramoorthi/companies/api/utils"
 	docs "github.com/kumareswaramoorthi/companies/docs"
 	swaggerfiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -41,10 +46,42 @@ func SetupRouter() *gin.Engine {
 		log.Fatal(err)
 	}
 
+	statsCacheTTL, err := time.ParseDuration(utils.GetEnvVars("STATS_CACHE_TTL", constants.DefaultStatsCacheTTL))
+	if err != nil {
+		log.Fatal(err)
+	}
 	companyRepo := repository.NewRepository(dbConn)
-	companySvc := service.NewService(companyRepo)
+	companySvc := service.NewService(companyRepo, statsCacheTTL)
 	companyCtrl := controller.NewController(companySvc)
 
+	idempotencyTTL, err := time.ParseDuration(utils.GetEnvVars("IDEMPOTENCY_KEY_TTL", constants.DefaultIdempotencyKeyTTL))
+	if err != nil {
+		log.Fatal(err)
+	}
+	idempotencyRepo := repository.NewIdempotencyRepository(dbConn)
+	idempotency := middleware.Idempotency(idempotencyRepo, idempotencyTTL)
+	jobs.Schedule(context.Background(), "PurgeExpiredIdempotencyKeys", constants.IdempotencyKeyPurgeInterval, idempotencyRepo.PurgeExpiredIdempotencyKeys)
+
+	trashRetention, err := time.ParseDuration(utils.GetEnvVars("TRASH_RETENTION", constants.DefaultTrashRetention))
+	if err != nil {
+		log.Fatal(err)
+	}
+	jobs.Schedule(context.Background(), "PurgeDeletedCompanies", constants.TrashPurgeInterval, func(ctx context.Context) (int64, error) {
+		return companyRepo.PurgeDeletedCompanies(ctx, trashRetention)
+	})
+
+	shareholdingRepo := repository.NewShareholdingRepository(dbConn)
+	shareholdingSvc := service.NewShareholdingService(shareholdingRepo, companyRepo)
+	shareholdingCtrl := controller.NewShareholdingController(shareholdingSvc)
+
+	locationRepo := repository.NewLocationRepository(dbConn)
+	locationSvc := service.NewLocationService(locationRepo, companyRepo)
+	locationCtrl := controller.NewLocationController(locationSvc)
+
+	contactRepo := repository.NewContactRepository(dbConn)
+	contactSvc := service.NewContactService(contactRepo, companyRepo)
+	contactCtrl := controller.NewContactController(contactSvc)
+
 	loginService := service.StaticLoginService()
 	jwtService := service.JWTAuthService()
 	loginCtrl := controller.NewLoginController(loginService, jwtService)
@@ -58,10 +95,45 @@ func SetupRouter() *gin.Engine {
 
 	v1.POST("/login", loginCtrl.Login)
 	v1.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
-	v1.GET("/company/:id", companyCtrl.GetCompany)
-	v1.POST("/company", middleware.AuthorizeJWT(), companyCtrl.CreateCompany)
-	v1.PATCH("/company/:id", middleware.AuthorizeJWT(), companyCtrl.UpdateCompany)
-	v1.DELETE("/company/:id", middleware.AuthorizeJWT(), companyCtrl.DeleteCompany)
+	v1.GET("/company", middleware.AuthorizeIncludes(constants.IncludeHistory), companyCtrl.ListCompanies)
+	v1.GET("/company/search", companyCtrl.SearchCompanies)
+	v1.GET("/company/suggest", companyCtrl.SuggestCompanies)
+	v1.GET("/company/export", companyCtrl.ExportCompanies)
+	v1.GET("/company/stats", companyCtrl.GetCompanyStats)
+	v1.GET("/company/near", locationCtrl.ListNearbyCompanies)
+	v1.GET("/company/by-name/:name", companyCtrl.GetCompanyByName)
+	v1.GET("/company/trash", middleware.AuthorizeJWT(), middleware.AuthorizeAdmin(), companyCtrl.ListDeletedCompanies)
+	v1.GET("/company/:id", middleware.AuthorizeIncludes(constants.IncludeHistory), companyCtrl.GetCompany)
+	v1.GET("/company/:id/history", middleware.AuthorizeJWT(), companyCtrl.GetCompanyHistory)
+	v1.GET("/company/:id/ancestors", companyCtrl.GetCompanyAncestors)
+	v1.GET("/company/:id/children", companyCtrl.ListCompanyChildren)
+	v1.GET("/company/:id/subtree", companyCtrl.GetCompanySubtree)
+	v1.GET("/company/:id/shareholdings", shareholdingCtrl.ListShareholdings)
+	v1.GET("/company/:id/owners", shareholdingCtrl.GetUltimateOwners)
+	v1.GET("/company/:id/locations", locationCtrl.ListLocations)
+	v1.GET("/company/:id/locations/:location_id", locationCtrl.GetLocation)
+	v1.GET("/company/:id/contacts", middleware.AuthorizeJWT(), contactCtrl.ListContacts)
+	v1.GET("/company/:id/contacts/:contact_id", middleware.AuthorizeJWT(), contactCtrl.GetContact)
+	v1.GET("/contacts/search", middleware.AuthorizeJWT(), contactCtrl.SearchContacts)
+	v1.POST("/company", middleware.AuthorizeJWT(), idempotency, companyCtrl.CreateCompany)
+	v1.POST("/company/bulk", middleware.AuthorizeJWT(), idempotency, companyCtrl.CreateCompanies)
+	v1.POST("/company/import", middleware.AuthorizeJWT(), companyCtrl.ImportCompanies)
+	v1.POST("/company/:id/restore", middleware.AuthorizeJWT(), middleware.AuthorizeAdmin(), companyCtrl.RestoreCompany)
+	v1.POST("/company/:id/revert", middleware.AuthorizeJWT(), middleware.AuthorizeAdmin(), idempotency, companyCtrl.RevertCompany)
+	v1.POST("/company/:id/shareholdings", middleware.AuthorizeJWT(), idempotency, shareholdingCtrl.CreateShareholding)
+	v1.POST("/company/:id/locations", middleware.AuthorizeJWT(), idempotency, locationCtrl.CreateLocation)
+	v1.POST("/company/:id/contacts", middleware.AuthorizeJWT(), idempotency, contactCtrl.CreateContact)
+	v1.PATCH("/company/bulk", middleware.AuthorizeJWT(), idempotency, companyCtrl.UpdateCompanies)
+	v1.PATCH("/company/:id", middleware.AuthorizeJWT(), idempotency, companyCtrl.UpdateCompany)
+	v1.PUT("/company/:id", middleware.AuthorizeJWT(), idempotency, companyCtrl.ReplaceCompany)
+	v1.PUT("/company/:id/shareholdings/:shareholding_id", middleware.AuthorizeJWT(), idempotency, shareholdingCtrl.ReplaceShareholding)
+	v1.PUT("/company/:id/locations/:location_id", middleware.AuthorizeJWT(), idempotency, locationCtrl.ReplaceLocation)
+	v1.PUT("/company/:id/contacts/:contact_id", middleware.AuthorizeJWT(), idempotency, contactCtrl.ReplaceContact)
+	v1.DELETE("/company/bulk", middleware.AuthorizeJWT(), idempotency, companyCtrl.DeleteCompanies)
+	v1.DELETE("/company/:id", middleware.AuthorizeJWT(), idempotency, companyCtrl.DeleteCompany)
+	v1.DELETE("/company/:id/shareholdings/:shareholding_id", middleware.AuthorizeJWT(), idempotency, shareholdingCtrl.DeleteShareholding)
+	v1.DELETE("/company/:id/locations/:location_id", middleware.AuthorizeJWT(), idempotency, locationCtrl.DeleteLocation)
+	v1.DELETE("/company/:id/contacts/:contact_id", middleware.AuthorizeJWT(), idempotency, contactCtrl.DeleteContact)
 
 	return router
 }
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/kumareswaramoorthi/companies/api/constants"
+	"github.com/kumareswaramoorthi/companies/api/models"
+)
+
+// statsCache keeps computed company stats for ttl, keyed by their query. A ttl of zero
+// disables it. Expired entries are dropped when new ones are stored.
+type statsCache struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]models.CompanyStats
+}
+
+func newStatsCache(ttl time.Duration) *statsCache {
+	return &statsCache{ttl: ttl, entries: map[string]models.CompanyStats{}}
+}
+
+func (sc *statsCache) get(key string) (models.CompanyStats, bool) {
+	if sc.ttl <= 0 {
+		return models.CompanyStats{}, false
+	}
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	stats, ok := sc.entries[key]
+	if !ok || time.Since(stats.ComputedAt) > sc.ttl {
+		return models.CompanyStats{}, false
+	}
+	return stats, true
+}
+
+func (sc *statsCache) set(key string, stats models.CompanyStats) {
+	if sc.ttl <= 0 {
+		return
+	}
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	for cachedKey, cached := range sc.entries {
+		if time.Since(cached.ComputedAt) > sc.ttl {
+			delete(sc.entries, cachedKey)
+		}
+	}
+	if len(sc.entries) < constants.MaxStatsCacheEntries {
+		sc.entries[key] = stats
+	}
+}
@@ -1,28 +1,63 @@
 package service
 
 import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/asaskevich/govalidator"
 	"github.com/gin-contrib/requestid"
 	"github.com/gin-gonic/gin"
+	"github.com/kumareswaramoorthi/companies/api/codec"
 	"github.com/kumareswaramoorthi/companies/api/constants"
 	errors "github.com/kumareswaramoorthi/companies/api/errors"
 	"github.com/kumareswaramoorthi/companies/api/logging"
 	"github.com/kumareswaramoorthi/companies/api/models"
+	"github.com/kumareswaramoorthi/companies/api/patch"
 	"github.com/kumareswaramoorthi/companies/api/repository"
+	"github.com/kumareswaramoorthi/companies/api/utils"
 )
 
 type Company interface {
 	CreateCompany(c *gin.Context, company models.Company) (models.Company, *errors.ErrorResponse)
-	GetCompany(c *gin.Context, id string) (models.Company, *errors.ErrorResponse)
-	DeleteCompany(c *gin.Context, id string) *errors.ErrorResponse
-	UpdateCompany(c *gin.Context, id string, updateReq map[string]interface{}) (models.Company, *errors.ErrorResponse)
+	CreateCompanies(c *gin.Context, companies []models.Company, documents []map[string]interface{}, atomic bool) (models.BulkResult, *errors.ErrorResponse)
+	GetCompany(c *gin.Context, id string, fields []string) (models.Company, *errors.ErrorResponse)
+	GetCompanyByName(c *gin.Context, name string) (models.Company, *errors.ErrorResponse)
+	DeleteCompany(c *gin.Context, id string, versions []int, cascade bool) *errors.ErrorResponse
+	UpdateCompany(c *gin.Context, id string, updateReq map[string]interface{}, versions []int) (models.Company, *errors.ErrorResponse)
+	PatchCompany(c *gin.Context, id string, p patch.Patch, versions []int) (models.Company, *errors.ErrorResponse)
+	ReplaceCompany(c *gin.Context, company models.Company, versions []int, mustExist bool) (models.Company, bool, *errors.ErrorResponse)
+	UpdateCompanies(c *gin.Context, selector models.CompanySelector, updateReq map[string]interface{}) (models.BulkChangeResult, *errors.ErrorResponse)
+	DeleteCompanies(c *gin.Context, selector models.CompanySelector) (models.BulkChangeResult, *errors.ErrorResponse)
+	ListCompanies(c *gin.Context, query models.CompanyListQuery) (models.CompanyPage, *errors.ErrorResponse)
+	SearchCompanies(c *gin.Context, text string, limit, offset int) ([]models.CompanySearchResult, *errors.ErrorResponse)
+	SuggestCompanies(c *gin.Context, prefix string, limit int) ([]models.CompanySuggestion, *errors.ErrorResponse)
+	ImportCompanies(c *gin.Context, decoder codec.CompanyDecoder, onConflict string) (models.ImportReport, *errors.ErrorResponse)
+	ExportCompanies(c *gin.Context, query models.CompanyListQuery, encoder codec.CompanyEncoder) *errors.ErrorResponse
+	ListDeletedCompanies(c *gin.Context, limit, offset int) ([]models.DeletedCompany, *errors.ErrorResponse)
+	RestoreCompany(c *gin.Context, id string) (models.Company, *errors.ErrorResponse)
+	GetCompanyHistory(c *gin.Context, id string, limit, offset int) (models.CompanyRevisionPage, *errors.ErrorResponse)
+	GetCompaniesHistory(c *gin.Context, ids []string, limit int) (map[string][]models.CompanyRevision, *errors.ErrorResponse)
+	RevertCompany(c *gin.Context, id string, revision int) (models.Company, *errors.ErrorResponse)
+	GetCompanyAsOf(c *gin.Context, id string, asOf time.Time) (models.CompanyVersion, *errors.ErrorResponse)
+	ListCompaniesAsOf(c *gin.Context, query models.CompanyListQuery) (models.CompanyVersionPage, *errors.ErrorResponse)
+	GetCompanyStats(c *gin.Context, query models.CompanyStatsQuery) (models.CompanyStats, *errors.ErrorResponse)
+	GetCompanyAncestors(c *gin.Context, id string) ([]models.CompanyNode, *errors.ErrorResponse)
+	ListCompanyChildren(c *gin.Context, id string, limit, offset int) ([]models.Company, *errors.ErrorResponse)
+	GetCompanySubtree(c *gin.Context, id string) (models.CompanyTree, *errors.ErrorResponse)
 }
 
 type company struct {
-	repo repository.Repository
+	repo  repository.Repository
+	stats *statsCache
 }
 
-func NewService(repo repository.Repository) Company {
-	return &company{repo: repo}
+// NewService returns the company service. Company stats are cached for statsCacheTTL,
+// not at all when it is zero.
+func NewService(repo repository.Repository, statsCacheTTL time.Duration) Company {
+	return &company{repo: repo, stats: newStatsCache(statsCacheTTL)}
 }
 
 func (s company) CreateCompany(c *gin.Context, companyReq models.Company) (models.Company, *errors.ErrorResponse) {
@@ -41,10 +76,15 @@ func (s company) CreateCompany(c *gin.Context, companyReq models.Company) (model
 		return models.Company{}, errors.ErrRecordAlreadyExistsForGivenName
 	}
 
+	if err = assignCompanyID(&companyReq); err != nil {
+		logger.Errorf("service: CreateCompany ID generation error: %s", err.Error())
+		return models.Company{}, errors.ErrInternalServerError
+	}
+
 	err = s.repo.CreateCompany(c, companyReq)
 	if err != nil {
 		logger.Errorf("service: CreateCompany name [%s] error: %s", companyReq.Name, err.Error())
-		return models.Company{}, errors.ErrUnableToCreateCompany
+		return models.Company{}, createCompanyError(err)
 	}
 
 	company, err := s.repo.GetCompany(c, companyReq.ID)
@@ -57,13 +97,76 @@ func (s company) CreateCompany(c *gin.Context, companyReq models.Company) (model
 	return company, nil
 }
 
-func (s company) GetCompany(c *gin.Context, id string) (models.Company, *errors.ErrorResponse) {
+// CreateCompanies validates every company against the JSON document it was decoded
+// from and inserts the valid ones in one transaction.
+// In atomic mode nothing is created unless every company can be created.
+func (s company) CreateCompanies(c *gin.Context, companiesReq []models.Company, documents []map[string]interface{}, atomic bool) (models.BulkResult, *errors.ErrorResponse) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Service").
+		WithField(constants.Method, "CreateCompanies")
+
+	result := models.BulkResult{Items: make([]models.BulkItemResult, len(companiesReq))}
+	var (
+		valid      []models.Company
+		validIndex []int
+	)
+	for i, companyReq := range companiesReq {
+		if err := assignCompanyID(&companyReq); err != nil {
+			logger.Errorf("service: CreateCompanies ID generation error: %s", err.Error())
+			return models.BulkResult{}, errors.ErrInternalServerError
+		}
+		result.Items[i] = models.BulkItemResult{Index: i, ID: companyReq.ID}
+		// an item may set registered to false and amount_of_employees to 0
+		if validationErr := utils.ValidateCompany(companyReq, documents[i]); validationErr != nil {
+			setBulkItemError(&result.Items[i], errors.ValidationFailed, validationErr.Error())
+			continue
+		}
+		valid = append(valid, companyReq)
+		validIndex = append(validIndex, i)
+	}
+
+	aborted := atomic && len(valid) < len(companiesReq)
+	if !aborted && len(valid) > 0 {
+		itemErrs, err := s.repo.CreateCompanies(c, valid, atomic)
+		if err != nil {
+			logger.Errorf("service: CreateCompanies error: %s", err.Error())
+			return models.BulkResult{}, errors.ErrUnableToCreateCompany
+		}
+		for j, itemErr := range itemErrs {
+			if itemErr != nil {
+				createErr := createCompanyError(itemErr)
+				setBulkItemError(&result.Items[validIndex[j]], createErr.ErrorCode, createErr.ErrorMessage)
+				aborted = atomic
+			}
+		}
+	}
+
+	for i := range result.Items {
+		item := &result.Items[i]
+		switch {
+		case item.Status == models.BulkItemFailed:
+			result.Failed++
+		case aborted:
+			item.Status = models.BulkItemSkipped
+		default:
+			item.Status = models.BulkItemCreated
+			result.Succeeded++
+		}
+	}
+
+	logger.Debugf("created %d of %d companies in bulk", result.Succeeded, len(companiesReq))
+	return result, nil
+}
+
+// GetCompany returns a company with only the given fields set, all of them when fields is empty.
+func (s company) GetCompany(c *gin.Context, id string, fields []string) (models.Company, *errors.ErrorResponse) {
 	logger := logging.GetLogger(c).
 		WithField(constants.ReqID, requestid.Get(c)).
 		WithField(constants.Interface, "Service").
 		WithField(constants.Method, "GetCompany")
 
-	company, err := s.repo.GetCompany(c, id)
+	company, err := s.repo.GetCompanyFields(c, id, fields)
 	if err != nil {
 		logger.Errorf("service: GetCompany ID [%s] error: %s", id, err.Error())
 		return models.Company{}, errors.ErrUnableToFetchCompany
@@ -73,7 +176,49 @@ func (s company) GetCompany(c *gin.Context, id string) (models.Company, *errors.
 	return company, nil
 }
 
-func (s company) DeleteCompany(c *gin.Context, id string) *errors.ErrorResponse {
+// GetCompanyAsOf returns a company as it was at asOf, with the revision it was read from.
+func (s company) GetCompanyAsOf(c *gin.Context, id string, asOf time.Time) (models.CompanyVersion, *errors.ErrorResponse) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Service").
+		WithField(constants.Method, "GetCompanyAsOf")
+
+	company, err := s.repo.GetCompanyAsOf(c, id, asOf)
+	if err == sql.ErrNoRows {
+		return models.CompanyVersion{}, errors.ErrNoCompanyRecordsFoundByID
+	}
+	if err != nil {
+		logger.Errorf("service: GetCompanyAsOf ID [%s] error: %s", id, err.Error())
+		return models.CompanyVersion{}, errors.ErrUnableToFetchCompany
+	}
+
+	logger.Debugf("fetched revision [%d] of company with ID: [%s]", company.Revision, id)
+	return company, nil
+}
+
+func (s company) GetCompanyByName(c *gin.Context, name string) (models.Company, *errors.ErrorResponse) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Service").
+		WithField(constants.Method, "GetCompanyByName")
+
+	company, err := s.repo.GetCompanyByName(c, name)
+	if err == sql.ErrNoRows {
+		return models.Company{}, errors.ErrNoCompanyRecordsFoundByName
+	}
+	if err != nil {
+		logger.Errorf("service: GetCompanyByName name [%s] error: %s", name, err.Error())
+		return models.Company{}, errors.ErrUnableToFetchCompany
+	}
+
+	logger.Debugf("fetched company with name: [%s]", name)
+	return company, nil
+}
+
+// DeleteCompany moves a company to the trash. A non-nil versions lists the versions the
+// client expects the company at, see UpdateCompany. A company with subsidiaries is only
+// deleted with cascade, which moves the subsidiaries to the trash as well.
+func (s company) DeleteCompany(c *gin.Context, id string, versions []int, cascade bool) *errors.ErrorResponse {
 	logger := logging.GetLogger(c).
 		WithField(constants.ReqID, requestid.Get(c)).
 		WithField(constants.Interface, "Service").
@@ -89,7 +234,13 @@ func (s company) DeleteCompany(c *gin.Context, id string) *errors.ErrorResponse
 		return errors.ErrNoCompanyRecordsFoundByID
 	}
 
-	err = s.repo.DeleteCompany(c, id)
+	err = s.repo.DeleteCompany(c, id, versions, cascade)
+	if err == repository.ErrVersionMismatch {
+		return errors.ErrPreconditionFailed
+	}
+	if errResp := hierarchyError(err); errResp != nil {
+		return errResp
+	}
 	if err != nil {
 		logger.Errorf("service: DeleteCompany ID [%s] error: %s", id, err.Error())
 		return errors.ErrUnableToDeleteCompany
@@ -99,12 +250,228 @@ func (s company) DeleteCompany(c *gin.Context, id string) *errors.ErrorResponse
 	return nil
 }
 
-func (s company) UpdateCompany(c *gin.Context, id string, updateReq map[string]interface{}) (models.Company, *errors.ErrorResponse) {
+func (s company) ListDeletedCompanies(c *gin.Context, limit, offset int) ([]models.DeletedCompany, *errors.ErrorResponse) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Service").
+		WithField(constants.Method, "ListDeletedCompanies")
+
+	companies, err := s.repo.ListDeletedCompanies(c, limit, offset)
+	if err != nil {
+		logger.Errorf("service: ListDeletedCompanies error: %s", err.Error())
+		return nil, errors.ErrUnableToListCompanies
+	}
+
+	logger.Debugf("listed %d deleted companies", len(companies))
+	return companies, nil
+}
+
+// RestoreCompany takes a soft deleted company out of the trash, unless another
+// company took its name in the meantime.
+func (s company) RestoreCompany(c *gin.Context, id string) (models.Company, *errors.ErrorResponse) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Service").
+		WithField(constants.Method, "RestoreCompany")
+
+	err := s.repo.RestoreCompany(c, id)
+	switch {
+	case err == sql.ErrNoRows:
+		return models.Company{}, errors.ErrNoDeletedCompanyFoundByID
+	case err == repository.ErrCompanyNameExists:
+		return models.Company{}, errors.ErrRecordAlreadyExistsForGivenName
+	case hierarchyError(err) != nil:
+		return models.Company{}, hierarchyError(err)
+	case err != nil:
+		logger.Errorf("service: RestoreCompany ID [%s] error: %s", id, err.Error())
+		return models.Company{}, errors.ErrUnableToRestoreCompany
+	}
+
+	company, err := s.repo.GetCompany(c, id)
+	if err != nil {
+		logger.Errorf("service: GetCompany ID [%s] error: %s", id, err.Error())
+		return models.Company{}, errors.ErrInternalServerError
+	}
+
+	logger.Debugf("restored company with ID: [%s]", id)
+	return company, nil
+}
+
+// GetCompanyHistory returns one page of the revisions of a company, newest first.
+// Deleted companies keep their history until they are purged.
+func (s company) GetCompanyHistory(c *gin.Context, id string, limit, offset int) (models.CompanyRevisionPage, *errors.ErrorResponse) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Service").
+		WithField(constants.Method, "GetCompanyHistory")
+
+	page, err := s.repo.GetCompanyHistory(c, id, limit, offset)
+	if err != nil {
+		logger.Errorf("service: GetCompanyHistory ID [%s] error: %s", id, err.Error())
+		return models.CompanyRevisionPage{}, errors.ErrUnableToFetchCompanyHistory
+	}
+	if page.TotalCount == 0 {
+		return models.CompanyRevisionPage{}, errors.ErrNoCompanyRecordsFoundByID
+	}
+
+	logger.Debugf("found %d revisions for ID: [%s]", len(page.Data), id)
+	return page, nil
+}
+
+// GetCompaniesHistory returns up to limit of the latest revisions of each of the given
+// companies, keyed by company ID. Every company has an entry, empty when it has no revision.
+func (s company) GetCompaniesHistory(c *gin.Context, ids []string, limit int) (map[string][]models.CompanyRevision, *errors.ErrorResponse) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Service").
+		WithField(constants.Method, "GetCompaniesHistory")
+
+	history := make(map[string][]models.CompanyRevision, len(ids))
+	for _, id := range ids {
+		history[id] = []models.CompanyRevision{}
+	}
+	if len(ids) == 0 {
+		return history, nil
+	}
+
+	revisions, err := s.repo.ListCompaniesRevisions(c, ids, limit)
+	if err != nil {
+		logger.Errorf("service: GetCompaniesHistory error: %s", err.Error())
+		return nil, errors.ErrUnableToFetchCompanyHistory
+	}
+	for _, revision := range revisions {
+		history[revision.CompanyID] = append(history[revision.CompanyID], revision)
+	}
+
+	logger.Debugf("found %d revisions of %d companies", len(revisions), len(ids))
+	return history, nil
+}
+
+// RevertCompany sets a company back to the state recorded in one of its revisions. The
+// snapshot is validated and applied like a PATCH, and recorded as a new revision that
+// points at the reverted one.
+func (s company) RevertCompany(c *gin.Context, id string, revision int) (models.Company, *errors.ErrorResponse) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Service").
+		WithField(constants.Method, "RevertCompany")
+
+	companyRevision, err := s.repo.GetCompanyRevision(c, id, revision)
+	switch {
+	case err == sql.ErrNoRows:
+		return models.Company{}, errors.ErrNoCompanyRevisionFound
+	case err != nil:
+		logger.Errorf("service: RevertCompany ID [%s] revision [%d] error: %s", id, revision, err.Error())
+		return models.Company{}, errors.ErrUnableToRevertCompany
+	}
+
+	var snapshot models.Company
+	if err := json.Unmarshal(companyRevision.Snapshot, &snapshot); err != nil {
+		logger.Errorf("service: RevertCompany ID [%s] revision [%d] snapshot error: %s", id, revision, err.Error())
+		return models.Company{}, errors.ErrUnableToRevertCompany
+	}
+	updateReq := map[string]interface{}{
+		"name":                snapshot.Name,
+		"description":         snapshot.Description,
+		"amount_of_employees": snapshot.AmountOfEmployees,
+		"registered":          snapshot.Registered,
+		"type":                snapshot.Type,
+		"parent_id":           snapshot.ParentID,
+	}
+	if _, validationErr := govalidator.ValidateMap(updateReq, utils.GetMapValidations()); validationErr != nil {
+		return models.Company{}, errors.NewErrorResponse(errors.ErrValidationFailed.HttpStatusCode, errors.ValidationFailed, validationErr.Error())
+	}
+
+	c.Set(constants.RevertedFrom, revision)
+	return s.UpdateCompany(c, id, updateReq, nil)
+}
+
+// GetCompanyAncestors returns the parent of a company and the parents above it up to the
+// root of its group, nearest first. A company without a parent has no ancestors.
+func (s company) GetCompanyAncestors(c *gin.Context, id string) ([]models.CompanyNode, *errors.ErrorResponse) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Service").
+		WithField(constants.Method, "GetCompanyAncestors")
+
+	exists, err := s.repo.CheckCompanyExistsByID(c, id)
+	if err != nil {
+		logger.Errorf("service: GetCompanyAncestors ID [%s] error: %s", id, err.Error())
+		return nil, errors.ErrInternalServerError
+	}
+	if !exists {
+		return nil, errors.ErrNoCompanyRecordsFoundByID
+	}
+
+	ancestors, err := s.repo.ListCompanyAncestors(c, id)
+	if err != nil {
+		logger.Errorf("service: GetCompanyAncestors ID [%s] error: %s", id, err.Error())
+		return nil, errors.ErrUnableToFetchCompanyHierarchy
+	}
+
+	logger.Debugf("found %d ancestors for ID: [%s]", len(ancestors), id)
+	return ancestors, nil
+}
+
+// ListCompanyChildren returns one page of the direct subsidiaries of a company.
+func (s company) ListCompanyChildren(c *gin.Context, id string, limit, offset int) ([]models.Company, *errors.ErrorResponse) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Service").
+		WithField(constants.Method, "ListCompanyChildren")
+
+	exists, err := s.repo.CheckCompanyExistsByID(c, id)
+	if err != nil {
+		logger.Errorf("service: ListCompanyChildren ID [%s] error: %s", id, err.Error())
+		return nil, errors.ErrInternalServerError
+	}
+	if !exists {
+		return nil, errors.ErrNoCompanyRecordsFoundByID
+	}
+
+	children, err := s.repo.ListCompanyChildren(c, id, limit, offset)
+	if err != nil {
+		logger.Errorf("service: ListCompanyChildren ID [%s] error: %s", id, err.Error())
+		return nil, errors.ErrUnableToFetchCompanyHierarchy
+	}
+
+	logger.Debugf("found %d children for ID: [%s]", len(children), id)
+	return children, nil
+}
+
+// GetCompanySubtree returns a company with all its subsidiaries nested under it.
+func (s company) GetCompanySubtree(c *gin.Context, id string) (models.CompanyTree, *errors.ErrorResponse) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Service").
+		WithField(constants.Method, "GetCompanySubtree")
+
+	nodes, err := s.repo.GetCompanySubtree(c, id)
+	if err == sql.ErrNoRows {
+		return models.CompanyTree{}, errors.ErrNoCompanyRecordsFoundByID
+	}
+	if err != nil {
+		logger.Errorf("service: GetCompanySubtree ID [%s] error: %s", id, err.Error())
+		return models.CompanyTree{}, errors.ErrUnableToFetchCompanyHierarchy
+	}
+
+	logger.Debugf("found %d subsidiaries for ID: [%s]", len(nodes)-1, id)
+	return buildCompanyTree(nodes), nil
+}
+
+// UpdateCompany applies a partial update to a company. A non-nil versions lists the
+// versions the client expects the company at, from an If-Match header. The update is
+// refused with ErrPreconditionFailed when the company was changed since.
+func (s company) UpdateCompany(c *gin.Context, id string, updateReq map[string]interface{}, versions []int) (models.Company, *errors.ErrorResponse) {
 	logger := logging.GetLogger(c).
 		WithField(constants.ReqID, requestid.Get(c)).
 		WithField(constants.Interface, "Service").
 		WithField(constants.Method, "UpdateCompany")
 
+	if _, ok := updateReq["id"]; ok {
+		return models.Company{}, errors.NewErrorResponse(errors.ErrValidationFailed.HttpStatusCode, errors.ValidationFailed, "id cannot be changed")
+	}
+
 	exists, err := s.repo.CheckCompanyExistsByID(c, id)
 	if err != nil {
 		logger.Errorf("service: UpdateCompany ID [%s] error: %s", id, err.Error())
@@ -115,7 +482,19 @@ func (s company) UpdateCompany(c *gin.Context, id string, updateReq map[string]i
 		return models.Company{}, errors.ErrNoCompanyRecordsFoundByID
 	}
 
-	err = s.repo.UpdateCompany(c, updateReq, id)
+	err = s.repo.UpdateCompany(c, updateReq, id, versions)
+	if err == repository.ErrCompanyNameExists {
+		return models.Company{}, errors.ErrRecordAlreadyExistsForGivenName
+	}
+	if errResp := hierarchyError(err); errResp != nil {
+		return models.Company{}, errResp
+	}
+	if err == repository.ErrVersionMismatch {
+		return models.Company{}, errors.ErrPreconditionFailed
+	}
+	if err == sql.ErrNoRows {
+		return models.Company{}, errors.ErrNoCompanyRecordsFoundByID
+	}
 	if err != nil {
 		logger.Errorf("service: UpdateCompany ID [%s] error: %s", id, err.Error())
 		return models.Company{}, errors.ErrUnableToUpdateCompany
@@ -130,3 +509,442 @@ func (s company) UpdateCompany(c *gin.Context, id string, updateReq map[string]i
 	logger.Debugf("updated company with ID: [%s]", id)
 	return company, nil
 }
+
+// PatchCompany applies a JSON Merge Patch or JSON Patch to the company as GetCompany returns
+// it. The patched document must pass the models.Company rules, then the fields it changes
+// are written on the condition that the company is still at the version that was patched.
+func (s company) PatchCompany(c *gin.Context, id string, p patch.Patch, versions []int) (models.Company, *errors.ErrorResponse) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Service").
+		WithField(constants.Method, "PatchCompany")
+
+	company, err := s.repo.GetCompany(c, id)
+	if err == sql.ErrNoRows {
+		return models.Company{}, errors.ErrNoCompanyRecordsFoundByID
+	}
+	if err != nil {
+		logger.Errorf("service: PatchCompany ID [%s] error: %s", id, err.Error())
+		return models.Company{}, errors.ErrUnableToFetchCompany
+	}
+	if versions != nil && !containsVersion(versions, company.Version) {
+		return models.Company{}, errors.ErrPreconditionFailed
+	}
+
+	document, err := json.Marshal(company)
+	if err != nil {
+		logger.Errorf("service: PatchCompany ID [%s] error: %s", id, err.Error())
+		return models.Company{}, errors.ErrUnableToUpdateCompany
+	}
+	patched, err := p.Apply(document)
+	if err == patch.ErrTestFailed {
+		return models.Company{}, errors.ErrPatchTestFailed
+	}
+	if err != nil {
+		return models.Company{}, errors.NewErrorResponse(errors.ErrUnprocessablePatch.HttpStatusCode, errors.UnprocessablePatch, err.Error())
+	}
+
+	var patchedCompany models.Company
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&patchedCompany); err != nil {
+		return models.Company{}, errors.NewErrorResponse(errors.ErrValidationFailed.HttpStatusCode, errors.ValidationFailed, err.Error())
+	}
+	if patchedCompany.ID != company.ID || patchedCompany.Version != company.Version ||
+		!patchedCompany.CreatedAt.Equal(company.CreatedAt) || !patchedCompany.UpdatedAt.Equal(company.UpdatedAt) {
+		return models.Company{}, errors.NewErrorResponse(errors.ErrValidationFailed.HttpStatusCode, errors.ValidationFailed,
+			"id, version, created_at and updated_at cannot be changed")
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(patched, &fields); err != nil {
+		logger.Errorf("service: PatchCompany ID [%s] error: %s", id, err.Error())
+		return models.Company{}, errors.ErrUnableToUpdateCompany
+	}
+	if validationErr := utils.ValidateCompany(patchedCompany, fields); validationErr != nil {
+		return models.Company{}, errors.NewErrorResponse(errors.ErrValidationFailed.HttpStatusCode, errors.ValidationFailed, validationErr.Error())
+	}
+
+	updateReq := changedFields(company, patchedCompany)
+	if len(updateReq) == 0 {
+		return company, nil
+	}
+	return s.UpdateCompany(c, id, updateReq, []int{company.Version})
+}
+
+// ReplaceCompany overwrites every writable field of a company with the given ones, or
+// creates the company when no company has its ID. It reports whether the company was
+// created. A non-nil versions, from an If-Match header, never matches a missing company,
+// and neither does mustExist, from If-Match: *. A company in the trash is not replaced.
+func (s company) ReplaceCompany(c *gin.Context, companyReq models.Company, versions []int, mustExist bool) (models.Company, bool, *errors.ErrorResponse) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Service").
+		WithField(constants.Method, "ReplaceCompany")
+
+	exists, err := s.repo.CheckCompanyExistsByID(c, companyReq.ID)
+	if err != nil {
+		logger.Errorf("service: ReplaceCompany ID [%s] error: %s", companyReq.ID, err.Error())
+		return models.Company{}, false, errors.ErrInternalServerError
+	}
+
+	if !exists {
+		if versions != nil || mustExist {
+			return models.Company{}, false, errors.ErrPreconditionFailed
+		}
+		company, errResp := s.CreateCompany(c, companyReq)
+		// no live company has the ID, so the company holding it is in the trash
+		if errResp == errors.ErrRecordAlreadyExistsForGivenID {
+			return models.Company{}, false, errors.ErrCompanyInTrash
+		}
+		return company, errResp == nil, errResp
+	}
+
+	updateReq := map[string]interface{}{
+		"name":                companyReq.Name,
+		"description":         companyReq.Description,
+		"amount_of_employees": companyReq.AmountOfEmployees,
+		"registered":          companyReq.Registered,
+		"type":                companyReq.Type,
+		"parent_id":           companyReq.ParentID,
+	}
+	company, errResp := s.UpdateCompany(c, companyReq.ID, updateReq, versions)
+	return company, false, errResp
+}
+
+// changedFields returns the writable fields that differ between two states of a company.
+func changedFields(before, after models.Company) map[string]interface{} {
+	fields := map[string]interface{}{}
+	if after.Name != before.Name {
+		fields["name"] = after.Name
+	}
+	if after.Description != before.Description {
+		fields["description"] = after.Description
+	}
+	if after.AmountOfEmployees != before.AmountOfEmployees {
+		fields["amount_of_employees"] = after.AmountOfEmployees
+	}
+	if after.Registered != before.Registered {
+		fields["registered"] = after.Registered
+	}
+	if after.Type != before.Type {
+		fields["type"] = after.Type
+	}
+	if (after.ParentID == nil) != (before.ParentID == nil) || (after.ParentID != nil && *after.ParentID != *before.ParentID) {
+		fields["parent_id"] = after.ParentID
+	}
+	return fields
+}
+
+func containsVersion(versions []int, version int) bool {
+	for _, v := range versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// GetCompanyStats aggregates the companies matching the query, answering from the stats
+// cache while the stats computed for the same query are fresh.
+func (s company) GetCompanyStats(c *gin.Context, query models.CompanyStatsQuery) (models.CompanyStats, *errors.ErrorResponse) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Service").
+		WithField(constants.Method, "GetCompanyStats")
+
+	key, err := json.Marshal(query)
+	if err != nil {
+		logger.Errorf("service: GetCompanyStats error: %s", err.Error())
+		return models.CompanyStats{}, errors.ErrUnableToComputeCompanyStats
+	}
+	if stats, ok := s.stats.get(string(key)); ok {
+		logger.Debugf("served cached stats computed at %s", stats.ComputedAt)
+		return stats, nil
+	}
+
+	stats, err := s.repo.GetCompanyStats(c, query)
+	if err != nil {
+		logger.Errorf("service: GetCompanyStats error: %s", err.Error())
+		return models.CompanyStats{}, errors.ErrUnableToComputeCompanyStats
+	}
+	if stats.TotalCount > 0 {
+		stats.RegisteredRatio = float64(stats.RegisteredCount) / float64(stats.TotalCount)
+	}
+	stats.ComputedAt = utils.Now()
+	s.stats.set(string(key), stats)
+
+	logger.Debugf("computed stats of %d companies", stats.TotalCount)
+	return stats, nil
+}
+
+func (s company) UpdateCompanies(c *gin.Context, selector models.CompanySelector, updateReq map[string]interface{}) (models.BulkChangeResult, *errors.ErrorResponse) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Service").
+		WithField(constants.Method, "UpdateCompanies")
+
+	rowsAffected, err := s.repo.UpdateCompanies(c, selector, updateReq)
+	if err == repository.ErrCompanyNameExists {
+		return models.BulkChangeResult{}, errors.ErrRecordAlreadyExistsForGivenName
+	}
+	if errResp := hierarchyError(err); errResp != nil {
+		return models.BulkChangeResult{}, errResp
+	}
+	if err != nil {
+		logger.Errorf("service: UpdateCompanies error: %s", err.Error())
+		return models.BulkChangeResult{}, errors.ErrUnableToUpdateCompany
+	}
+
+	logger.Debugf("updated %d companies", rowsAffected)
+	return models.BulkChangeResult{RowsAffected: rowsAffected}, nil
+}
+
+func (s company) DeleteCompanies(c *gin.Context, selector models.CompanySelector) (models.BulkChangeResult, *errors.ErrorResponse) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Service").
+		WithField(constants.Method, "DeleteCompanies")
+
+	rowsAffected, err := s.repo.DeleteCompanies(c, selector)
+	if errResp := hierarchyError(err); errResp != nil {
+		return models.BulkChangeResult{}, errResp
+	}
+	if err != nil {
+		logger.Errorf("service: DeleteCompanies error: %s", err.Error())
+		return models.BulkChangeResult{}, errors.ErrUnableToDeleteCompany
+	}
+
+	logger.Debugf("deleted %d companies", rowsAffected)
+	return models.BulkChangeResult{RowsAffected: rowsAffected}, nil
+}
+
+func (s company) ListCompanies(c *gin.Context, query models.CompanyListQuery) (models.CompanyPage, *errors.ErrorResponse) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Service").
+		WithField(constants.Method, "ListCompanies")
+
+	page, err := s.repo.ListCompanies(c, query)
+	if err != nil {
+		logger.Errorf("service: ListCompanies error: %s", err.Error())
+		return models.CompanyPage{}, errors.ErrUnableToListCompanies
+	}
+
+	logger.Debugf("listed %d companies", len(page.Data))
+	return page, nil
+}
+
+func (s company) ListCompaniesAsOf(c *gin.Context, query models.CompanyListQuery) (models.CompanyVersionPage, *errors.ErrorResponse) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Service").
+		WithField(constants.Method, "ListCompaniesAsOf")
+
+	page, err := s.repo.ListCompaniesAsOf(c, query)
+	if err != nil {
+		logger.Errorf("service: ListCompaniesAsOf error: %s", err.Error())
+		return models.CompanyVersionPage{}, errors.ErrUnableToListCompanies
+	}
+
+	logger.Debugf("listed %d companies as of [%s]", len(page.Data), page.AsOf)
+	return page, nil
+}
+
+func (s company) SearchCompanies(c *gin.Context, text string, limit, offset int) ([]models.CompanySearchResult, *errors.ErrorResponse) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Service").
+		WithField(constants.Method, "SearchCompanies")
+
+	results, err := s.repo.SearchCompanies(c, text, limit, offset)
+	if err != nil {
+		logger.Errorf("service: SearchCompanies text [%s] error: %s", text, err.Error())
+		return nil, errors.ErrUnableToSearchCompanies
+	}
+
+	logger.Debugf("found %d companies for text: [%s]", len(results), text)
+	return results, nil
+}
+
+func (s company) SuggestCompanies(c *gin.Context, prefix string, limit int) ([]models.CompanySuggestion, *errors.ErrorResponse) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Service").
+		WithField(constants.Method, "SuggestCompanies")
+
+	suggestions, err := s.repo.SuggestCompanies(c, prefix, limit)
+	if err != nil {
+		logger.Errorf("service: SuggestCompanies prefix [%s] error: %s", prefix, err.Error())
+		return nil, errors.ErrUnableToSuggestCompanies
+	}
+
+	logger.Debugf("found %d suggestions for prefix: [%s]", len(suggestions), prefix)
+	return suggestions, nil
+}
+
+// ImportCompanies validates and writes the decoded companies one row at a time.
+// A bad row is added to the report and the import goes on with the next one,
+// only an unreadable input stops it.
+func (s company) ImportCompanies(c *gin.Context, decoder codec.CompanyDecoder, onConflict string) (models.ImportReport, *errors.ErrorResponse) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Service").
+		WithField(constants.Method, "ImportCompanies")
+
+	report := models.ImportReport{Errors: []models.ImportLineError{}}
+	for {
+		companyReq, fields, line, err := decoder.Decode()
+		if err == io.EOF {
+			break
+		}
+		if rowErr, ok := err.(*codec.RowError); ok {
+			report.Processed++
+			addImportError(&report, line, "", errors.InvalidImportFile, rowErr.Err.Error())
+			continue
+		}
+		if err != nil {
+			logger.Errorf("service: ImportCompanies line [%d] error: %s", line, err.Error())
+			addImportError(&report, line, "", errors.InvalidImportFile, err.Error())
+			break
+		}
+
+		report.Processed++
+		if onConflict == models.ImportConflictOverwrite && companyReq.ID == "" {
+			// a row without an ID overwrites the company with the same name, ignoring case
+			existing, err := s.repo.GetCompanyByName(c, companyReq.Name)
+			if err != nil && err != sql.ErrNoRows {
+				logger.Errorf("service: ImportCompanies line [%d] error: %s", line, err.Error())
+				return report, errors.ErrUnableToImportCompanies
+			}
+			companyReq.ID = existing.ID
+		}
+		if err = assignCompanyID(&companyReq); err != nil {
+			logger.Errorf("service: ImportCompanies ID generation error: %s", err.Error())
+			return report, errors.ErrUnableToImportCompanies
+		}
+		// a row may set registered to false and amount_of_employees to 0
+		if validationErr := utils.ValidateCompany(companyReq, fields); validationErr != nil {
+			addImportError(&report, line, companyReq.ID, errors.ValidationFailed, validationErr.Error())
+			continue
+		}
+
+		status, err := s.repo.UpsertCompany(c, companyReq, onConflict)
+		if err != nil {
+			if c.Request.Context().Err() != nil {
+				logger.Errorf("service: ImportCompanies line [%d] error: %s", line, err.Error())
+				return report, errors.ErrUnableToImportCompanies
+			}
+			createErr := createCompanyError(err)
+			addImportError(&report, line, companyReq.ID, createErr.ErrorCode, createErr.ErrorMessage)
+			continue
+		}
+		switch status {
+		case models.BulkItemCreated:
+			report.Created++
+		case models.BulkItemUpdated:
+			report.Updated++
+		case models.BulkItemSkipped:
+			report.Skipped++
+		}
+	}
+
+	logger.Debugf("imported %d companies, %d updated, %d skipped, %d failed", report.Created, report.Updated, report.Skipped, report.Failed)
+	return report, nil
+}
+
+// ExportCompanies encodes every company matching the query, flushing the encoder
+// every constants.ExportFlushSize rows so the response is streamed.
+func (s company) ExportCompanies(c *gin.Context, query models.CompanyListQuery, encoder codec.CompanyEncoder) *errors.ErrorResponse {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Service").
+		WithField(constants.Method, "ExportCompanies")
+
+	rows := 0
+	err := s.repo.ExportCompanies(c, query, func(company models.Company) error {
+		if err := encoder.Encode(company); err != nil {
+			return err
+		}
+		rows++
+		if rows%constants.ExportFlushSize == 0 {
+			return encoder.Flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = encoder.Close()
+	}
+	if err != nil {
+		logger.Errorf("service: ExportCompanies after %d rows error: %s", rows, err.Error())
+		return errors.ErrUnableToExportCompanies
+	}
+
+	logger.Debugf("exported %d companies", rows)
+	return nil
+}
+
+// assignCompanyID gives a company created without an ID one generated by the server.
+func assignCompanyID(company *models.Company) error {
+	if company.ID != "" {
+		return nil
+	}
+	var err error
+	company.ID, err = utils.NewCompanyID()
+	return err
+}
+
+func setBulkItemError(item *models.BulkItemResult, code errors.ErrorCode, message string) {
+	item.Status = models.BulkItemFailed
+	item.ErrorCode = string(code)
+	item.ErrorMessage = message
+}
+
+// createCompanyError maps a repository write error to the API error reported for it.
+func createCompanyError(err error) *errors.ErrorResponse {
+	switch err {
+	case repository.ErrCompanyNameExists:
+		return errors.ErrRecordAlreadyExistsForGivenName
+	case repository.ErrCompanyIDExists:
+		return errors.ErrRecordAlreadyExistsForGivenID
+	case repository.ErrCompanyInTrash:
+		return errors.ErrCompanyInTrash
+	}
+	if errResp := hierarchyError(err); errResp != nil {
+		return errResp
+	}
+	return errors.ErrUnableToCreateCompany
+}
+
+// hierarchyError maps a repository error breaking the company hierarchy to the API error
+// reported for it, nil for any other error.
+func hierarchyError(err error) *errors.ErrorResponse {
+	switch err {
+	case repository.ErrParentNotFound:
+		return errors.ErrParentCompanyNotFound
+	case repository.ErrHierarchyCycle:
+		return errors.ErrCompanyHierarchyCycle
+	case repository.ErrCompanyHasSubsidiaries:
+		return errors.ErrCompanyHasSubsidiaries
+	}
+	return nil
+}
+
+// buildCompanyTree nests the nodes of a subtree, listed level by level from its root,
+// under their parents.
+func buildCompanyTree(nodes []models.CompanyNode) models.CompanyTree {
+	trees := make(map[string]*models.CompanyTree, len(nodes))
+	for i, node := range nodes {
+		tree := &models.CompanyTree{Company: node.Company, Subsidiaries: []*models.CompanyTree{}}
+		trees[node.ID] = tree
+		if i > 0 {
+			parent := trees[*node.ParentID]
+			parent.Subsidiaries = append(parent.Subsidiaries, tree)
+		}
+	}
+	return *trees[nodes[0].ID]
+}
+
+func addImportError(report *models.ImportReport, line int, id string, code errors.ErrorCode, message string) {
+	report.Failed++
+	report.Errors = append(report.Errors, models.ImportLineError{Line: line, ID: id, ErrorCode: string(code), ErrorMessage: message})
+}
@@ -1,15 +1,25 @@
 package service
 
 import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/asaskevich/govalidator"
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
+	"github.com/kumareswaramoorthi/companies/api/codec"
+	"github.com/kumareswaramoorthi/companies/api/constants"
 	er "github.com/kumareswaramoorthi/companies/api/errors"
 	"github.com/kumareswaramoorthi/companies/api/models"
+	"github.com/kumareswaramoorthi/companies/api/patch"
+	"github.com/kumareswaramoorthi/companies/api/repository"
 	"github.com/kumareswaramoorthi/companies/api/repository/mocks"
 	"github.com/stretchr/testify/suite"
 )
@@ -29,7 +39,7 @@ func TestCompanyService(t *testing.T) {
 func (suite *CompanyServiceTestSuite) SetupTest() {
 	suite.mockCtrl = gomock.NewController(suite.T())
 	suite.mockCompanyRepository = mocks.NewMockRepository(suite.mockCtrl)
-	suite.CompanyService = NewService(suite.mockCompanyRepository)
+	suite.CompanyService = NewService(suite.mockCompanyRepository, 0)
 	suite.context, _ = gin.CreateTestContext(httptest.NewRecorder())
 	suite.context.Request, _ = http.NewRequest("GET", "", nil)
 
@@ -46,43 +56,86 @@ func (suite *CompanyServiceTestSuite) TestGetCompanySuccess() {
 		Registered:        true,
 		Type:              "Corporations"}
 
-	suite.mockCompanyRepository.EXPECT().GetCompany(suite.context, id).Return(expectedCompany, nil)
-	company, err := suite.CompanyService.GetCompany(suite.context, id)
+	suite.mockCompanyRepository.EXPECT().GetCompanyFields(suite.context, id, gomock.Nil()).Return(expectedCompany, nil)
+	company, err := suite.CompanyService.GetCompany(suite.context, id, nil)
 	suite.Nil(err)
 	suite.Equal(expectedCompany, company)
 }
 
 func (suite *CompanyServiceTestSuite) TestGetCompanyFail() {
-	suite.mockCompanyRepository.EXPECT().GetCompany(suite.context, id).Return(models.Company{}, errors.New("something went wrong"))
-	_, err := suite.CompanyService.GetCompany(suite.context, id)
+	suite.mockCompanyRepository.EXPECT().GetCompanyFields(suite.context, id, gomock.Nil()).Return(models.Company{}, errors.New("something went wrong"))
+	_, err := suite.CompanyService.GetCompany(suite.context, id, nil)
 	suite.NotNil(err)
 }
 
+func (suite *CompanyServiceTestSuite) TestGetCompaniesHistoryGroupsRevisionsByCompany() {
+	otherID := "9b2e5a4c-1f3d-4e8a-9c7b-2d6f8e0a1b3c"
+	revisions := []models.CompanyRevision{
+		{CompanyID: id, Revision: 2, Operation: "update"},
+		{CompanyID: id, Revision: 1, Operation: "create"},
+	}
+	suite.mockCompanyRepository.EXPECT().ListCompaniesRevisions(suite.context, []string{id, otherID}, 20).Return(revisions, nil)
+	history, err := suite.CompanyService.GetCompaniesHistory(suite.context, []string{id, otherID}, 20)
+	suite.Nil(err)
+	suite.Equal(revisions, history[id])
+	suite.NotNil(history[otherID])
+	suite.Empty(history[otherID])
+}
+
+func (suite *CompanyServiceTestSuite) TestGetCompanyByNameSuccess() {
+	var expectedCompany models.Company = models.Company{
+		ID:                id,
+		Name:              "xyz",
+		Description:       "test company",
+		AmountOfEmployees: 100,
+		Registered:        true,
+		Type:              "Corporations"}
+
+	suite.mockCompanyRepository.EXPECT().GetCompanyByName(suite.context, "XYZ").Return(expectedCompany, nil)
+	company, err := suite.CompanyService.GetCompanyByName(suite.context, "XYZ")
+	suite.Nil(err)
+	suite.Equal(expectedCompany, company)
+}
+
+func (suite *CompanyServiceTestSuite) TestGetCompanyByNameFailIfNameNonExists() {
+	suite.mockCompanyRepository.EXPECT().GetCompanyByName(suite.context, "xyz").Return(models.Company{}, sql.ErrNoRows)
+	_, err := suite.CompanyService.GetCompanyByName(suite.context, "xyz")
+	suite.NotNil(err)
+	suite.Equal(err, er.ErrNoCompanyRecordsFoundByName)
+}
+
+func (suite *CompanyServiceTestSuite) TestGetCompanyByNameFailIfDBErr() {
+	suite.mockCompanyRepository.EXPECT().GetCompanyByName(suite.context, "xyz").Return(models.Company{}, errors.New("something went wrong"))
+	_, err := suite.CompanyService.GetCompanyByName(suite.context, "xyz")
+	suite.NotNil(err)
+	suite.Equal(err, er.ErrUnableToFetchCompany)
+}
+
 func (suite *CompanyServiceTestSuite) TestDeleteCompanySuccess() {
 	suite.mockCompanyRepository.EXPECT().CheckCompanyExistsByID(suite.context, id).Return(true, nil)
-	suite.mockCompanyRepository.EXPECT().DeleteCompany(suite.context, id).Return(nil)
-	err := suite.CompanyService.DeleteCompany(suite.context, id)
+	suite.mockCompanyRepository.EXPECT().DeleteCompany(suite.context, id, gomock.Nil(), false).Return(nil)
+	err := suite.CompanyService.DeleteCompany(suite.context, id, nil, false)
 	suite.Nil(err)
 }
 
 func (suite *CompanyServiceTestSuite) TestDeleteCompanyFailIfIDNonExists() {
 	suite.mockCompanyRepository.EXPECT().CheckCompanyExistsByID(suite.context, id).Return(false, nil)
-	err := suite.CompanyService.DeleteCompany(suite.context, id)
+	err := suite.CompanyService.DeleteCompany(suite.context, id, nil, false)
 	suite.NotNil(err)
 	suite.Equal(err, er.ErrNoCompanyRecordsFoundByID)
 }
 
 func (suite *CompanyServiceTestSuite) TestDeleteCompanyFailIfDBErr() {
 	suite.mockCompanyRepository.EXPECT().CheckCompanyExistsByID(suite.context, id).Return(true, nil)
-	suite.mockCompanyRepository.EXPECT().DeleteCompany(suite.context, id).Return(er.ErrUnableToDeleteCompany)
-	err := suite.CompanyService.DeleteCompany(suite.context, id)
+	suite.mockCompanyRepository.EXPECT().DeleteCompany(suite.context, id, gomock.Nil(), false).Return(er.ErrUnableToDeleteCompany)
+	err := suite.CompanyService.DeleteCompany(suite.context, id, nil, false)
 	suite.NotNil(err)
 	suite.Equal(err, er.ErrUnableToDeleteCompany)
 }
 
 func (suite *CompanyServiceTestSuite) TestDeleteCompanyFailIfCheckErr() {
 	suite.mockCompanyRepository.EXPECT().CheckCompanyExistsByID(suite.context, id).Return(false, er.ErrInternalServerError)
-	err := suite.CompanyService.DeleteCompany(suite.context, id)
+	err := suite.CompanyService.DeleteCompany(suite.context, id, nil, false)
 	suite.NotNil(err)
 	suite.Equal(err, er.ErrInternalServerError)
 }
@@ -99,9 +152,9 @@ func (suite *CompanyServiceTestSuite) TestUpdateCompanySuccess() {
 	req := make(map[string]interface{})
 	req["name"] = "xyz"
 	suite.mockCompanyRepository.EXPECT().CheckCompanyExistsByID(suite.context, id).Return(true, nil)
-	suite.mockCompanyRepository.EXPECT().UpdateCompany(suite.context, req, id).Return(nil)
+	suite.mockCompanyRepository.EXPECT().UpdateCompany(suite.context, req, id, gomock.Nil()).Return(nil)
 	suite.mockCompanyRepository.EXPECT().GetCompany(suite.context, id).Return(expectedCompany, nil)
-	company, err := suite.CompanyService.UpdateCompany(suite.context, id, req)
+	company, err := suite.CompanyService.UpdateCompany(suite.context, id, req, nil)
 	suite.Nil(err)
 	suite.Equal(expectedCompany, company)
 }
@@ -110,7 +163,7 @@ func (suite *CompanyServiceTestSuite) TestUpdateCompanyFailIfDBErr() {
 	req := make(map[string]interface{})
 	req["name"] = "xyz"
 	suite.mockCompanyRepository.EXPECT().CheckCompanyExistsByID(suite.context, id).Return(false, errors.New("something went wrong"))
-	_, err := suite.CompanyService.UpdateCompany(suite.context, id, req)
+	_, err := suite.CompanyService.UpdateCompany(suite.context, id, req, nil)
 	suite.NotNil(err)
 	suite.Equal(err, er.ErrInternalServerError)
 }
@@ -119,7 +172,7 @@ func (suite *CompanyServiceTestSuite) TestUpdateCompanyFailIfIDNonExists() {
 	req := make(map[string]interface{})
 	req["name"] = "xyz"
 	suite.mockCompanyRepository.EXPECT().CheckCompanyExistsByID(suite.context, id).Return(false, nil)
-	_, err := suite.CompanyService.UpdateCompany(suite.context, id, req)
+	_, err := suite.CompanyService.UpdateCompany(suite.context, id, req, nil)
 	suite.NotNil(err)
 	suite.Equal(err, er.ErrNoCompanyRecordsFoundByID)
 }
@@ -128,23 +181,156 @@ func (suite *CompanyServiceTestSuite) TestUpdateCompanyFailIfDBUpdateErr() {
 	req := make(map[string]interface{})
 	req["name"] = "xyz"
 	suite.mockCompanyRepository.EXPECT().CheckCompanyExistsByID(suite.context, id).Return(true, nil)
-	suite.mockCompanyRepository.EXPECT().UpdateCompany(suite.context, req, id).Return(errors.New("something went wrong"))
-	_, err := suite.CompanyService.UpdateCompany(suite.context, id, req)
+	suite.mockCompanyRepository.EXPECT().UpdateCompany(suite.context, req, id, gomock.Nil()).Return(errors.New("something went wrong"))
+	_, err := suite.CompanyService.UpdateCompany(suite.context, id, req, nil)
 	suite.NotNil(err)
 	suite.Equal(err, er.ErrUnableToUpdateCompany)
 }
 
+func (suite *CompanyServiceTestSuite) TestUpdateCompanyFailIfDeletedMeanwhile() {
+	req := map[string]interface{}{"name": "xyz"}
+	suite.mockCompanyRepository.EXPECT().CheckCompanyExistsByID(suite.context, id).Return(true, nil)
+	suite.mockCompanyRepository.EXPECT().UpdateCompany(suite.context, req, id, gomock.Nil()).Return(sql.ErrNoRows)
+	_, err := suite.CompanyService.UpdateCompany(suite.context, id, req, nil)
+	suite.NotNil(err)
+	suite.Equal(err, er.ErrNoCompanyRecordsFoundByID)
+}
+
 func (suite *CompanyServiceTestSuite) TestUpdateCompanyFailIfDBFetchErr() {
 	req := make(map[string]interface{})
 	req["name"] = "xyz"
 	suite.mockCompanyRepository.EXPECT().CheckCompanyExistsByID(suite.context, id).Return(true, nil)
-	suite.mockCompanyRepository.EXPECT().UpdateCompany(suite.context, req, id).Return(nil)
+	suite.mockCompanyRepository.EXPECT().UpdateCompany(suite.context, req, id, gomock.Nil()).Return(nil)
 	suite.mockCompanyRepository.EXPECT().GetCompany(suite.context, id).Return(models.Company{}, errors.New("something went wrong"))
-	_, err := suite.CompanyService.UpdateCompany(suite.context, id, req)
+	_, err := suite.CompanyService.UpdateCompany(suite.context, id, req, nil)
 	suite.NotNil(err)
 	suite.Equal(err, er.ErrInternalServerError)
 }
 
+func (suite *CompanyServiceTestSuite) TestPatchCompanyMergePatchClearsDescription() {
+	current := models.Company{
+		ID:                id,
+		Name:              "xyz",
+		Description:       "test company",
+		AmountOfEmployees: 100,
+		Registered:        false,
+		Type:              "Corporations",
+		Version:           3}
+	updated := current
+	updated.Description = ""
+	updated.Version = 4
+
+	companyPatch, err := patch.NewPatch(patch.MergePatchContentType, []byte(`{"description": null}`))
+	suite.Nil(err)
+	suite.mockCompanyRepository.EXPECT().GetCompany(suite.context, id).Return(current, nil)
+	suite.mockCompanyRepository.EXPECT().CheckCompanyExistsByID(suite.context, id).Return(true, nil)
+	suite.mockCompanyRepository.EXPECT().UpdateCompany(suite.context, map[string]interface{}{"description": ""}, id, []int{3}).Return(nil)
+	suite.mockCompanyRepository.EXPECT().GetCompany(suite.context, id).Return(updated, nil)
+	company, errResp := suite.CompanyService.PatchCompany(suite.context, id, companyPatch, []int{3})
+	suite.Nil(errResp)
+	suite.Equal(updated, company)
+}
+
+func (suite *CompanyServiceTestSuite) TestPatchCompanyJSONPatchFailsIfTestFails() {
+	current := models.Company{ID: id, Name: "xyz", AmountOfEmployees: 100, Registered: true, Type: "Corporations", Version: 3}
+
+	companyPatch, err := patch.NewPatch(patch.JSONPatchContentType, []byte(`[
+		{"op": "test", "path": "/type", "value": "NonProfit"},
+		{"op": "replace", "path": "/amount_of_employees", "value": 10}
+	]`))
+	suite.Nil(err)
+	suite.mockCompanyRepository.EXPECT().GetCompany(suite.context, id).Return(current, nil)
+	_, errResp := suite.CompanyService.PatchCompany(suite.context, id, companyPatch, nil)
+	suite.Equal(er.ErrPatchTestFailed, errResp)
+}
+
+func (suite *CompanyServiceTestSuite) TestPatchCompanyFailsIfDocumentIsInvalid() {
+	current := models.Company{ID: id, Name: "xyz", AmountOfEmployees: 100, Registered: true, Type: "Corporations", Version: 3}
+
+	companyPatch, err := patch.NewPatch(patch.JSONPatchContentType, []byte(`[{"op": "remove", "path": "/name"}]`))
+	suite.Nil(err)
+	suite.mockCompanyRepository.EXPECT().GetCompany(suite.context, id).Return(current, nil)
+	_, errResp := suite.CompanyService.PatchCompany(suite.context, id, companyPatch, nil)
+	suite.NotNil(errResp)
+	suite.Equal(er.ErrValidationFailed.ErrorCode, errResp.ErrorCode)
+}
+
+func (suite *CompanyServiceTestSuite) TestPatchCompanyFailsIfVersionMismatch() {
+	current := models.Company{ID: id, Name: "xyz", AmountOfEmployees: 100, Registered: true, Type: "Corporations", Version: 3}
+
+	companyPatch, err := patch.NewPatch(patch.MergePatchContentType, []byte(`{"name": "abc"}`))
+	suite.Nil(err)
+	suite.mockCompanyRepository.EXPECT().GetCompany(suite.context, id).Return(current, nil)
+	_, errResp := suite.CompanyService.PatchCompany(suite.context, id, companyPatch, []int{2})
+	suite.Equal(er.ErrPreconditionFailed, errResp)
+}
+
+func (suite *CompanyServiceTestSuite) TestReplaceCompanyOverwritesEveryField() {
+	req := models.Company{ID: id, Name: "xyz", AmountOfEmployees: 100, Registered: true, Type: "Corporations"}
+	updated := req
+	updated.Version = 4
+
+	fields := map[string]interface{}{
+		"name":                "xyz",
+		"description":         "",
+		"amount_of_employees": 100,
+		"registered":          true,
+		"type":                "Corporations",
+		"parent_id":           (*string)(nil),
+	}
+	suite.mockCompanyRepository.EXPECT().CheckCompanyExistsByID(suite.context, id).Return(true, nil).Times(2)
+	suite.mockCompanyRepository.EXPECT().UpdateCompany(suite.context, fields, id, []int{3}).Return(nil)
+	suite.mockCompanyRepository.EXPECT().GetCompany(suite.context, id).Return(updated, nil)
+	company, created, err := suite.CompanyService.ReplaceCompany(suite.context, req, []int{3}, false)
+	suite.Nil(err)
+	suite.False(created)
+	suite.Equal(updated, company)
+}
+
+func (suite *CompanyServiceTestSuite) TestReplaceCompanyCreatesMissingCompany() {
+	req := models.Company{ID: id, Name: "xyz", AmountOfEmployees: 100, Registered: true, Type: "Corporations"}
+	createdCompany := req
+	createdCompany.Version = 1
+
+	suite.mockCompanyRepository.EXPECT().CheckCompanyExistsByID(suite.context, id).Return(false, nil)
+	suite.mockCompanyRepository.EXPECT().CheckCompanyExistsByName(suite.context, req.Name).Return(false, nil)
+	suite.mockCompanyRepository.EXPECT().CreateCompany(suite.context, req).Return(nil)
+	suite.mockCompanyRepository.EXPECT().GetCompany(suite.context, id).Return(createdCompany, nil)
+	company, created, err := suite.CompanyService.ReplaceCompany(suite.context, req, nil, false)
+	suite.Nil(err)
+	suite.True(created)
+	suite.Equal(createdCompany, company)
+}
+
+func (suite *CompanyServiceTestSuite) TestReplaceCompanyFailsIfMissingCompanyIsMatched() {
+	req := models.Company{ID: id, Name: "xyz", AmountOfEmployees: 100, Registered: true, Type: "Corporations"}
+
+	suite.mockCompanyRepository.EXPECT().CheckCompanyExistsByID(suite.context, id).Return(false, nil)
+	_, created, err := suite.CompanyService.ReplaceCompany(suite.context, req, []int{1}, false)
+	suite.False(created)
+	suite.Equal(er.ErrPreconditionFailed, err)
+}
+
+func (suite *CompanyServiceTestSuite) TestReplaceCompanyFailsIfAnyVersionOfMissingCompanyIsMatched() {
+	req := models.Company{ID: id, Name: "xyz", AmountOfEmployees: 100, Registered: true, Type: "Corporations"}
+
+	suite.mockCompanyRepository.EXPECT().CheckCompanyExistsByID(suite.context, id).Return(false, nil)
+	_, created, err := suite.CompanyService.ReplaceCompany(suite.context, req, nil, true)
+	suite.False(created)
+	suite.Equal(er.ErrPreconditionFailed, err)
+}
+
+func (suite *CompanyServiceTestSuite) TestReplaceCompanyInTrash() {
+	req := models.Company{ID: id, Name: "xyz", AmountOfEmployees: 100, Registered: true, Type: "Corporations"}
+
+	suite.mockCompanyRepository.EXPECT().CheckCompanyExistsByID(suite.context, id).Return(false, nil)
+	suite.mockCompanyRepository.EXPECT().CheckCompanyExistsByName(suite.context, req.Name).Return(false, nil)
+	suite.mockCompanyRepository.EXPECT().CreateCompany(suite.context, req).Return(repository.ErrCompanyIDExists)
+	_, created, err := suite.CompanyService.ReplaceCompany(suite.context, req, nil, false)
+	suite.False(created)
+	suite.Equal(er.ErrCompanyInTrash, err)
+}
+
 func (suite *CompanyServiceTestSuite) TestCreateCompanyFailsIfNameExists() {
 	var req models.Company = models.Company{
 		ID:                id,
@@ -174,3 +360,588 @@ func (suite *CompanyServiceTestSuite) TestCreateCompanyFailsIfNameCheckErr() {
 	suite.NotNil(err)
 	suite.Equal(err, er.ErrInternalServerError)
 }
+
+func (suite *CompanyServiceTestSuite) TestCreateCompanyGeneratesID() {
+	suite.T().Setenv("ID_SCHEME", "uuidv7")
+	var req models.Company = models.Company{
+		Name:              "xyz",
+		AmountOfEmployees: 100,
+		Registered:        true,
+		Type:              "Corporations"}
+
+	var created models.Company
+	suite.mockCompanyRepository.EXPECT().CheckCompanyExistsByName(suite.context, req.Name).Return(false, nil)
+	suite.mockCompanyRepository.EXPECT().CreateCompany(suite.context, gomock.Any()).
+		DoAndReturn(func(c *gin.Context, company models.Company) error {
+			created = company
+			return nil
+		})
+	suite.mockCompanyRepository.EXPECT().GetCompany(suite.context, gomock.Any()).
+		DoAndReturn(func(c *gin.Context, companyID string) (models.Company, error) {
+			return created, nil
+		})
+	company, err := suite.CompanyService.CreateCompany(suite.context, req)
+	suite.Nil(err)
+	suite.True(govalidator.IsUUID(company.ID))
+	suite.Equal(byte('7'), company.ID[14])
+}
+
+func (suite *CompanyServiceTestSuite) TestCreateCompanyFailsIfIDExists() {
+	var req models.Company = models.Company{
+		ID:                id,
+		Name:              "xyz",
+		AmountOfEmployees: 100,
+		Registered:        true,
+		Type:              "Corporations"}
+
+	suite.mockCompanyRepository.EXPECT().CheckCompanyExistsByName(suite.context, req.Name).Return(false, nil)
+	suite.mockCompanyRepository.EXPECT().CreateCompany(suite.context, req).Return(repository.ErrCompanyIDExists)
+	_, err := suite.CompanyService.CreateCompany(suite.context, req)
+	suite.NotNil(err)
+	suite.Equal(err, er.ErrRecordAlreadyExistsForGivenID)
+}
+
+func (suite *CompanyServiceTestSuite) TestListCompaniesSuccess() {
+	query := models.CompanyListQuery{SortBy: "id", Limit: 20}
+	expectedPage := models.CompanyPage{
+		Data: []models.Company{{
+			ID:                id,
+			Name:              "xyz",
+			Description:       "test company",
+			AmountOfEmployees: 100,
+			Registered:        true,
+			Type:              "Corporations"}},
+		TotalCount: 1,
+	}
+
+	suite.mockCompanyRepository.EXPECT().ListCompanies(suite.context, query).Return(expectedPage, nil)
+	page, err := suite.CompanyService.ListCompanies(suite.context, query)
+	suite.Nil(err)
+	suite.Equal(expectedPage, page)
+}
+
+func (suite *CompanyServiceTestSuite) TestListCompaniesFailIfDBErr() {
+	query := models.CompanyListQuery{SortBy: "id", Limit: 20}
+	suite.mockCompanyRepository.EXPECT().ListCompanies(suite.context, query).Return(models.CompanyPage{}, errors.New("something went wrong"))
+	_, err := suite.CompanyService.ListCompanies(suite.context, query)
+	suite.NotNil(err)
+	suite.Equal(err, er.ErrUnableToListCompanies)
+}
+
+func (suite *CompanyServiceTestSuite) TestSearchCompaniesSuccess() {
+	expectedResults := []models.CompanySearchResult{{
+		Company: models.Company{ID: id, Name: "xyz", Description: "solar panel maker"},
+		Rank:    0.6,
+		Snippet: "<b>solar</b> panel maker",
+	}}
+
+	suite.mockCompanyRepository.EXPECT().SearchCompanies(suite.context, "solar", 20, 0).Return(expectedResults, nil)
+	results, err := suite.CompanyService.SearchCompanies(suite.context, "solar", 20, 0)
+	suite.Nil(err)
+	suite.Equal(expectedResults, results)
+}
+
+func (suite *CompanyServiceTestSuite) TestSearchCompaniesFailIfDBErr() {
+	suite.mockCompanyRepository.EXPECT().SearchCompanies(suite.context, "solar", 20, 0).Return(nil, errors.New("something went wrong"))
+	_, err := suite.CompanyService.SearchCompanies(suite.context, "solar", 20, 0)
+	suite.NotNil(err)
+	suite.Equal(err, er.ErrUnableToSearchCompanies)
+}
+
+func (suite *CompanyServiceTestSuite) TestSuggestCompaniesSuccess() {
+	expectedSuggestions := []models.CompanySuggestion{{ID: id, Name: "xyz", Score: 0.5}}
+
+	suite.mockCompanyRepository.EXPECT().SuggestCompanies(suite.context, "xy", 10).Return(expectedSuggestions, nil)
+	suggestions, err := suite.CompanyService.SuggestCompanies(suite.context, "xy", 10)
+	suite.Nil(err)
+	suite.Equal(expectedSuggestions, suggestions)
+}
+
+func (suite *CompanyServiceTestSuite) TestSuggestCompaniesFailIfDBErr() {
+	suite.mockCompanyRepository.EXPECT().SuggestCompanies(suite.context, "xy", 10).Return(nil, errors.New("something went wrong"))
+	_, err := suite.CompanyService.SuggestCompanies(suite.context, "xy", 10)
+	suite.NotNil(err)
+	suite.Equal(err, er.ErrUnableToSuggestCompanies)
+}
+
+// bulkDocuments returns the JSON documents a client sends for the companies.
+func bulkDocuments(companies []models.Company) []map[string]interface{} {
+	documents := make([]map[string]interface{}, len(companies))
+	for i, company := range companies {
+		body, _ := json.Marshal(company)
+		_ = json.Unmarshal(body, &documents[i])
+	}
+	return documents
+}
+
+func (suite *CompanyServiceTestSuite) TestCreateCompaniesSuccess() {
+	companies := []models.Company{
+		{ID: id, Name: "xyz", AmountOfEmployees: 100, Registered: true, Type: "Corporations"},
+		{ID: "9b2e5a4c-1f3d-4e8a-9c7b-2d6f8e0a1b3c", Name: "abc", AmountOfEmployees: 10, Registered: true, Type: "NonProfit"},
+	}
+
+	suite.mockCompanyRepository.EXPECT().CreateCompanies(suite.context, companies, true).Return([]error{nil, nil}, nil)
+	result, err := suite.CompanyService.CreateCompanies(suite.context, companies, bulkDocuments(companies), true)
+	suite.Nil(err)
+	suite.Equal(2, result.Succeeded)
+	suite.Equal(0, result.Failed)
+	suite.Equal(models.BulkItemCreated, result.Items[1].Status)
+}
+
+func (suite *CompanyServiceTestSuite) TestCreateCompaniesAcceptsFalseAndZero() {
+	companies := []models.Company{
+		{ID: id, Name: "xyz", AmountOfEmployees: 0, Registered: false, Type: "Corporations"},
+		{ID: "9b2e5a4c-1f3d-4e8a-9c7b-2d6f8e0a1b3c", Name: "abc", Type: "NonProfit"},
+	}
+	documents := []map[string]interface{}{
+		{"id": id, "name": "xyz", "amount_of_employees": float64(0), "registered": false, "type": "Corporations"},
+		{"id": "9b2e5a4c-1f3d-4e8a-9c7b-2d6f8e0a1b3c", "name": "abc", "type": "NonProfit"},
+	}
+
+	suite.mockCompanyRepository.EXPECT().CreateCompanies(suite.context, companies[:1], false).Return([]error{nil}, nil)
+	result, err := suite.CompanyService.CreateCompanies(suite.context, companies, documents, false)
+	suite.Nil(err)
+	suite.Equal(1, result.Succeeded)
+	suite.Equal(models.BulkItemCreated, result.Items[0].Status)
+	suite.Equal(models.BulkItemFailed, result.Items[1].Status)
+	suite.Equal(string(er.ValidationFailed), result.Items[1].ErrorCode)
+}
+
+func (suite *CompanyServiceTestSuite) TestCreateCompaniesAtomicSkipsAllIfOneInvalid() {
+	companies := []models.Company{
+		{ID: id, Name: "xyz", AmountOfEmployees: 100, Registered: true, Type: "Corporations"},
+		{ID: "not-a-uuid", Name: "abc", AmountOfEmployees: 10, Registered: true, Type: "NonProfit"},
+	}
+
+	result, err := suite.CompanyService.CreateCompanies(suite.context, companies, bulkDocuments(companies), true)
+	suite.Nil(err)
+	suite.Equal(0, result.Succeeded)
+	suite.Equal(1, result.Failed)
+	suite.Equal(models.BulkItemSkipped, result.Items[0].Status)
+	suite.Equal(models.BulkItemFailed, result.Items[1].Status)
+	suite.Equal(string(er.ValidationFailed), result.Items[1].ErrorCode)
+}
+
+func (suite *CompanyServiceTestSuite) TestCreateCompaniesPartialReportsFailedItems() {
+	companies := []models.Company{
+		{ID: id, Name: "xyz", AmountOfEmployees: 100, Registered: true, Type: "Corporations"},
+		{ID: "not-a-uuid", Name: "abc", AmountOfEmployees: 10, Registered: true, Type: "NonProfit"},
+		{ID: "9b2e5a4c-1f3d-4e8a-9c7b-2d6f8e0a1b3c", Name: "xyz", AmountOfEmployees: 10, Registered: true, Type: "NonProfit"},
+	}
+
+	suite.mockCompanyRepository.EXPECT().CreateCompanies(suite.context, []models.Company{companies[0], companies[2]}, false).
+		Return([]error{nil, repository.ErrCompanyNameExists}, nil)
+	result, err := suite.CompanyService.CreateCompanies(suite.context, companies, bulkDocuments(companies), false)
+	suite.Nil(err)
+	suite.Equal(1, result.Succeeded)
+	suite.Equal(2, result.Failed)
+	suite.Equal(models.BulkItemCreated, result.Items[0].Status)
+	suite.Equal(string(er.RecordAlreadyExistsForGivenName), result.Items[2].ErrorCode)
+}
+
+func (suite *CompanyServiceTestSuite) TestCreateCompaniesFailIfDBErr() {
+	companies := []models.Company{{ID: id, Name: "xyz", AmountOfEmployees: 100, Registered: true, Type: "Corporations"}}
+
+	suite.mockCompanyRepository.EXPECT().CreateCompanies(suite.context, companies, true).Return(nil, errors.New("something went wrong"))
+	_, err := suite.CompanyService.CreateCompanies(suite.context, companies, bulkDocuments(companies), true)
+	suite.NotNil(err)
+	suite.Equal(err, er.ErrUnableToCreateCompany)
+}
+
+func (suite *CompanyServiceTestSuite) TestUpdateCompaniesSuccess() {
+	selector := models.CompanySelector{IDs: []string{id}}
+	req := map[string]interface{}{"registered": false}
+
+	suite.mockCompanyRepository.EXPECT().UpdateCompanies(suite.context, selector, req).Return(int64(1), nil)
+	result, err := suite.CompanyService.UpdateCompanies(suite.context, selector, req)
+	suite.Nil(err)
+	suite.Equal(models.BulkChangeResult{RowsAffected: 1}, result)
+}
+
+func (suite *CompanyServiceTestSuite) TestUpdateCompaniesFailIfNameExists() {
+	selector := models.CompanySelector{IDs: []string{id}}
+	req := map[string]interface{}{"name": "xyz"}
+
+	suite.mockCompanyRepository.EXPECT().UpdateCompanies(suite.context, selector, req).Return(int64(0), repository.ErrCompanyNameExists)
+	_, err := suite.CompanyService.UpdateCompanies(suite.context, selector, req)
+	suite.NotNil(err)
+	suite.Equal(err, er.ErrRecordAlreadyExistsForGivenName)
+}
+
+func (suite *CompanyServiceTestSuite) TestDeleteCompaniesSuccess() {
+	selector := models.CompanySelector{Filter: models.CompanyFilter{Types: []string{"NonProfit"}}}
+
+	suite.mockCompanyRepository.EXPECT().DeleteCompanies(suite.context, selector).Return(int64(3), nil)
+	result, err := suite.CompanyService.DeleteCompanies(suite.context, selector)
+	suite.Nil(err)
+	suite.Equal(models.BulkChangeResult{RowsAffected: 3}, result)
+}
+
+func (suite *CompanyServiceTestSuite) TestDeleteCompaniesFailIfDBErr() {
+	selector := models.CompanySelector{IDs: []string{id}}
+
+	suite.mockCompanyRepository.EXPECT().DeleteCompanies(suite.context, selector).Return(int64(0), errors.New("something went wrong"))
+	_, err := suite.CompanyService.DeleteCompanies(suite.context, selector)
+	suite.NotNil(err)
+	suite.Equal(err, er.ErrUnableToDeleteCompany)
+}
+
+func (suite *CompanyServiceTestSuite) TestImportCompaniesReportsBadLines() {
+	input := `id,name,amount_of_employees,registered,type
+041d2027-e6fa-4d6d-836d-eedb235c82bc,xyz,100,true,Corporations
+9b2e5a4c-1f3d-4e8a-9c7b-2d6f8e0a1b3c,abc,many,true,NonProfit
+not-a-uuid,def,10,true,NonProfit
+9b2e5a4c-1f3d-4e8a-9c7b-2d6f8e0a1b3c,ghi,10,true,Cooperative
+`
+	decoder, decodeErr := codec.NewCompanyDecoder(codec.CSV, strings.NewReader(input))
+	suite.Nil(decodeErr)
+
+	first := models.Company{ID: id, Name: "xyz", AmountOfEmployees: 100, Registered: true, Type: "Corporations"}
+	last := models.Company{ID: "9b2e5a4c-1f3d-4e8a-9c7b-2d6f8e0a1b3c", Name: "ghi", AmountOfEmployees: 10, Registered: true, Type: "Cooperative"}
+	suite.mockCompanyRepository.EXPECT().UpsertCompany(suite.context, first, models.ImportConflictSkip).Return(models.BulkItemCreated, nil)
+	suite.mockCompanyRepository.EXPECT().UpsertCompany(suite.context, last, models.ImportConflictSkip).Return(models.BulkItemSkipped, nil)
+
+	report, err := suite.CompanyService.ImportCompanies(suite.context, decoder, models.ImportConflictSkip)
+	suite.Nil(err)
+	suite.Equal(4, report.Processed)
+	suite.Equal(1, report.Created)
+	suite.Equal(1, report.Skipped)
+	suite.Equal(2, report.Failed)
+	suite.Equal(3, report.Errors[0].Line)
+	suite.Equal(string(er.InvalidImportFile), report.Errors[0].ErrorCode)
+	suite.Equal(4, report.Errors[1].Line)
+	suite.Equal(string(er.ValidationFailed), report.Errors[1].ErrorCode)
+}
+
+func (suite *CompanyServiceTestSuite) TestImportCompaniesAcceptsFalseAndZero() {
+	input := `id,name,amount_of_employees,registered,type
+041d2027-e6fa-4d6d-836d-eedb235c82bc,xyz,0,false,Corporations
+9b2e5a4c-1f3d-4e8a-9c7b-2d6f8e0a1b3c,abc,,,NonProfit
+`
+	decoder, decodeErr := codec.NewCompanyDecoder(codec.CSV, strings.NewReader(input))
+	suite.Nil(decodeErr)
+
+	company := models.Company{ID: id, Name: "xyz", AmountOfEmployees: 0, Registered: false, Type: "Corporations"}
+	suite.mockCompanyRepository.EXPECT().UpsertCompany(suite.context, company, models.ImportConflictSkip).Return(models.BulkItemCreated, nil)
+
+	report, err := suite.CompanyService.ImportCompanies(suite.context, decoder, models.ImportConflictSkip)
+	suite.Nil(err)
+	suite.Equal(2, report.Processed)
+	suite.Equal(1, report.Created)
+	suite.Equal(1, report.Failed)
+	suite.Equal(3, report.Errors[0].Line)
+	suite.Equal(string(er.ValidationFailed), report.Errors[0].ErrorCode)
+}
+
+func (suite *CompanyServiceTestSuite) TestImportCompaniesReportsConflicts() {
+	input := `{"id": "041d2027-e6fa-4d6d-836d-eedb235c82bc", "name": "xyz", "amount_of_employees": 100, "registered": true, "type": "Corporations"}
+
+{"id": 
+`
+	decoder, decodeErr := codec.NewCompanyDecoder(codec.NDJSON, strings.NewReader(input))
+	suite.Nil(decodeErr)
+
+	company := models.Company{ID: id, Name: "xyz", AmountOfEmployees: 100, Registered: true, Type: "Corporations"}
+	suite.mockCompanyRepository.EXPECT().UpsertCompany(suite.context, company, models.ImportConflictFail).Return("", repository.ErrCompanyNameExists)
+
+	report, err := suite.CompanyService.ImportCompanies(suite.context, decoder, models.ImportConflictFail)
+	suite.Nil(err)
+	suite.Equal(2, report.Processed)
+	suite.Equal(2, report.Failed)
+	suite.Equal(models.ImportLineError{Line: 1, ID: id, ErrorCode: string(er.RecordAlreadyExistsForGivenName), ErrorMessage: er.ErrRecordAlreadyExistsForGivenName.ErrorMessage}, report.Errors[0])
+	suite.Equal(3, report.Errors[1].Line)
+}
+
+func (suite *CompanyServiceTestSuite) TestImportCompaniesOverwritesByName() {
+	input := `name,amount_of_employees,registered,type
+XYZ,200,true,Corporations
+abc,10,true,NonProfit
+`
+	decoder, decodeErr := codec.NewCompanyDecoder(codec.CSV, strings.NewReader(input))
+	suite.Nil(decodeErr)
+
+	existing := models.Company{ID: id, Name: "xyz", AmountOfEmployees: 100, Registered: true, Type: "Corporations"}
+	suite.mockCompanyRepository.EXPECT().GetCompanyByName(suite.context, "XYZ").Return(existing, nil)
+	suite.mockCompanyRepository.EXPECT().GetCompanyByName(suite.context, "abc").Return(models.Company{}, sql.ErrNoRows)
+	suite.mockCompanyRepository.EXPECT().UpsertCompany(suite.context, models.Company{ID: id, Name: "XYZ", AmountOfEmployees: 200, Registered: true, Type: "Corporations"}, models.ImportConflictOverwrite).
+		Return(models.BulkItemUpdated, nil)
+	suite.mockCompanyRepository.EXPECT().UpsertCompany(suite.context, gomock.Any(), models.ImportConflictOverwrite).
+		DoAndReturn(func(c *gin.Context, company models.Company, onConflict string) (string, error) {
+			suite.NotEmpty(company.ID)
+			suite.NotEqual(id, company.ID)
+			return models.BulkItemCreated, nil
+		})
+
+	report, err := suite.CompanyService.ImportCompanies(suite.context, decoder, models.ImportConflictOverwrite)
+	suite.Nil(err)
+	suite.Equal(1, report.Updated)
+	suite.Equal(1, report.Created)
+	suite.Equal(0, report.Failed)
+}
+
+func (suite *CompanyServiceTestSuite) TestImportCompaniesReportsTrashedCompany() {
+	input := `{"id": "041d2027-e6fa-4d6d-836d-eedb235c82bc", "name": "xyz", "amount_of_employees": 100, "registered": true, "type": "Corporations"}
+`
+	decoder, decodeErr := codec.NewCompanyDecoder(codec.NDJSON, strings.NewReader(input))
+	suite.Nil(decodeErr)
+
+	company := models.Company{ID: id, Name: "xyz", AmountOfEmployees: 100, Registered: true, Type: "Corporations"}
+	suite.mockCompanyRepository.EXPECT().UpsertCompany(suite.context, company, models.ImportConflictOverwrite).Return("", repository.ErrCompanyInTrash)
+
+	report, err := suite.CompanyService.ImportCompanies(suite.context, decoder, models.ImportConflictOverwrite)
+	suite.Nil(err)
+	suite.Equal(1, report.Failed)
+	suite.Equal(string(er.CompanyInTrash), report.Errors[0].ErrorCode)
+}
+
+func (suite *CompanyServiceTestSuite) TestExportCompaniesWritesCSV() {
+	query := models.CompanyListQuery{SortBy: "id"}
+	var out bytes.Buffer
+	encoder, encodeErr := codec.NewCompanyEncoder(codec.CSV, &out)
+	suite.Nil(encodeErr)
+
+	suite.mockCompanyRepository.EXPECT().ExportCompanies(suite.context, query, gomock.Any()).
+		DoAndReturn(func(c *gin.Context, query models.CompanyListQuery, fn func(models.Company) error) error {
+			return fn(models.Company{ID: id, Name: "xyz", Description: "a, b", AmountOfEmployees: 100, Registered: true, Type: "Corporations"})
+		})
+	err := suite.CompanyService.ExportCompanies(suite.context, query, encoder)
+	suite.Nil(err)
+	suite.Equal("id,name,description,amount_of_employees,registered,type,parent_id\n"+id+`,xyz,"a, b",100,true,Corporations,`+"\n", out.String())
+}
+
+func (suite *CompanyServiceTestSuite) TestExportCompaniesWritesEmptyJSONArray() {
+	query := models.CompanyListQuery{SortBy: "id"}
+	var out bytes.Buffer
+	encoder, encodeErr := codec.NewCompanyEncoder(codec.JSON, &out)
+	suite.Nil(encodeErr)
+
+	suite.mockCompanyRepository.EXPECT().ExportCompanies(suite.context, query, gomock.Any()).Return(nil)
+	err := suite.CompanyService.ExportCompanies(suite.context, query, encoder)
+	suite.Nil(err)
+	suite.Equal("[]\n", out.String())
+}
+
+func (suite *CompanyServiceTestSuite) TestExportCompaniesFailIfDBErr() {
+	query := models.CompanyListQuery{SortBy: "id"}
+	encoder, encodeErr := codec.NewCompanyEncoder(codec.NDJSON, &bytes.Buffer{})
+	suite.Nil(encodeErr)
+
+	suite.mockCompanyRepository.EXPECT().ExportCompanies(suite.context, query, gomock.Any()).Return(errors.New("something went wrong"))
+	err := suite.CompanyService.ExportCompanies(suite.context, query, encoder)
+	suite.NotNil(err)
+	suite.Equal(err, er.ErrUnableToExportCompanies)
+}
+
+func (suite *CompanyServiceTestSuite) TestRestoreCompanySuccess() {
+	expectedCompany := models.Company{ID: id, Name: "xyz", AmountOfEmployees: 100, Registered: true, Type: "Corporations"}
+
+	suite.mockCompanyRepository.EXPECT().RestoreCompany(suite.context, id).Return(nil)
+	suite.mockCompanyRepository.EXPECT().GetCompany(suite.context, id).Return(expectedCompany, nil)
+	company, err := suite.CompanyService.RestoreCompany(suite.context, id)
+	suite.Nil(err)
+	suite.Equal(expectedCompany, company)
+}
+
+func (suite *CompanyServiceTestSuite) TestRestoreCompanyFailIfNotInTrash() {
+	suite.mockCompanyRepository.EXPECT().RestoreCompany(suite.context, id).Return(sql.ErrNoRows)
+	_, err := suite.CompanyService.RestoreCompany(suite.context, id)
+	suite.NotNil(err)
+	suite.Equal(err, er.ErrNoDeletedCompanyFoundByID)
+}
+
+func (suite *CompanyServiceTestSuite) TestRestoreCompanyFailIfNameTaken() {
+	suite.mockCompanyRepository.EXPECT().RestoreCompany(suite.context, id).Return(repository.ErrCompanyNameExists)
+	_, err := suite.CompanyService.RestoreCompany(suite.context, id)
+	suite.NotNil(err)
+	suite.Equal(err, er.ErrRecordAlreadyExistsForGivenName)
+}
+
+func (suite *CompanyServiceTestSuite) TestGetCompanyHistorySuccess() {
+	expectedPage := models.CompanyRevisionPage{
+		Data:       []models.CompanyRevision{{CompanyID: id, Revision: 1, Operation: "create", ChangedFields: []string{"name"}}},
+		TotalCount: 1,
+	}
+
+	suite.mockCompanyRepository.EXPECT().GetCompanyHistory(suite.context, id, 20, 0).Return(expectedPage, nil)
+	page, err := suite.CompanyService.GetCompanyHistory(suite.context, id, 20, 0)
+	suite.Nil(err)
+	suite.Equal(expectedPage, page)
+}
+
+func (suite *CompanyServiceTestSuite) TestGetCompanyHistoryFailIfNoRevisions() {
+	suite.mockCompanyRepository.EXPECT().GetCompanyHistory(suite.context, id, 20, 0).Return(models.CompanyRevisionPage{Data: []models.CompanyRevision{}}, nil)
+	_, err := suite.CompanyService.GetCompanyHistory(suite.context, id, 20, 0)
+	suite.NotNil(err)
+	suite.Equal(err, er.ErrNoCompanyRecordsFoundByID)
+}
+
+func (suite *CompanyServiceTestSuite) TestRevertCompanySuccess() {
+	expectedCompany := models.Company{ID: id, Name: "xyz", Description: "test company", AmountOfEmployees: 100, Registered: true, Type: "Corporations"}
+	companyRevision := models.CompanyRevision{
+		CompanyID: id,
+		Revision:  1,
+		Snapshot:  []byte(`{"id":"` + id + `","name":"xyz","description":"test company","amount_of_employees":100,"registered":true,"type":"Corporations"}`),
+	}
+	req := map[string]interface{}{
+		"name":                "xyz",
+		"description":         "test company",
+		"amount_of_employees": 100,
+		"registered":          true,
+		"type":                "Corporations",
+		"parent_id":           (*string)(nil),
+	}
+
+	suite.mockCompanyRepository.EXPECT().GetCompanyRevision(suite.context, id, 1).Return(companyRevision, nil)
+	suite.mockCompanyRepository.EXPECT().CheckCompanyExistsByID(suite.context, id).Return(true, nil)
+	suite.mockCompanyRepository.EXPECT().UpdateCompany(suite.context, req, id, gomock.Nil()).Return(nil)
+	suite.mockCompanyRepository.EXPECT().GetCompany(suite.context, id).Return(expectedCompany, nil)
+	company, err := suite.CompanyService.RevertCompany(suite.context, id, 1)
+	suite.Nil(err)
+	suite.Equal(expectedCompany, company)
+	suite.Equal(1, suite.context.GetInt(constants.RevertedFrom))
+}
+
+func (suite *CompanyServiceTestSuite) TestRevertCompanyFailIfRevisionNonExists() {
+	suite.mockCompanyRepository.EXPECT().GetCompanyRevision(suite.context, id, 9).Return(models.CompanyRevision{}, sql.ErrNoRows)
+	_, err := suite.CompanyService.RevertCompany(suite.context, id, 9)
+	suite.NotNil(err)
+	suite.Equal(err, er.ErrNoCompanyRevisionFound)
+}
+
+func (suite *CompanyServiceTestSuite) TestRevertCompanyFailIfSnapshotInvalid() {
+	companyRevision := models.CompanyRevision{
+		CompanyID: id,
+		Revision:  1,
+		Snapshot:  []byte(`{"id":"` + id + `","name":"x","amount_of_employees":100,"registered":true,"type":"Corporations"}`),
+	}
+
+	suite.mockCompanyRepository.EXPECT().GetCompanyRevision(suite.context, id, 1).Return(companyRevision, nil)
+	_, err := suite.CompanyService.RevertCompany(suite.context, id, 1)
+	suite.NotNil(err)
+	suite.Equal(er.ValidationFailed, string(err.ErrorCode))
+}
+
+func (suite *CompanyServiceTestSuite) TestGetCompanyAsOfFailIfNotExisting() {
+	asOf := time.Date(2023, 1, 31, 0, 0, 0, 0, time.UTC)
+	suite.mockCompanyRepository.EXPECT().GetCompanyAsOf(suite.context, id, asOf).Return(models.CompanyVersion{}, sql.ErrNoRows)
+	_, err := suite.CompanyService.GetCompanyAsOf(suite.context, id, asOf)
+	suite.NotNil(err)
+	suite.Equal(err, er.ErrNoCompanyRecordsFoundByID)
+}
+
+func (suite *CompanyServiceTestSuite) TestListCompaniesAsOfFailIfDBErr() {
+	asOf := time.Date(2023, 1, 31, 0, 0, 0, 0, time.UTC)
+	query := models.CompanyListQuery{SortBy: "id", Limit: 20, AsOf: &asOf}
+	suite.mockCompanyRepository.EXPECT().ListCompaniesAsOf(suite.context, query).Return(models.CompanyVersionPage{}, errors.New("something went wrong"))
+	_, err := suite.CompanyService.ListCompaniesAsOf(suite.context, query)
+	suite.NotNil(err)
+	suite.Equal(err, er.ErrUnableToListCompanies)
+}
+
+func (suite *CompanyServiceTestSuite) TestUpdateCompanyFailIfVersionStale() {
+	req := map[string]interface{}{"name": "xyz"}
+	suite.mockCompanyRepository.EXPECT().CheckCompanyExistsByID(suite.context, id).Return(true, nil)
+	suite.mockCompanyRepository.EXPECT().UpdateCompany(suite.context, req, id, []int{3}).Return(repository.ErrVersionMismatch)
+	_, err := suite.CompanyService.UpdateCompany(suite.context, id, req, []int{3})
+	suite.NotNil(err)
+	suite.Equal(err, er.ErrPreconditionFailed)
+}
+
+func (suite *CompanyServiceTestSuite) TestDeleteCompanyFailIfVersionStale() {
+	suite.mockCompanyRepository.EXPECT().CheckCompanyExistsByID(suite.context, id).Return(true, nil)
+	suite.mockCompanyRepository.EXPECT().DeleteCompany(suite.context, id, []int{}, false).Return(repository.ErrVersionMismatch)
+	err := suite.CompanyService.DeleteCompany(suite.context, id, []int{}, false)
+	suite.NotNil(err)
+	suite.Equal(err, er.ErrPreconditionFailed)
+}
+
+func (suite *CompanyServiceTestSuite) TestGetCompanyStatsComputesRatio() {
+	query := models.CompanyStatsQuery{GroupBy: "type", Buckets: []int{0, 10}}
+	suite.mockCompanyRepository.EXPECT().GetCompanyStats(suite.context, query).
+		Return(models.CompanyStats{TotalCount: 4, RegisteredCount: 1, GroupBy: "type"}, nil)
+
+	stats, err := suite.CompanyService.GetCompanyStats(suite.context, query)
+	suite.Nil(err)
+	suite.Equal(0.25, stats.RegisteredRatio)
+	suite.False(stats.ComputedAt.IsZero())
+}
+
+func (suite *CompanyServiceTestSuite) TestGetCompanyStatsServedFromCache() {
+	service := NewService(suite.mockCompanyRepository, time.Minute)
+	query := models.CompanyStatsQuery{GroupBy: "registered", Buckets: []int{0, 10}}
+	suite.mockCompanyRepository.EXPECT().GetCompanyStats(suite.context, query).
+		Return(models.CompanyStats{TotalCount: 2, RegisteredCount: 2, GroupBy: "registered"}, nil).Times(1)
+
+	first, err := service.GetCompanyStats(suite.context, query)
+	suite.Nil(err)
+	second, err := service.GetCompanyStats(suite.context, query)
+	suite.Nil(err)
+	suite.Equal(first, second)
+}
+
+func (suite *CompanyServiceTestSuite) TestGetCompanyStatsFail() {
+	query := models.CompanyStatsQuery{GroupBy: "type"}
+	suite.mockCompanyRepository.EXPECT().GetCompanyStats(suite.context, query).Return(models.CompanyStats{}, errors.New("db error"))
+
+	_, err := suite.CompanyService.GetCompanyStats(suite.context, query)
+	suite.Equal(er.ErrUnableToComputeCompanyStats, err)
+}
+
+func (suite *CompanyServiceTestSuite) TestGetCompanySubtreeNestsSubsidiaries() {
+	childID := "9b2e5a4c-1f3d-4e8a-9c7b-2d6f8e0a1b3c"
+	grandchildID := "5f0c6a1e-8b7d-4c2a-9e3f-1a2b3c4d5e6f"
+	nodes := []models.CompanyNode{
+		{Company: models.Company{ID: id, Name: "group"}},
+		{Company: models.Company{ID: childID, Name: "child", ParentID: &id}, Depth: 1},
+		{Company: models.Company{ID: grandchildID, Name: "grandchild", ParentID: &childID}, Depth: 2},
+	}
+	suite.mockCompanyRepository.EXPECT().GetCompanySubtree(suite.context, id).Return(nodes, nil)
+
+	tree, err := suite.CompanyService.GetCompanySubtree(suite.context, id)
+	suite.Nil(err)
+	suite.Equal(id, tree.ID)
+	suite.Len(tree.Subsidiaries, 1)
+	suite.Equal(childID, tree.Subsidiaries[0].ID)
+	suite.Len(tree.Subsidiaries[0].Subsidiaries, 1)
+	suite.Equal(grandchildID, tree.Subsidiaries[0].Subsidiaries[0].ID)
+	suite.Empty(tree.Subsidiaries[0].Subsidiaries[0].Subsidiaries)
+}
+
+func (suite *CompanyServiceTestSuite) TestGetCompanySubtreeNotFound() {
+	suite.mockCompanyRepository.EXPECT().GetCompanySubtree(suite.context, id).Return(nil, sql.ErrNoRows)
+
+	_, err := suite.CompanyService.GetCompanySubtree(suite.context, id)
+	suite.Equal(er.ErrNoCompanyRecordsFoundByID, err)
+}
+
+func (suite *CompanyServiceTestSuite) TestGetCompanyAncestorsNotFound() {
+	suite.mockCompanyRepository.EXPECT().CheckCompanyExistsByID(suite.context, id).Return(false, nil)
+
+	_, err := suite.CompanyService.GetCompanyAncestors(suite.context, id)
+	suite.Equal(er.ErrNoCompanyRecordsFoundByID, err)
+}
+
+func (suite *CompanyServiceTestSuite) TestDeleteCompanyWithSubsidiaries() {
+	suite.mockCompanyRepository.EXPECT().CheckCompanyExistsByID(suite.context, id).Return(true, nil)
+	suite.mockCompanyRepository.EXPECT().DeleteCompany(suite.context, id, gomock.Nil(), false).Return(repository.ErrCompanyHasSubsidiaries)
+
+	err := suite.CompanyService.DeleteCompany(suite.context, id, nil, false)
+	suite.Equal(er.ErrCompanyHasSubsidiaries, err)
+}
+
+func (suite *CompanyServiceTestSuite) TestUpdateCompanyRejectsID() {
+	req := map[string]interface{}{"id": "9b2e5a4c-1f3d-4e8a-9c7b-2d6f8e0a1b3c"}
+
+	_, err := suite.CompanyService.UpdateCompany(suite.context, id, req, nil)
+	suite.Equal(er.ValidationFailed, string(err.ErrorCode))
+}
+
+func (suite *CompanyServiceTestSuite) TestUpdateCompanyRejectsHierarchyCycle() {
+	parentID := "9b2e5a4c-1f3d-4e8a-9c7b-2d6f8e0a1b3c"
+	req := map[string]interface{}{"parent_id": parentID}
+	suite.mockCompanyRepository.EXPECT().CheckCompanyExistsByID(suite.context, id).Return(true, nil)
+	suite.mockCompanyRepository.EXPECT().UpdateCompany(suite.context, req, id, gomock.Nil()).Return(repository.ErrHierarchyCycle)
+
+	_, err := suite.CompanyService.UpdateCompany(suite.context, id, req, nil)
+	suite.Equal(er.ErrCompanyHierarchyCycle, err)
+}
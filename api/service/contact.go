@@ -0,0 +1,212 @@
+package service
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+	"github.com/kumareswaramoorthi/companies/api/constants"
+	errors "github.com/kumareswaramoorthi/companies/api/errors"
+	"github.com/kumareswaramoorthi/companies/api/logging"
+	"github.com/kumareswaramoorthi/companies/api/models"
+	"github.com/kumareswaramoorthi/companies/api/repository"
+	"github.com/kumareswaramoorthi/companies/api/utils"
+)
+
+type Contact interface {
+	CreateContact(c *gin.Context, contact models.Contact) (models.Contact, *errors.ErrorResponse)
+	GetContact(c *gin.Context, companyID, id string) (models.Contact, *errors.ErrorResponse)
+	ListContacts(c *gin.Context, companyID string, limit, offset int) ([]models.Contact, *errors.ErrorResponse)
+	ReplaceContact(c *gin.Context, contact models.Contact) (models.Contact, *errors.ErrorResponse)
+	DeleteContact(c *gin.Context, companyID, id string) *errors.ErrorResponse
+	SearchContacts(c *gin.Context, text string, limit, offset int) ([]models.ContactSearchResult, *errors.ErrorResponse)
+}
+
+type contact struct {
+	repo        repository.ContactRepository
+	companyRepo repository.Repository
+}
+
+// NewContactService returns the service of the people at companies.
+func NewContactService(repo repository.ContactRepository, companyRepo repository.Repository) Contact {
+	return &contact{repo: repo, companyRepo: companyRepo}
+}
+
+func (s contact) CreateContact(c *gin.Context, contactReq models.Contact) (models.Contact, *errors.ErrorResponse) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Service").
+		WithField(constants.Method, "CreateContact")
+
+	contactReq, validationErr := normalizeContact(contactReq)
+	if validationErr != nil {
+		return models.Contact{}, errors.NewErrorResponse(errors.ErrValidationFailed.HttpStatusCode, errors.ValidationFailed, validationErr.Error())
+	}
+	if errResp := checkCompanyExists(c, s.companyRepo, contactReq.CompanyID, errors.ErrNoCompanyRecordsFoundByID); errResp != nil {
+		return models.Contact{}, errResp
+	}
+
+	var err error
+	if contactReq.ID, err = utils.NewCompanyID(); err != nil {
+		logger.Errorf("service: CreateContact ID generation error: %s", err.Error())
+		return models.Contact{}, errors.ErrInternalServerError
+	}
+
+	if err = s.repo.CreateContact(c, contactReq); err != nil {
+		logger.Errorf("service: CreateContact company ID [%s] error: %s", contactReq.CompanyID, err.Error())
+		return models.Contact{}, contactError(err)
+	}
+
+	created, err := s.repo.GetContact(c, contactReq.CompanyID, contactReq.ID)
+	if err != nil {
+		logger.Errorf("service: CreateContact ID [%s] error: %s", contactReq.ID, err.Error())
+		return models.Contact{}, errors.ErrUnableToFetchContacts
+	}
+
+	logger.Debugf("created contact with ID: [%s]", created.ID)
+	return created, nil
+}
+
+func (s contact) GetContact(c *gin.Context, companyID, id string) (models.Contact, *errors.ErrorResponse) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Service").
+		WithField(constants.Method, "GetContact")
+
+	if errResp := checkCompanyExists(c, s.companyRepo, companyID, errors.ErrNoCompanyRecordsFoundByID); errResp != nil {
+		return models.Contact{}, errResp
+	}
+
+	found, err := s.repo.GetContact(c, companyID, id)
+	if err == sql.ErrNoRows {
+		return models.Contact{}, errors.ErrNoContactFound
+	}
+	if err != nil {
+		logger.Errorf("service: GetContact ID [%s] error: %s", id, err.Error())
+		return models.Contact{}, errors.ErrUnableToFetchContacts
+	}
+
+	logger.Debugf("found contact with ID: [%s]", id)
+	return found, nil
+}
+
+// ListContacts returns one page of the contacts of a company, ordered by name.
+func (s contact) ListContacts(c *gin.Context, companyID string, limit, offset int) ([]models.Contact, *errors.ErrorResponse) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Service").
+		WithField(constants.Method, "ListContacts")
+
+	if errResp := checkCompanyExists(c, s.companyRepo, companyID, errors.ErrNoCompanyRecordsFoundByID); errResp != nil {
+		return nil, errResp
+	}
+
+	contacts, err := s.repo.ListContacts(c, companyID, limit, offset)
+	if err != nil {
+		logger.Errorf("service: ListContacts company ID [%s] error: %s", companyID, err.Error())
+		return nil, errors.ErrUnableToFetchContacts
+	}
+
+	logger.Debugf("found %d contacts of company with ID: [%s]", len(contacts), companyID)
+	return contacts, nil
+}
+
+// ReplaceContact replaces every field of a contact of a company.
+func (s contact) ReplaceContact(c *gin.Context, contactReq models.Contact) (models.Contact, *errors.ErrorResponse) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Service").
+		WithField(constants.Method, "ReplaceContact")
+
+	if errResp := checkCompanyExists(c, s.companyRepo, contactReq.CompanyID, errors.ErrNoCompanyRecordsFoundByID); errResp != nil {
+		return models.Contact{}, errResp
+	}
+
+	contactReq, validationErr := normalizeContact(contactReq)
+	if validationErr != nil {
+		return models.Contact{}, errors.NewErrorResponse(errors.ErrValidationFailed.HttpStatusCode, errors.ValidationFailed, validationErr.Error())
+	}
+
+	err := s.repo.UpdateContact(c, contactReq)
+	if err == sql.ErrNoRows {
+		return models.Contact{}, errors.ErrNoContactFound
+	}
+	if err != nil {
+		logger.Errorf("service: ReplaceContact ID [%s] error: %s", contactReq.ID, err.Error())
+		return models.Contact{}, contactError(err)
+	}
+
+	replaced, err := s.repo.GetContact(c, contactReq.CompanyID, contactReq.ID)
+	if err != nil {
+		logger.Errorf("service: ReplaceContact ID [%s] error: %s", contactReq.ID, err.Error())
+		return models.Contact{}, errors.ErrUnableToFetchContacts
+	}
+
+	logger.Debugf("replaced contact with ID: [%s]", replaced.ID)
+	return replaced, nil
+}
+
+func (s contact) DeleteContact(c *gin.Context, companyID, id string) *errors.ErrorResponse {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Service").
+		WithField(constants.Method, "DeleteContact")
+
+	if errResp := checkCompanyExists(c, s.companyRepo, companyID, errors.ErrNoCompanyRecordsFoundByID); errResp != nil {
+		return errResp
+	}
+
+	err := s.repo.DeleteContact(c, companyID, id)
+	if err == sql.ErrNoRows {
+		return errors.ErrNoContactFound
+	}
+	if err != nil {
+		logger.Errorf("service: DeleteContact ID [%s] error: %s", id, err.Error())
+		return errors.ErrUnableToSaveContact
+	}
+
+	logger.Debugf("deleted contact with ID: [%s]", id)
+	return nil
+}
+
+// SearchContacts returns one page of the contacts whose name, role or email contains text,
+// across all companies.
+func (s contact) SearchContacts(c *gin.Context, text string, limit, offset int) ([]models.ContactSearchResult, *errors.ErrorResponse) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Service").
+		WithField(constants.Method, "SearchContacts")
+
+	contacts, err := s.repo.SearchContacts(c, text, limit, offset)
+	if err != nil {
+		logger.Errorf("service: SearchContacts text [%s] error: %s", text, err.Error())
+		return nil, errors.ErrUnableToSearchContacts
+	}
+
+	logger.Debugf("found %d contacts matching [%s]", len(contacts), text)
+	return contacts, nil
+}
+
+// normalizeContact trims the fields of a contact and writes its phone in E.164 format,
+// then validates it.
+func normalizeContact(contactReq models.Contact) (models.Contact, error) {
+	contactReq.Name = strings.TrimSpace(contactReq.Name)
+	contactReq.Role = strings.TrimSpace(contactReq.Role)
+	contactReq.Email = strings.TrimSpace(contactReq.Email)
+	if _, err := govalidator.ValidateStruct(contactReq); err != nil {
+		return contactReq, err
+	}
+	var err error
+	contactReq.Phone, err = utils.NormalizePhone(contactReq.Phone)
+	return contactReq, err
+}
+
+// contactError maps a repository write error to the API error reported for it.
+func contactError(err error) *errors.ErrorResponse {
+	if err == repository.ErrContactEmailExists {
+		return errors.ErrContactEmailExists
+	}
+	return errors.ErrUnableToSaveContact
+}
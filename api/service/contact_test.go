@@ -0,0 +1,88 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	er "github.com/kumareswaramoorthi/companies/api/errors"
+	"github.com/kumareswaramoorthi/companies/api/models"
+	"github.com/kumareswaramoorthi/companies/api/repository"
+	"github.com/kumareswaramoorthi/companies/api/repository/mocks"
+	"github.com/stretchr/testify/suite"
+)
+
+type ContactServiceTestSuite struct {
+	suite.Suite
+	mockCtrl              *gomock.Controller
+	mockContactRepository *mocks.MockContactRepository
+	mockCompanyRepository *mocks.MockRepository
+	ContactService        Contact
+	context               *gin.Context
+}
+
+func TestContactService(t *testing.T) {
+	suite.Run(t, new(ContactServiceTestSuite))
+}
+
+func (suite *ContactServiceTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockContactRepository = mocks.NewMockContactRepository(suite.mockCtrl)
+	suite.mockCompanyRepository = mocks.NewMockRepository(suite.mockCtrl)
+	suite.ContactService = NewContactService(suite.mockContactRepository, suite.mockCompanyRepository)
+	suite.context, _ = gin.CreateTestContext(httptest.NewRecorder())
+	suite.context.Request, _ = http.NewRequest("GET", "", nil)
+}
+
+func (suite *ContactServiceTestSuite) TestCreateContactNormalizesPhone() {
+	suite.mockCompanyRepository.EXPECT().CheckCompanyExistsByID(suite.context, id).Return(true, nil)
+	suite.mockContactRepository.EXPECT().CreateContact(suite.context, gomock.Any()).
+		DoAndReturn(func(c *gin.Context, contact models.Contact) error {
+			suite.NotEmpty(contact.ID)
+			suite.Equal("Asha Rao", contact.Name)
+			suite.Equal("+914412345678", contact.Phone)
+			return nil
+		})
+	suite.mockContactRepository.EXPECT().GetContact(suite.context, id, gomock.Any()).Return(models.Contact{Phone: "+914412345678"}, nil)
+
+	contact, err := suite.ContactService.CreateContact(suite.context,
+		models.Contact{CompanyID: id, Name: " Asha Rao ", Email: "asha@xyz.com", Phone: "+91 (44) 1234-5678"})
+	suite.Nil(err)
+	suite.Equal("+914412345678", contact.Phone)
+}
+
+func (suite *ContactServiceTestSuite) TestCreateContactInvalidEmail() {
+	_, err := suite.ContactService.CreateContact(suite.context, models.Contact{CompanyID: id, Name: "Asha Rao", Email: "asha at xyz"})
+	suite.Equal(er.ValidationFailed, string(err.ErrorCode))
+}
+
+func (suite *ContactServiceTestSuite) TestCreateContactInvalidPhone() {
+	_, err := suite.ContactService.CreateContact(suite.context, models.Contact{CompanyID: id, Name: "Asha Rao", Email: "asha@xyz.com", Phone: "044 1234 5678"})
+	suite.Equal(er.ValidationFailed, string(err.ErrorCode))
+}
+
+func (suite *ContactServiceTestSuite) TestReplaceContactEmailExists() {
+	contact := models.Contact{ID: "5f0c7a3e-8d2b-4c1a-9e6f-3b7d2a1c0e9f", CompanyID: id, Name: "Asha Rao", Email: "asha@xyz.com"}
+	suite.mockCompanyRepository.EXPECT().CheckCompanyExistsByID(suite.context, id).Return(true, nil)
+	suite.mockContactRepository.EXPECT().UpdateContact(suite.context, contact).Return(repository.ErrContactEmailExists)
+
+	_, err := suite.ContactService.ReplaceContact(suite.context, contact)
+	suite.Equal(er.ErrContactEmailExists, err)
+}
+
+func (suite *ContactServiceTestSuite) TestReplaceContactCompanyNotFound() {
+	contact := models.Contact{ID: "5f0c7a3e-8d2b-4c1a-9e6f-3b7d2a1c0e9f", CompanyID: id, Name: "Asha Rao", Email: "asha@xyz.com"}
+	suite.mockCompanyRepository.EXPECT().CheckCompanyExistsByID(suite.context, id).Return(false, nil)
+
+	_, err := suite.ContactService.ReplaceContact(suite.context, contact)
+	suite.Equal(er.ErrNoCompanyRecordsFoundByID, err)
+}
+
+func (suite *ContactServiceTestSuite) TestDeleteContactCompanyNotFound() {
+	suite.mockCompanyRepository.EXPECT().CheckCompanyExistsByID(suite.context, id).Return(false, nil)
+
+	err := suite.ContactService.DeleteContact(suite.context, id, "5f0c7a3e-8d2b-4c1a-9e6f-3b7d2a1c0e9f")
+	suite.Equal(er.ErrNoCompanyRecordsFoundByID, err)
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+	"github.com/kumareswaramoorthi/companies/api/constants"
+	errors "github.com/kumareswaramoorthi/companies/api/errors"
+	"github.com/kumareswaramoorthi/companies/api/logging"
+	"github.com/kumareswaramoorthi/companies/api/models"
+	"github.com/kumareswaramoorthi/companies/api/repository"
+	"github.com/kumareswaramoorthi/companies/api/utils"
+)
+
+type Location interface {
+	CreateLocation(c *gin.Context, location models.Location) (models.Location, *errors.ErrorResponse)
+	GetLocation(c *gin.Context, companyID, id string) (models.Location, *errors.ErrorResponse)
+	ListLocations(c *gin.Context, companyID string) ([]models.Location, *errors.ErrorResponse)
+	ReplaceLocation(c *gin.Context, location models.Location) (models.Location, *errors.ErrorResponse)
+	DeleteLocation(c *gin.Context, companyID, id string) *errors.ErrorResponse
+	ListNearbyCompanies(c *gin.Context, query models.NearbyQuery) ([]models.NearbyCompany, *errors.ErrorResponse)
+}
+
+type location struct {
+	repo        repository.LocationRepository
+	companyRepo repository.Repository
+}
+
+// NewLocationService returns the service of the addresses of companies.
+func NewLocationService(repo repository.LocationRepository, companyRepo repository.Repository) Location {
+	return &location{repo: repo, companyRepo: companyRepo}
+}
+
+func (s location) CreateLocation(c *gin.Context, locationReq models.Location) (models.Location, *errors.ErrorResponse) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Service").
+		WithField(constants.Method, "CreateLocation")
+
+	locationReq.Country = strings.ToUpper(locationReq.Country)
+	if validationErr := validateLocation(locationReq); validationErr != nil {
+		return models.Location{}, errors.NewErrorResponse(errors.ErrValidationFailed.HttpStatusCode, errors.ValidationFailed, validationErr.Error())
+	}
+	if errResp := checkCompanyExists(c, s.companyRepo, locationReq.CompanyID, errors.ErrNoCompanyRecordsFoundByID); errResp != nil {
+		return models.Location{}, errResp
+	}
+
+	var err error
+	if locationReq.ID, err = utils.NewCompanyID(); err != nil {
+		logger.Errorf("service: CreateLocation ID generation error: %s", err.Error())
+		return models.Location{}, errors.ErrInternalServerError
+	}
+
+	if err = s.repo.CreateLocation(c, locationReq); err != nil {
+		logger.Errorf("service: CreateLocation company ID [%s] error: %s", locationReq.CompanyID, err.Error())
+		return models.Location{}, locationError(err)
+	}
+
+	created, err := s.repo.GetLocation(c, locationReq.CompanyID, locationReq.ID)
+	if err != nil {
+		logger.Errorf("service: CreateLocation ID [%s] error: %s", locationReq.ID, err.Error())
+		return models.Location{}, errors.ErrUnableToFetchLocations
+	}
+
+	logger.Debugf("created location with ID: [%s]", created.ID)
+	return created, nil
+}
+
+func (s location) GetLocation(c *gin.Context, companyID, id string) (models.Location, *errors.ErrorResponse) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Service").
+		WithField(constants.Method, "GetLocation")
+
+	if errResp := checkCompanyExists(c, s.companyRepo, companyID, errors.ErrNoCompanyRecordsFoundByID); errResp != nil {
+		return models.Location{}, errResp
+	}
+
+	location, err := s.repo.GetLocation(c, companyID, id)
+	if err == sql.ErrNoRows {
+		return models.Location{}, errors.ErrNoLocationFound
+	}
+	if err != nil {
+		logger.Errorf("service: GetLocation ID [%s] error: %s", id, err.Error())
+		return models.Location{}, errors.ErrUnableToFetchLocations
+	}
+
+	logger.Debugf("found location with ID: [%s]", id)
+	return location, nil
+}
+
+func (s location) ListLocations(c *gin.Context, companyID string) ([]models.Location, *errors.ErrorResponse) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Service").
+		WithField(constants.Method, "ListLocations")
+
+	if errResp := checkCompanyExists(c, s.companyRepo, companyID, errors.ErrNoCompanyRecordsFoundByID); errResp != nil {
+		return nil, errResp
+	}
+
+	locations, err := s.repo.ListLocations(c, companyID)
+	if err != nil {
+		logger.Errorf("service: ListLocations company ID [%s] error: %s", companyID, err.Error())
+		return nil, errors.ErrUnableToFetchLocations
+	}
+
+	logger.Debugf("found %d locations of company with ID: [%s]", len(locations), companyID)
+	return locations, nil
+}
+
+// ReplaceLocation replaces every field of a location of a company.
+func (s location) ReplaceLocation(c *gin.Context, locationReq models.Location) (models.Location, *errors.ErrorResponse) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Service").
+		WithField(constants.Method, "ReplaceLocation")
+
+	if errResp := checkCompanyExists(c, s.companyRepo, locationReq.CompanyID, errors.ErrNoCompanyRecordsFoundByID); errResp != nil {
+		return models.Location{}, errResp
+	}
+
+	locationReq.Country = strings.ToUpper(locationReq.Country)
+	if validationErr := validateLocation(locationReq); validationErr != nil {
+		return models.Location{}, errors.NewErrorResponse(errors.ErrValidationFailed.HttpStatusCode, errors.ValidationFailed, validationErr.Error())
+	}
+
+	err := s.repo.UpdateLocation(c, locationReq)
+	if err == sql.ErrNoRows {
+		return models.Location{}, errors.ErrNoLocationFound
+	}
+	if err != nil {
+		logger.Errorf("service: ReplaceLocation ID [%s] error: %s", locationReq.ID, err.Error())
+		return models.Location{}, locationError(err)
+	}
+
+	replaced, err := s.repo.GetLocation(c, locationReq.CompanyID, locationReq.ID)
+	if err != nil {
+		logger.Errorf("service: ReplaceLocation ID [%s] error: %s", locationReq.ID, err.Error())
+		return models.Location{}, errors.ErrUnableToFetchLocations
+	}
+
+	logger.Debugf("replaced location with ID: [%s]", replaced.ID)
+	return replaced, nil
+}
+
+func (s location) DeleteLocation(c *gin.Context, companyID, id string) *errors.ErrorResponse {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Service").
+		WithField(constants.Method, "DeleteLocation")
+
+	if errResp := checkCompanyExists(c, s.companyRepo, companyID, errors.ErrNoCompanyRecordsFoundByID); errResp != nil {
+		return errResp
+	}
+
+	err := s.repo.DeleteLocation(c, companyID, id)
+	if err == sql.ErrNoRows {
+		return errors.ErrNoLocationFound
+	}
+	if err != nil {
+		logger.Errorf("service: DeleteLocation ID [%s] error: %s", id, err.Error())
+		return errors.ErrUnableToSaveLocation
+	}
+
+	logger.Debugf("deleted location with ID: [%s]", id)
+	return nil
+}
+
+// ListNearbyCompanies returns one page of the companies with a location within the radius
+// of the query, nearest first.
+func (s location) ListNearbyCompanies(c *gin.Context, query models.NearbyQuery) ([]models.NearbyCompany, *errors.ErrorResponse) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Service").
+		WithField(constants.Method, "ListNearbyCompanies")
+
+	companies, err := s.repo.ListNearbyCompanies(c, query)
+	if err != nil {
+		logger.Errorf("service: ListNearbyCompanies error: %s", err.Error())
+		return nil, errors.ErrUnableToSearchNearbyCompanies
+	}
+
+	logger.Debugf("found %d companies within %g km", len(companies), query.RadiusKm)
+	return companies, nil
+}
+
+func validateLocation(l models.Location) error {
+	if _, err := govalidator.ValidateStruct(l); err != nil {
+		return err
+	}
+	// the coordinates are pointers so a missing one is not taken for 0
+	if l.Latitude == nil || l.Longitude == nil {
+		return fmt.Errorf("latitude and longitude are required")
+	}
+	if *l.Latitude < -90 || *l.Latitude > 90 {
+		return fmt.Errorf("latitude must be between -90 and 90")
+	}
+	if *l.Longitude < -180 || *l.Longitude > 180 {
+		return fmt.Errorf("longitude must be between -180 and 180")
+	}
+	return nil
+}
+
+// locationError maps a repository write error to the API error reported for it.
+func locationError(err error) *errors.ErrorResponse {
+	if err == repository.ErrHeadquartersExists {
+		return errors.ErrHeadquartersExists
+	}
+	return errors.ErrUnableToSaveLocation
+}
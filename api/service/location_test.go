@@ -0,0 +1,129 @@
+package service
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	er "github.com/kumareswaramoorthi/companies/api/errors"
+	"github.com/kumareswaramoorthi/companies/api/models"
+	"github.com/kumareswaramoorthi/companies/api/repository"
+	"github.com/kumareswaramoorthi/companies/api/repository/mocks"
+	"github.com/stretchr/testify/suite"
+)
+
+type LocationServiceTestSuite struct {
+	suite.Suite
+	mockCtrl               *gomock.Controller
+	mockLocationRepository *mocks.MockLocationRepository
+	mockCompanyRepository  *mocks.MockRepository
+	LocationService        Location
+	context                *gin.Context
+}
+
+func TestLocationService(t *testing.T) {
+	suite.Run(t, new(LocationServiceTestSuite))
+}
+
+func (suite *LocationServiceTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockLocationRepository = mocks.NewMockLocationRepository(suite.mockCtrl)
+	suite.mockCompanyRepository = mocks.NewMockRepository(suite.mockCtrl)
+	suite.LocationService = NewLocationService(suite.mockLocationRepository, suite.mockCompanyRepository)
+	suite.context, _ = gin.CreateTestContext(httptest.NewRecorder())
+	suite.context.Request, _ = http.NewRequest("GET", "", nil)
+}
+
+func branch() models.Location {
+	latitude, longitude := 13.0827, 80.2707
+	return models.Location{CompanyID: id, Type: "branch", Street: "1 Main Street", City: "Chennai", Country: "in", Latitude: &latitude, Longitude: &longitude}
+}
+
+func (suite *LocationServiceTestSuite) TestCreateLocationSuccess() {
+	suite.mockCompanyRepository.EXPECT().CheckCompanyExistsByID(suite.context, id).Return(true, nil)
+	suite.mockLocationRepository.EXPECT().CreateLocation(suite.context, gomock.Any()).
+		DoAndReturn(func(c *gin.Context, location models.Location) error {
+			suite.NotEmpty(location.ID)
+			suite.Equal("IN", location.Country)
+			return nil
+		})
+	suite.mockLocationRepository.EXPECT().GetLocation(suite.context, id, gomock.Any()).Return(models.Location{ID: "5f0c7a3e-8d2b-4c1a-9e6f-3b7d2a1c0e9f", Country: "IN"}, nil)
+
+	location, err := suite.LocationService.CreateLocation(suite.context, branch())
+	suite.Nil(err)
+	suite.Equal("IN", location.Country)
+}
+
+func (suite *LocationServiceTestSuite) TestCreateLocationInvalidLatitude() {
+	location := branch()
+	*location.Latitude = 91
+
+	_, err := suite.LocationService.CreateLocation(suite.context, location)
+	suite.Equal(er.ValidationFailed, string(err.ErrorCode))
+}
+
+func (suite *LocationServiceTestSuite) TestCreateLocationMissingCoordinates() {
+	location := branch()
+	location.Longitude = nil
+
+	_, err := suite.LocationService.CreateLocation(suite.context, location)
+	suite.Equal(er.ErrValidationFailed.HttpStatusCode, err.HttpStatusCode)
+	suite.Equal(er.ValidationFailed, string(err.ErrorCode))
+}
+
+func (suite *LocationServiceTestSuite) TestReplaceLocationMissingCoordinates() {
+	location := branch()
+	location.ID = "5f0c7a3e-8d2b-4c1a-9e6f-3b7d2a1c0e9f"
+	location.Latitude = nil
+	suite.mockCompanyRepository.EXPECT().CheckCompanyExistsByID(suite.context, id).Return(true, nil)
+
+	_, err := suite.LocationService.ReplaceLocation(suite.context, location)
+	suite.Equal(er.ErrValidationFailed.HttpStatusCode, err.HttpStatusCode)
+	suite.Equal(er.ValidationFailed, string(err.ErrorCode))
+}
+
+func (suite *LocationServiceTestSuite) TestCreateLocationInvalidCountry() {
+	location := branch()
+	location.Country = "India"
+
+	_, err := suite.LocationService.CreateLocation(suite.context, location)
+	suite.Equal(er.ValidationFailed, string(err.ErrorCode))
+}
+
+func (suite *LocationServiceTestSuite) TestReplaceLocationSecondHeadquarters() {
+	location := branch()
+	location.ID = "5f0c7a3e-8d2b-4c1a-9e6f-3b7d2a1c0e9f"
+	location.Type = "headquarters"
+	suite.mockCompanyRepository.EXPECT().CheckCompanyExistsByID(suite.context, id).Return(true, nil)
+	suite.mockLocationRepository.EXPECT().UpdateLocation(suite.context, gomock.Any()).Return(repository.ErrHeadquartersExists)
+
+	_, err := suite.LocationService.ReplaceLocation(suite.context, location)
+	suite.Equal(er.ErrHeadquartersExists, err)
+}
+
+func (suite *LocationServiceTestSuite) TestReplaceLocationCompanyNotFound() {
+	location := branch()
+	location.ID = "5f0c7a3e-8d2b-4c1a-9e6f-3b7d2a1c0e9f"
+	suite.mockCompanyRepository.EXPECT().CheckCompanyExistsByID(suite.context, id).Return(false, nil)
+
+	_, err := suite.LocationService.ReplaceLocation(suite.context, location)
+	suite.Equal(er.ErrNoCompanyRecordsFoundByID, err)
+}
+
+func (suite *LocationServiceTestSuite) TestDeleteLocationCompanyNotFound() {
+	suite.mockCompanyRepository.EXPECT().CheckCompanyExistsByID(suite.context, id).Return(false, nil)
+
+	err := suite.LocationService.DeleteLocation(suite.context, id, "5f0c7a3e-8d2b-4c1a-9e6f-3b7d2a1c0e9f")
+	suite.Equal(er.ErrNoCompanyRecordsFoundByID, err)
+}
+
+func (suite *LocationServiceTestSuite) TestGetLocationNotFound() {
+	suite.mockCompanyRepository.EXPECT().CheckCompanyExistsByID(suite.context, id).Return(true, nil)
+	suite.mockLocationRepository.EXPECT().GetLocation(suite.context, id, "5f0c7a3e-8d2b-4c1a-9e6f-3b7d2a1c0e9f").Return(models.Location{}, sql.ErrNoRows)
+
+	_, err := suite.LocationService.GetLocation(suite.context, id, "5f0c7a3e-8d2b-4c1a-9e6f-3b7d2a1c0e9f")
+	suite.Equal(er.ErrNoLocationFound, err)
+}
@@ -6,11 +6,14 @@ package mocks
 
 import (
         reflect "reflect"
+        time "time"
 
         gin "github.com/gin-gonic/gin"
         gomock "github.com/golang/mock/gomock"
+        codec "github.com/kumareswaramoorthi/companies/api/codec"
         errors "github.com/kumareswaramoorthi/companies/api/errors"
         models "github.com/kumareswaramoorthi/companies/api/models"
+        patch "github.com/kumareswaramoorthi/companies/api/patch"
 )
 
 // MockCompany is a mock of Company interface.
@@ -36,6 +39,21 @@ func (m *MockCompany) EXPECT() *MockCompanyMockRecorder {
         return m.recorder
 }
 
+// CreateCompanies mocks base method.
+func (m *MockCompany) CreateCompanies(c *gin.Context, companies []models.Company, documents []map[string]interface{}, atomic bool) (models.BulkResult, *errors.ErrorResponse) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "CreateCompanies", c, companies, documents, atomic)
+        ret0, _ := ret[0].(models.BulkResult)
+        ret1, _ := ret[1].(*errors.ErrorResponse)
+        return ret0, ret1
+}
+
+// CreateCompanies indicates an expected call of CreateCompanies.
+func (mr *MockCompanyMockRecorder) CreateCompanies(c, companies, documents, atomic interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCompanies", reflect.TypeOf((*MockCompany)(nil).CreateCompanies), c, companies, documents, atomic)
+}
+
 // CreateCompany mocks base method.
 func (m *MockCompany) CreateCompany(c *gin.Context, company models.Company) (models.Company, *errors.ErrorResponse) {
         m.ctrl.T.Helper()
@@ -51,46 +69,361 @@ func (mr *MockCompanyMockRecorder) CreateCompany(c, company interface{}) *gomock
         return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCompany", reflect.TypeOf((*MockCompany)(nil).CreateCompany), c, company)
 }
 
+// DeleteCompanies mocks base method.
+func (m *MockCompany) DeleteCompanies(c *gin.Context, selector models.CompanySelector) (models.BulkChangeResult, *errors.ErrorResponse) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "DeleteCompanies", c, selector)
+        ret0, _ := ret[0].(models.BulkChangeResult)
+        ret1, _ := ret[1].(*errors.ErrorResponse)
+        return ret0, ret1
+}
+
+// DeleteCompanies indicates an expected call of DeleteCompanies.
+func (mr *MockCompanyMockRecorder) DeleteCompanies(c, selector interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCompanies", reflect.TypeOf((*MockCompany)(nil).DeleteCompanies), c, selector)
+}
+
 // DeleteCompany mocks base method.
-func (m *MockCompany) DeleteCompany(c *gin.Context, id string) *errors.ErrorResponse {
+func (m *MockCompany) DeleteCompany(c *gin.Context, id string, versions []int, cascade bool) *errors.ErrorResponse {
         m.ctrl.T.Helper()
-        ret := m.ctrl.Call(m, "DeleteCompany", c, id)
+        ret := m.ctrl.Call(m, "DeleteCompany", c, id, versions, cascade)
         ret0, _ := ret[0].(*errors.ErrorResponse)
         return ret0
 }
 
 // DeleteCompany indicates an expected call of DeleteCompany.
-func (mr *MockCompanyMockRecorder) DeleteCompany(c, id interface{}) *gomock.Call {
+func (mr *MockCompanyMockRecorder) DeleteCompany(c, id, versions, cascade interface{}) *gomock.Call {
         mr.mock.ctrl.T.Helper()
-        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCompany", reflect.TypeOf((*MockCompany)(nil).DeleteCompany), c, id)
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCompany", reflect.TypeOf((*MockCompany)(nil).DeleteCompany), c, id, versions, cascade)
+}
+
+// ExportCompanies mocks base method.
+func (m *MockCompany) ExportCompanies(c *gin.Context, query models.CompanyListQuery, encoder codec.CompanyEncoder) *errors.ErrorResponse {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "ExportCompanies", c, query, encoder)
+        ret0, _ := ret[0].(*errors.ErrorResponse)
+        return ret0
+}
+
+// ExportCompanies indicates an expected call of ExportCompanies.
+func (mr *MockCompanyMockRecorder) ExportCompanies(c, query, encoder interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportCompanies", reflect.TypeOf((*MockCompany)(nil).ExportCompanies), c, query, encoder)
+}
+
+// GetCompaniesHistory mocks base method.
+func (m *MockCompany) GetCompaniesHistory(c *gin.Context, ids []string, limit int) (map[string][]models.CompanyRevision, *errors.ErrorResponse) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "GetCompaniesHistory", c, ids, limit)
+        ret0, _ := ret[0].(map[string][]models.CompanyRevision)
+        ret1, _ := ret[1].(*errors.ErrorResponse)
+        return ret0, ret1
+}
+
+// GetCompaniesHistory indicates an expected call of GetCompaniesHistory.
+func (mr *MockCompanyMockRecorder) GetCompaniesHistory(c, ids, limit interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCompaniesHistory", reflect.TypeOf((*MockCompany)(nil).GetCompaniesHistory), c, ids, limit)
 }
 
 // GetCompany mocks base method.
-func (m *MockCompany) GetCompany(c *gin.Context, id string) (models.Company, *errors.ErrorResponse) {
+func (m *MockCompany) GetCompany(c *gin.Context, id string, fields []string) (models.Company, *errors.ErrorResponse) {
         m.ctrl.T.Helper()
-        ret := m.ctrl.Call(m, "GetCompany", c, id)
+        ret := m.ctrl.Call(m, "GetCompany", c, id, fields)
         ret0, _ := ret[0].(models.Company)
         ret1, _ := ret[1].(*errors.ErrorResponse)
         return ret0, ret1
 }
 
 // GetCompany indicates an expected call of GetCompany.
-func (mr *MockCompanyMockRecorder) GetCompany(c, id interface{}) *gomock.Call {
+func (mr *MockCompanyMockRecorder) GetCompany(c, id, fields interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCompany", reflect.TypeOf((*MockCompany)(nil).GetCompany), c, id, fields)
+}
+
+// GetCompanyAncestors mocks base method.
+func (m *MockCompany) GetCompanyAncestors(c *gin.Context, id string) ([]models.CompanyNode, *errors.ErrorResponse) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "GetCompanyAncestors", c, id)
+        ret0, _ := ret[0].([]models.CompanyNode)
+        ret1, _ := ret[1].(*errors.ErrorResponse)
+        return ret0, ret1
+}
+
+// GetCompanyAncestors indicates an expected call of GetCompanyAncestors.
+func (mr *MockCompanyMockRecorder) GetCompanyAncestors(c, id interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCompanyAncestors", reflect.TypeOf((*MockCompany)(nil).GetCompanyAncestors), c, id)
+}
+
+// GetCompanyAsOf mocks base method.
+func (m *MockCompany) GetCompanyAsOf(c *gin.Context, id string, asOf time.Time) (models.CompanyVersion, *errors.ErrorResponse) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "GetCompanyAsOf", c, id, asOf)
+        ret0, _ := ret[0].(models.CompanyVersion)
+        ret1, _ := ret[1].(*errors.ErrorResponse)
+        return ret0, ret1
+}
+
+// GetCompanyAsOf indicates an expected call of GetCompanyAsOf.
+func (mr *MockCompanyMockRecorder) GetCompanyAsOf(c, id, asOf interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCompanyAsOf", reflect.TypeOf((*MockCompany)(nil).GetCompanyAsOf), c, id, asOf)
+}
+
+// GetCompanyByName mocks base method.
+func (m *MockCompany) GetCompanyByName(c *gin.Context, name string) (models.Company, *errors.ErrorResponse) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "GetCompanyByName", c, name)
+        ret0, _ := ret[0].(models.Company)
+        ret1, _ := ret[1].(*errors.ErrorResponse)
+        return ret0, ret1
+}
+
+// GetCompanyByName indicates an expected call of GetCompanyByName.
+func (mr *MockCompanyMockRecorder) GetCompanyByName(c, name interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCompanyByName", reflect.TypeOf((*MockCompany)(nil).GetCompanyByName), c, name)
+}
+
+// GetCompanyHistory mocks base method.
+func (m *MockCompany) GetCompanyHistory(c *gin.Context, id string, limit, offset int) (models.CompanyRevisionPage, *errors.ErrorResponse) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "GetCompanyHistory", c, id, limit, offset)
+        ret0, _ := ret[0].(models.CompanyRevisionPage)
+        ret1, _ := ret[1].(*errors.ErrorResponse)
+        return ret0, ret1
+}
+
+// GetCompanyHistory indicates an expected call of GetCompanyHistory.
+func (mr *MockCompanyMockRecorder) GetCompanyHistory(c, id, limit, offset interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCompanyHistory", reflect.TypeOf((*MockCompany)(nil).GetCompanyHistory), c, id, limit, offset)
+}
+
+// GetCompanyStats mocks base method.
+func (m *MockCompany) GetCompanyStats(c *gin.Context, query models.CompanyStatsQuery) (models.CompanyStats, *errors.ErrorResponse) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "GetCompanyStats", c, query)
+        ret0, _ := ret[0].(models.CompanyStats)
+        ret1, _ := ret[1].(*errors.ErrorResponse)
+        return ret0, ret1
+}
+
+// GetCompanyStats indicates an expected call of GetCompanyStats.
+func (mr *MockCompanyMockRecorder) GetCompanyStats(c, query interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCompanyStats", reflect.TypeOf((*MockCompany)(nil).GetCompanyStats), c, query)
+}
+
+// GetCompanySubtree mocks base method.
+func (m *MockCompany) GetCompanySubtree(c *gin.Context, id string) (models.CompanyTree, *errors.ErrorResponse) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "GetCompanySubtree", c, id)
+        ret0, _ := ret[0].(models.CompanyTree)
+        ret1, _ := ret[1].(*errors.ErrorResponse)
+        return ret0, ret1
+}
+
+// GetCompanySubtree indicates an expected call of GetCompanySubtree.
+func (mr *MockCompanyMockRecorder) GetCompanySubtree(c, id interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCompanySubtree", reflect.TypeOf((*MockCompany)(nil).GetCompanySubtree), c, id)
+}
+
+// ImportCompanies mocks base method.
+func (m *MockCompany) ImportCompanies(c *gin.Context, decoder codec.CompanyDecoder, onConflict string) (models.ImportReport, *errors.ErrorResponse) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "ImportCompanies", c, decoder, onConflict)
+        ret0, _ := ret[0].(models.ImportReport)
+        ret1, _ := ret[1].(*errors.ErrorResponse)
+        return ret0, ret1
+}
+
+// ImportCompanies indicates an expected call of ImportCompanies.
+func (mr *MockCompanyMockRecorder) ImportCompanies(c, decoder, onConflict interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportCompanies", reflect.TypeOf((*MockCompany)(nil).ImportCompanies), c, decoder, onConflict)
+}
+
+// ListCompanies mocks base method.
+func (m *MockCompany) ListCompanies(c *gin.Context, query models.CompanyListQuery) (models.CompanyPage, *errors.ErrorResponse) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "ListCompanies", c, query)
+        ret0, _ := ret[0].(models.CompanyPage)
+        ret1, _ := ret[1].(*errors.ErrorResponse)
+        return ret0, ret1
+}
+
+// ListCompanies indicates an expected call of ListCompanies.
+func (mr *MockCompanyMockRecorder) ListCompanies(c, query interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCompanies", reflect.TypeOf((*MockCompany)(nil).ListCompanies), c, query)
+}
+
+// ListCompaniesAsOf mocks base method.
+func (m *MockCompany) ListCompaniesAsOf(c *gin.Context, query models.CompanyListQuery) (models.CompanyVersionPage, *errors.ErrorResponse) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "ListCompaniesAsOf", c, query)
+        ret0, _ := ret[0].(models.CompanyVersionPage)
+        ret1, _ := ret[1].(*errors.ErrorResponse)
+        return ret0, ret1
+}
+
+// ListCompaniesAsOf indicates an expected call of ListCompaniesAsOf.
+func (mr *MockCompanyMockRecorder) ListCompaniesAsOf(c, query interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCompaniesAsOf", reflect.TypeOf((*MockCompany)(nil).ListCompaniesAsOf), c, query)
+}
+
+// ListCompanyChildren mocks base method.
+func (m *MockCompany) ListCompanyChildren(c *gin.Context, id string, limit, offset int) ([]models.Company, *errors.ErrorResponse) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "ListCompanyChildren", c, id, limit, offset)
+        ret0, _ := ret[0].([]models.Company)
+        ret1, _ := ret[1].(*errors.ErrorResponse)
+        return ret0, ret1
+}
+
+// ListCompanyChildren indicates an expected call of ListCompanyChildren.
+func (mr *MockCompanyMockRecorder) ListCompanyChildren(c, id, limit, offset interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCompanyChildren", reflect.TypeOf((*MockCompany)(nil).ListCompanyChildren), c, id, limit, offset)
+}
+
+// ListDeletedCompanies mocks base method.
+func (m *MockCompany) ListDeletedCompanies(c *gin.Context, limit, offset int) ([]models.DeletedCompany, *errors.ErrorResponse) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "ListDeletedCompanies", c, limit, offset)
+        ret0, _ := ret[0].([]models.DeletedCompany)
+        ret1, _ := ret[1].(*errors.ErrorResponse)
+        return ret0, ret1
+}
+
+// ListDeletedCompanies indicates an expected call of ListDeletedCompanies.
+func (mr *MockCompanyMockRecorder) ListDeletedCompanies(c, limit, offset interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeletedCompanies", reflect.TypeOf((*MockCompany)(nil).ListDeletedCompanies), c, limit, offset)
+}
+
+// PatchCompany mocks base method.
+func (m *MockCompany) PatchCompany(c *gin.Context, id string, p patch.Patch, versions []int) (models.Company, *errors.ErrorResponse) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "PatchCompany", c, id, p, versions)
+        ret0, _ := ret[0].(models.Company)
+        ret1, _ := ret[1].(*errors.ErrorResponse)
+        return ret0, ret1
+}
+
+// PatchCompany indicates an expected call of PatchCompany.
+func (mr *MockCompanyMockRecorder) PatchCompany(c, id, p, versions interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchCompany", reflect.TypeOf((*MockCompany)(nil).PatchCompany), c, id, p, versions)
+}
+
+// ReplaceCompany mocks base method.
+func (m *MockCompany) ReplaceCompany(c *gin.Context, company models.Company, versions []int, mustExist bool) (models.Company, bool, *errors.ErrorResponse) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "ReplaceCompany", c, company, versions, mustExist)
+        ret0, _ := ret[0].(models.Company)
+        ret1, _ := ret[1].(bool)
+        ret2, _ := ret[2].(*errors.ErrorResponse)
+        return ret0, ret1, ret2
+}
+
+// ReplaceCompany indicates an expected call of ReplaceCompany.
+func (mr *MockCompanyMockRecorder) ReplaceCompany(c, company, versions, mustExist interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceCompany", reflect.TypeOf((*MockCompany)(nil).ReplaceCompany), c, company, versions, mustExist)
+}
+
+// RestoreCompany mocks base method.
+func (m *MockCompany) RestoreCompany(c *gin.Context, id string) (models.Company, *errors.ErrorResponse) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "RestoreCompany", c, id)
+        ret0, _ := ret[0].(models.Company)
+        ret1, _ := ret[1].(*errors.ErrorResponse)
+        return ret0, ret1
+}
+
+// RestoreCompany indicates an expected call of RestoreCompany.
+func (mr *MockCompanyMockRecorder) RestoreCompany(c, id interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreCompany", reflect.TypeOf((*MockCompany)(nil).RestoreCompany), c, id)
+}
+
+// RevertCompany mocks base method.
+func (m *MockCompany) RevertCompany(c *gin.Context, id string, revision int) (models.Company, *errors.ErrorResponse) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "RevertCompany", c, id, revision)
+        ret0, _ := ret[0].(models.Company)
+        ret1, _ := ret[1].(*errors.ErrorResponse)
+        return ret0, ret1
+}
+
+// RevertCompany indicates an expected call of RevertCompany.
+func (mr *MockCompanyMockRecorder) RevertCompany(c, id, revision interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevertCompany", reflect.TypeOf((*MockCompany)(nil).RevertCompany), c, id, revision)
+}
+
+// SearchCompanies mocks base method.
+func (m *MockCompany) SearchCompanies(c *gin.Context, text string, limit, offset int) ([]models.CompanySearchResult, *errors.ErrorResponse) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "SearchCompanies", c, text, limit, offset)
+        ret0, _ := ret[0].([]models.CompanySearchResult)
+        ret1, _ := ret[1].(*errors.ErrorResponse)
+        return ret0, ret1
+}
+
+// SearchCompanies indicates an expected call of SearchCompanies.
+func (mr *MockCompanyMockRecorder) SearchCompanies(c, text, limit, offset interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchCompanies", reflect.TypeOf((*MockCompany)(nil).SearchCompanies), c, text, limit, offset)
+}
+
+// SuggestCompanies mocks base method.
+func (m *MockCompany) SuggestCompanies(c *gin.Context, prefix string, limit int) ([]models.CompanySuggestion, *errors.ErrorResponse) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "SuggestCompanies", c, prefix, limit)
+        ret0, _ := ret[0].([]models.CompanySuggestion)
+        ret1, _ := ret[1].(*errors.ErrorResponse)
+        return ret0, ret1
+}
+
+// SuggestCompanies indicates an expected call of SuggestCompanies.
+func (mr *MockCompanyMockRecorder) SuggestCompanies(c, prefix, limit interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SuggestCompanies", reflect.TypeOf((*MockCompany)(nil).SuggestCompanies), c, prefix, limit)
+}
+
+// UpdateCompanies mocks base method.
+func (m *MockCompany) UpdateCompanies(c *gin.Context, selector models.CompanySelector, updateReq map[string]interface{}) (models.BulkChangeResult, *errors.ErrorResponse) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "UpdateCompanies", c, selector, updateReq)
+        ret0, _ := ret[0].(models.BulkChangeResult)
+        ret1, _ := ret[1].(*errors.ErrorResponse)
+        return ret0, ret1
+}
+
+// UpdateCompanies indicates an expected call of UpdateCompanies.
+func (mr *MockCompanyMockRecorder) UpdateCompanies(c, selector, updateReq interface{}) *gomock.Call {
         mr.mock.ctrl.T.Helper()
-        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCompany", reflect.TypeOf((*MockCompany)(nil).GetCompany), c, id)
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCompanies", reflect.TypeOf((*MockCompany)(nil).UpdateCompanies), c, selector, updateReq)
 }
 
 // UpdateCompany mocks base method.
-func (m *MockCompany) UpdateCompany(c *gin.Context, id string, updateReq map[string]interface{}) (models.Company, *errors.ErrorResponse) {
+func (m *MockCompany) UpdateCompany(c *gin.Context, id string, updateReq map[string]interface{}, versions []int) (models.Company, *errors.ErrorResponse) {
         m.ctrl.T.Helper()
-        ret := m.ctrl.Call(m, "UpdateCompany", c, id, updateReq)
+        ret := m.ctrl.Call(m, "UpdateCompany", c, id, updateReq, versions)
         ret0, _ := ret[0].(models.Company)
         ret1, _ := ret[1].(*errors.ErrorResponse)
         return ret0, ret1
 }
 
 // UpdateCompany indicates an expected call of UpdateCompany.
-func (mr *MockCompanyMockRecorder) UpdateCompany(c, id, updateReq interface{}) *gomock.Call {
+func (mr *MockCompanyMockRecorder) UpdateCompany(c, id, updateReq, versions interface{}) *gomock.Call {
         mr.mock.ctrl.T.Helper()
-        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCompany", reflect.TypeOf((*MockCompany)(nil).UpdateCompany), c, id, updateReq)
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCompany", reflect.TypeOf((*MockCompany)(nil).UpdateCompany), c, id, updateReq, versions)
 }
\ No newline at end of file
@@ -0,0 +1,306 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+	"github.com/kumareswaramoorthi/companies/api/constants"
+	errors "github.com/kumareswaramoorthi/companies/api/errors"
+	"github.com/kumareswaramoorthi/companies/api/logging"
+	"github.com/kumareswaramoorthi/companies/api/models"
+	"github.com/kumareswaramoorthi/companies/api/repository"
+	"github.com/kumareswaramoorthi/companies/api/utils"
+)
+
+type Shareholding interface {
+	CreateShareholding(c *gin.Context, shareholding models.Shareholding) (models.Shareholding, *errors.ErrorResponse)
+	ListShareholdings(c *gin.Context, companyID string, asOf *time.Time) ([]models.Shareholding, *errors.ErrorResponse)
+	ReplaceShareholding(c *gin.Context, shareholding models.Shareholding) (models.Shareholding, *errors.ErrorResponse)
+	DeleteShareholding(c *gin.Context, companyID, id string) *errors.ErrorResponse
+	GetUltimateOwners(c *gin.Context, companyID string, asOf time.Time, minPercentage float64) (models.OwnershipReport, *errors.ErrorResponse)
+}
+
+type shareholding struct {
+	repo        repository.ShareholdingRepository
+	companyRepo repository.Repository
+}
+
+// NewShareholdingService returns the service of the stakes companies hold in each other.
+func NewShareholdingService(repo repository.ShareholdingRepository, companyRepo repository.Repository) Shareholding {
+	return &shareholding{repo: repo, companyRepo: companyRepo}
+}
+
+func (s shareholding) CreateShareholding(c *gin.Context, shareholdingReq models.Shareholding) (models.Shareholding, *errors.ErrorResponse) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Service").
+		WithField(constants.Method, "CreateShareholding")
+
+	if validationErr := validateShareholding(shareholdingReq); validationErr != nil {
+		return models.Shareholding{}, errors.NewErrorResponse(errors.ErrValidationFailed.HttpStatusCode, errors.ValidationFailed, validationErr.Error())
+	}
+	if errResp := checkCompanyExists(c, s.companyRepo, shareholdingReq.CompanyID, errors.ErrNoCompanyRecordsFoundByID); errResp != nil {
+		return models.Shareholding{}, errResp
+	}
+	if errResp := checkCompanyExists(c, s.companyRepo, shareholdingReq.ShareholderID, errors.ErrShareholderNotFound); errResp != nil {
+		return models.Shareholding{}, errResp
+	}
+
+	var err error
+	if shareholdingReq.ID, err = utils.NewCompanyID(); err != nil {
+		logger.Errorf("service: CreateShareholding ID generation error: %s", err.Error())
+		return models.Shareholding{}, errors.ErrInternalServerError
+	}
+
+	if err = s.repo.CreateShareholding(c, shareholdingReq); err != nil {
+		logger.Errorf("service: CreateShareholding company ID [%s] error: %s", shareholdingReq.CompanyID, err.Error())
+		return models.Shareholding{}, shareholdingError(err)
+	}
+
+	created, err := s.repo.GetShareholding(c, shareholdingReq.CompanyID, shareholdingReq.ID)
+	if err != nil {
+		logger.Errorf("service: CreateShareholding ID [%s] error: %s", shareholdingReq.ID, err.Error())
+		return models.Shareholding{}, errors.ErrUnableToFetchShareholdings
+	}
+
+	logger.Debugf("created shareholding with ID: [%s]", created.ID)
+	return created, nil
+}
+
+// ListShareholdings returns the stakes held in a company, only those in effect at asOf
+// when it is not nil.
+func (s shareholding) ListShareholdings(c *gin.Context, companyID string, asOf *time.Time) ([]models.Shareholding, *errors.ErrorResponse) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Service").
+		WithField(constants.Method, "ListShareholdings")
+
+	if errResp := checkCompanyExists(c, s.companyRepo, companyID, errors.ErrNoCompanyRecordsFoundByID); errResp != nil {
+		return nil, errResp
+	}
+
+	shareholdings, err := s.repo.ListShareholdings(c, companyID, asOf)
+	if err != nil {
+		logger.Errorf("service: ListShareholdings company ID [%s] error: %s", companyID, err.Error())
+		return nil, errors.ErrUnableToFetchShareholdings
+	}
+
+	logger.Debugf("found %d shareholdings in company with ID: [%s]", len(shareholdings), companyID)
+	return shareholdings, nil
+}
+
+// ReplaceShareholding changes the percentage and the period of a stake. The shareholder
+// of a stake cannot be changed, a stake of another shareholder is a new shareholding.
+func (s shareholding) ReplaceShareholding(c *gin.Context, shareholdingReq models.Shareholding) (models.Shareholding, *errors.ErrorResponse) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Service").
+		WithField(constants.Method, "ReplaceShareholding")
+
+	if errResp := checkCompanyExists(c, s.companyRepo, shareholdingReq.CompanyID, errors.ErrNoCompanyRecordsFoundByID); errResp != nil {
+		return models.Shareholding{}, errResp
+	}
+
+	current, err := s.repo.GetShareholding(c, shareholdingReq.CompanyID, shareholdingReq.ID)
+	if err == sql.ErrNoRows {
+		return models.Shareholding{}, errors.ErrNoShareholdingFound
+	}
+	if err != nil {
+		logger.Errorf("service: ReplaceShareholding ID [%s] error: %s", shareholdingReq.ID, err.Error())
+		return models.Shareholding{}, errors.ErrUnableToFetchShareholdings
+	}
+
+	if shareholdingReq.ShareholderID == "" {
+		shareholdingReq.ShareholderID = current.ShareholderID
+	}
+	if shareholdingReq.ShareholderID != current.ShareholderID {
+		return models.Shareholding{}, errors.NewErrorResponse(errors.ErrValidationFailed.HttpStatusCode, errors.ValidationFailed,
+			"shareholder_id of a shareholding cannot be changed")
+	}
+	if validationErr := validateShareholding(shareholdingReq); validationErr != nil {
+		return models.Shareholding{}, errors.NewErrorResponse(errors.ErrValidationFailed.HttpStatusCode, errors.ValidationFailed, validationErr.Error())
+	}
+
+	err = s.repo.UpdateShareholding(c, shareholdingReq)
+	if err == sql.ErrNoRows {
+		return models.Shareholding{}, errors.ErrNoShareholdingFound
+	}
+	if err != nil {
+		logger.Errorf("service: ReplaceShareholding ID [%s] error: %s", shareholdingReq.ID, err.Error())
+		return models.Shareholding{}, shareholdingError(err)
+	}
+
+	replaced, err := s.repo.GetShareholding(c, shareholdingReq.CompanyID, shareholdingReq.ID)
+	if err != nil {
+		logger.Errorf("service: ReplaceShareholding ID [%s] error: %s", shareholdingReq.ID, err.Error())
+		return models.Shareholding{}, errors.ErrUnableToFetchShareholdings
+	}
+
+	logger.Debugf("replaced shareholding with ID: [%s]", replaced.ID)
+	return replaced, nil
+}
+
+func (s shareholding) DeleteShareholding(c *gin.Context, companyID, id string) *errors.ErrorResponse {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Service").
+		WithField(constants.Method, "DeleteShareholding")
+
+	if errResp := checkCompanyExists(c, s.companyRepo, companyID, errors.ErrNoCompanyRecordsFoundByID); errResp != nil {
+		return errResp
+	}
+
+	err := s.repo.DeleteShareholding(c, companyID, id)
+	if err == sql.ErrNoRows {
+		return errors.ErrNoShareholdingFound
+	}
+	if err != nil {
+		logger.Errorf("service: DeleteShareholding ID [%s] error: %s", id, err.Error())
+		return errors.ErrUnableToSaveShareholding
+	}
+
+	logger.Debugf("deleted shareholding with ID: [%s]", id)
+	return nil
+}
+
+// GetUltimateOwners traces the stakes in effect at asOf up from a company to the
+// companies without recorded shareholders, and returns those owning at least
+// minPercentage of it.
+func (s shareholding) GetUltimateOwners(c *gin.Context, companyID string, asOf time.Time, minPercentage float64) (models.OwnershipReport, *errors.ErrorResponse) {
+	logger := logging.GetLogger(c).
+		WithField(constants.ReqID, requestid.Get(c)).
+		WithField(constants.Interface, "Service").
+		WithField(constants.Method, "GetUltimateOwners")
+
+	if errResp := checkCompanyExists(c, s.companyRepo, companyID, errors.ErrNoCompanyRecordsFoundByID); errResp != nil {
+		return models.OwnershipReport{}, errResp
+	}
+
+	graph, err := s.repo.ListOwnershipGraph(c, companyID, asOf)
+	if err != nil {
+		logger.Errorf("service: GetUltimateOwners company ID [%s] error: %s", companyID, err.Error())
+		return models.OwnershipReport{}, errors.ErrUnableToComputeOwners
+	}
+
+	report := ultimateOwners(companyID, graph)
+	report.AsOf = asOf
+	owners := report.Owners[:0]
+	for _, owner := range report.Owners {
+		if owner.EffectivePercentage >= minPercentage {
+			owners = append(owners, owner)
+		}
+	}
+	report.Owners = owners
+
+	logger.Debugf("found %d ultimate owners of company with ID: [%s]", len(report.Owners), companyID)
+	return report, nil
+}
+
+// checkCompanyExists returns notFound when no company that is not in the trash has the ID.
+func checkCompanyExists(c *gin.Context, repo repository.Repository, id string, notFound *errors.ErrorResponse) *errors.ErrorResponse {
+	exists, err := repo.CheckCompanyExistsByID(c, id)
+	if err != nil {
+		logging.GetLogger(c).WithField(constants.ReqID, requestid.Get(c)).Errorf("service: CheckCompanyExistsByID ID [%s] error: %s", id, err.Error())
+		return errors.ErrInternalServerError
+	}
+	if !exists {
+		return notFound
+	}
+	return nil
+}
+
+// ultimateOwners spreads a company over its shareholders in proportion to their stakes,
+// round after round: what reaches a company without shareholders is owned by it, what
+// reaches a company with shareholders is spread again in the next round. Around circular
+// holdings the part passed on shrinks every round, so the walk stops once it is
+// negligible. What never reaches an ultimate owner is unattributed.
+func ultimateOwners(companyID string, graph []models.Shareholding) models.OwnershipReport {
+	holders := map[string][]models.Shareholding{}
+	names := map[string]string{}
+	for _, stake := range graph {
+		holders[stake.CompanyID] = append(holders[stake.CompanyID], stake)
+		names[stake.ShareholderID] = stake.ShareholderName
+	}
+
+	owned := map[string]float64{}
+	pending := map[string]float64{companyID: 1}
+	for round := 0; round < constants.MaxOwnershipIterations && len(pending) > 0; round++ {
+		next := map[string]float64{}
+		for id, part := range pending {
+			for _, stake := range holders[id] {
+				share := part * stake.Percentage / 100
+				if len(holders[stake.ShareholderID]) == 0 {
+					owned[stake.ShareholderID] += share
+				} else {
+					next[stake.ShareholderID] += share
+				}
+			}
+		}
+		pending = map[string]float64{}
+		for id, part := range next {
+			if part >= constants.OwnershipEpsilon {
+				pending[id] = part
+			}
+		}
+	}
+
+	report := models.OwnershipReport{CompanyID: companyID, Owners: []models.UltimateOwner{}}
+	attributed := 0.0
+	for id, part := range owned {
+		attributed += part
+		report.Owners = append(report.Owners, models.UltimateOwner{
+			ID:                  id,
+			Name:                names[id],
+			EffectivePercentage: roundPercentage(part * 100),
+		})
+	}
+	report.UnattributedPercentage = roundPercentage(math.Max(0, 100-attributed*100))
+	sort.Slice(report.Owners, func(i, j int) bool {
+		if report.Owners[i].EffectivePercentage != report.Owners[j].EffectivePercentage {
+			return report.Owners[i].EffectivePercentage > report.Owners[j].EffectivePercentage
+		}
+		return report.Owners[i].ID < report.Owners[j].ID
+	})
+	return report
+}
+
+func roundPercentage(percentage float64) float64 {
+	scale := math.Pow10(constants.OwnershipPrecision)
+	return math.Round(percentage*scale) / scale
+}
+
+func validateShareholding(s models.Shareholding) error {
+	if _, err := govalidator.ValidateStruct(s); err != nil {
+		return err
+	}
+	switch {
+	case s.ShareholderID == s.CompanyID:
+		return fmt.Errorf("a company cannot hold shares in itself")
+	case s.Percentage <= 0 || s.Percentage > 100:
+		return fmt.Errorf("percentage must be above 0 and at most 100")
+	case s.EffectiveFrom.IsZero():
+		return fmt.Errorf("effective_from is required")
+	case s.EffectiveTo != nil && !s.EffectiveTo.After(s.EffectiveFrom):
+		return fmt.Errorf("effective_to must be after effective_from")
+	}
+	return nil
+}
+
+// shareholdingError maps a repository write error to the API error reported for it.
+func shareholdingError(err error) *errors.ErrorResponse {
+	switch err {
+	case repository.ErrShareholderNotFound:
+		return errors.ErrShareholderNotFound
+	case repository.ErrShareholdingOverlaps:
+		return errors.ErrShareholdingOverlaps
+	case repository.ErrShareholdingsExceedTotal:
+		return errors.ErrShareholdingsExceedTotal
+	}
+	return errors.ErrUnableToSaveShareholding
+}
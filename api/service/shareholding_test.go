@@ -0,0 +1,149 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	er "github.com/kumareswaramoorthi/companies/api/errors"
+	"github.com/kumareswaramoorthi/companies/api/models"
+	"github.com/kumareswaramoorthi/companies/api/repository"
+	"github.com/kumareswaramoorthi/companies/api/repository/mocks"
+	"github.com/stretchr/testify/suite"
+)
+
+type ShareholdingServiceTestSuite struct {
+	suite.Suite
+	mockCtrl                   *gomock.Controller
+	mockShareholdingRepository *mocks.MockShareholdingRepository
+	mockCompanyRepository      *mocks.MockRepository
+	ShareholdingService        Shareholding
+	context                    *gin.Context
+}
+
+func TestShareholdingService(t *testing.T) {
+	suite.Run(t, new(ShareholdingServiceTestSuite))
+}
+
+func (suite *ShareholdingServiceTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockShareholdingRepository = mocks.NewMockShareholdingRepository(suite.mockCtrl)
+	suite.mockCompanyRepository = mocks.NewMockRepository(suite.mockCtrl)
+	suite.ShareholdingService = NewShareholdingService(suite.mockShareholdingRepository, suite.mockCompanyRepository)
+	suite.context, _ = gin.CreateTestContext(httptest.NewRecorder())
+	suite.context.Request, _ = http.NewRequest("GET", "", nil)
+}
+
+const (
+	shareholderID = "9b2e5a4c-1f3d-4e8a-9c7b-2d6f8e0a1b3c"
+	ownerID       = "7c1d9e2f-3a4b-4c5d-8e6f-0a1b2c3d4e5f"
+)
+
+func stake(companyID, shareholderID string, percentage float64) models.Shareholding {
+	return models.Shareholding{CompanyID: companyID, ShareholderID: shareholderID, ShareholderName: shareholderID[:4], Percentage: percentage}
+}
+
+func (suite *ShareholdingServiceTestSuite) TestGetUltimateOwnersMultipliesAlongPaths() {
+	asOf := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	graph := []models.Shareholding{
+		stake(id, shareholderID, 60),
+		stake(id, ownerID, 10),
+		stake(shareholderID, ownerID, 50),
+	}
+	suite.mockCompanyRepository.EXPECT().CheckCompanyExistsByID(suite.context, id).Return(true, nil)
+	suite.mockShareholdingRepository.EXPECT().ListOwnershipGraph(suite.context, id, asOf).Return(graph, nil)
+
+	report, err := suite.ShareholdingService.GetUltimateOwners(suite.context, id, asOf, 0)
+	suite.Nil(err)
+	suite.Equal(asOf, report.AsOf)
+	suite.Equal([]models.UltimateOwner{{ID: ownerID, Name: ownerID[:4], EffectivePercentage: 40}}, report.Owners)
+	suite.Equal(60.0, report.UnattributedPercentage)
+}
+
+func (suite *ShareholdingServiceTestSuite) TestGetUltimateOwnersFollowsCircularHoldings() {
+	asOf := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	// the company is half owned by a shareholder that is half owned by the company
+	// itself and half by the owner, so the owner ends up with a third of it
+	graph := []models.Shareholding{
+		stake(id, shareholderID, 50),
+		stake(shareholderID, id, 50),
+		stake(shareholderID, ownerID, 50),
+	}
+	suite.mockCompanyRepository.EXPECT().CheckCompanyExistsByID(suite.context, id).Return(true, nil)
+	suite.mockShareholdingRepository.EXPECT().ListOwnershipGraph(suite.context, id, asOf).Return(graph, nil)
+
+	report, err := suite.ShareholdingService.GetUltimateOwners(suite.context, id, asOf, 0)
+	suite.Nil(err)
+	suite.Len(report.Owners, 1)
+	suite.Equal(33.3333, report.Owners[0].EffectivePercentage)
+	suite.Equal(66.6667, report.UnattributedPercentage)
+}
+
+func (suite *ShareholdingServiceTestSuite) TestGetUltimateOwnersBelowMinPercentage() {
+	asOf := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	graph := []models.Shareholding{stake(id, shareholderID, 80), stake(id, ownerID, 5)}
+	suite.mockCompanyRepository.EXPECT().CheckCompanyExistsByID(suite.context, id).Return(true, nil)
+	suite.mockShareholdingRepository.EXPECT().ListOwnershipGraph(suite.context, id, asOf).Return(graph, nil)
+
+	report, err := suite.ShareholdingService.GetUltimateOwners(suite.context, id, asOf, 10)
+	suite.Nil(err)
+	suite.Len(report.Owners, 1)
+	suite.Equal(shareholderID, report.Owners[0].ID)
+	suite.Equal(15.0, report.UnattributedPercentage)
+}
+
+func (suite *ShareholdingServiceTestSuite) TestGetUltimateOwnersCompanyNotFound() {
+	suite.mockCompanyRepository.EXPECT().CheckCompanyExistsByID(suite.context, id).Return(false, nil)
+
+	_, err := suite.ShareholdingService.GetUltimateOwners(suite.context, id, time.Now(), 0)
+	suite.Equal(er.ErrNoCompanyRecordsFoundByID, err)
+}
+
+func (suite *ShareholdingServiceTestSuite) TestCreateShareholdingInItself() {
+	shareholding := stake(id, id, 10)
+	shareholding.EffectiveFrom = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := suite.ShareholdingService.CreateShareholding(suite.context, shareholding)
+	suite.Equal(er.ValidationFailed, string(err.ErrorCode))
+}
+
+func (suite *ShareholdingServiceTestSuite) TestCreateShareholdingExceedingTotal() {
+	shareholding := stake(id, shareholderID, 60)
+	shareholding.EffectiveFrom = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	suite.mockCompanyRepository.EXPECT().CheckCompanyExistsByID(suite.context, id).Return(true, nil)
+	suite.mockCompanyRepository.EXPECT().CheckCompanyExistsByID(suite.context, shareholderID).Return(true, nil)
+	suite.mockShareholdingRepository.EXPECT().CreateShareholding(suite.context, gomock.Any()).Return(repository.ErrShareholdingsExceedTotal)
+
+	_, err := suite.ShareholdingService.CreateShareholding(suite.context, shareholding)
+	suite.Equal(er.ErrShareholdingsExceedTotal, err)
+}
+
+func (suite *ShareholdingServiceTestSuite) TestReplaceShareholdingChangingShareholder() {
+	shareholding := stake(id, ownerID, 10)
+	shareholding.ID = "5f0c7a3e-8d2b-4c1a-9e6f-3b7d2a1c0e9f"
+	shareholding.EffectiveFrom = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	suite.mockCompanyRepository.EXPECT().CheckCompanyExistsByID(suite.context, id).Return(true, nil)
+	suite.mockShareholdingRepository.EXPECT().GetShareholding(suite.context, id, shareholding.ID).Return(stake(id, shareholderID, 10), nil)
+
+	_, err := suite.ShareholdingService.ReplaceShareholding(suite.context, shareholding)
+	suite.Equal(er.ValidationFailed, string(err.ErrorCode))
+}
+
+func (suite *ShareholdingServiceTestSuite) TestReplaceShareholdingCompanyNotFound() {
+	shareholding := stake(id, shareholderID, 10)
+	shareholding.ID = "5f0c7a3e-8d2b-4c1a-9e6f-3b7d2a1c0e9f"
+	suite.mockCompanyRepository.EXPECT().CheckCompanyExistsByID(suite.context, id).Return(false, nil)
+
+	_, err := suite.ShareholdingService.ReplaceShareholding(suite.context, shareholding)
+	suite.Equal(er.ErrNoCompanyRecordsFoundByID, err)
+}
+
+func (suite *ShareholdingServiceTestSuite) TestDeleteShareholdingCompanyNotFound() {
+	suite.mockCompanyRepository.EXPECT().CheckCompanyExistsByID(suite.context, id).Return(false, nil)
+
+	err := suite.ShareholdingService.DeleteShareholding(suite.context, id, "5f0c7a3e-8d2b-4c1a-9e6f-3b7d2a1c0e9f")
+	suite.Equal(er.ErrNoCompanyRecordsFoundByID, err)
+}
@@ -1,8 +1,20 @@
 package utils
 
 import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
 	"os"
+	"regexp"
+	"strings"
 	"time"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/google/uuid"
+	"github.com/kumareswaramoorthi/companies/api/constants"
+	"github.com/kumareswaramoorthi/companies/api/models"
 )
 
 func GetEnvVars(key, defultValue string) string {
@@ -20,13 +32,142 @@ func Now() time.Time {
 
 func GetMapValidations() map[string]interface{} {
 	return map[string]interface{}{
-		"id":                  "",
-		"created_at":          "",
-		"updated_at":          "",
 		"name":                "stringlength(2|15)",
 		"description":         "maxstringlength(3000)",
 		"amount_of_employees": "numeric",
 		"registered":          "type(bool)",
 		"type":                "in(Corporations|NonProfit|Cooperative|Sole Proprietorship)",
+		"parent_id":           "uuid",
+	}
+}
+
+// GetSortableColumns returns the companies columns a list can be ordered by.
+func GetSortableColumns() map[string]bool {
+	return map[string]bool{
+		"id":                  true,
+		"name":                true,
+		"description":         true,
+		"amount_of_employees": true,
+		"registered":          true,
+		"type":                true,
+		"created_at":          true,
+		"updated_at":          true,
+	}
+}
+
+// GetGroupableColumns returns the companies columns stats can be grouped by.
+func GetGroupableColumns() map[string]bool {
+	return map[string]bool{
+		"type":       true,
+		"registered": true,
+	}
+}
+
+// GetSelectableFields returns the company fields a response can be reduced to.
+func GetSelectableFields() map[string]bool {
+	return map[string]bool{
+		"id":                  true,
+		"name":                true,
+		"description":         true,
+		"amount_of_employees": true,
+		"registered":          true,
+		"type":                true,
+		"version":             true,
+		"created_at":          true,
+		"updated_at":          true,
+		"parent_id":           true,
+	}
+}
+
+// GetIncludableResources returns the related resources a company response can embed.
+func GetIncludableResources() map[string]bool {
+	return map[string]bool{
+		constants.IncludeHistory: true,
+	}
+}
+
+// EncodeCursor returns the opaque string form of a keyset cursor.
+func EncodeCursor(cursor models.Cursor) (string, error) {
+	raw, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// DecodeCursor parses a cursor produced by EncodeCursor.
+func DecodeCursor(encoded string) (models.Cursor, error) {
+	var cursor models.Cursor
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return cursor, err
+	}
+	err = json.Unmarshal(raw, &cursor)
+	return cursor, err
+}
+
+// NewCompanyID returns an ID for a company created without one, following the ID_SCHEME
+// environment variable. UUIDv7 IDs grow with time, which keeps inserts local in the primary key index.
+func NewCompanyID() (string, error) {
+	if GetEnvVars("ID_SCHEME", constants.IDSchemeUUIDv4) == constants.IDSchemeUUIDv7 {
+		return newUUIDv7()
+	}
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return "", err
+	}
+	return id.String(), nil
+}
+
+// newUUIDv7 builds a UUIDv7 as laid out in RFC 9562: a 48 bit millisecond timestamp followed by random bits.
+func newUUIDv7() (string, error) {
+	var id uuid.UUID
+	if _, err := rand.Read(id[6:]); err != nil {
+		return "", err
+	}
+	var timestamp [8]byte
+	binary.BigEndian.PutUint64(timestamp[:], uint64(time.Now().UnixMilli()))
+	copy(id[:6], timestamp[2:])
+	id[6] = id[6]&0x0f | 0x70
+	id[8] = id[8]&0x3f | 0x80
+	return id.String(), nil
+}
+
+var e164 = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+// NormalizePhone drops the spaces, dots, dashes and parentheses people write phone
+// numbers with and checks that what is left is an E.164 number, + and up to 15 digits.
+// An empty phone stays empty.
+func NormalizePhone(phone string) (string, error) {
+	normalized := strings.NewReplacer(" ", "", ".", "", "-", "", "(", "", ")", "").Replace(phone)
+	if normalized != "" && !e164.MatchString(normalized) {
+		return "", fmt.Errorf("phone [%s] must be an international number starting with +", phone)
+	}
+	return normalized, nil
+}
+
+// ValidateCompany checks a company decoded from a JSON document against the
+// models.Company rules. govalidator takes false and 0 for missing required values,
+// so those are accepted as long as the document holds the field.
+func ValidateCompany(company models.Company, document map[string]interface{}) error {
+	_, err := govalidator.ValidateStruct(company)
+	validationErrs, ok := err.(govalidator.Errors)
+	if !ok {
+		return err
+	}
+
+	var remaining govalidator.Errors
+	for _, validationErr := range validationErrs.Errors() {
+		if fieldErr, ok := validationErr.(govalidator.Error); ok && fieldErr.Validator == "required" {
+			switch document[fieldErr.Name].(type) {
+			case bool, float64:
+				continue
+			}
+		}
+		remaining = append(remaining, validationErr)
+	}
+	if len(remaining) == 0 {
+		return nil
 	}
+	return remaining
 }
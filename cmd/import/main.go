@@ -0,0 +1,130 @@
+// Command import streams a CSV or NDJSON file of companies to the import endpoint
+// and prints the per-line report.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kumareswaramoorthi/companies/api/codec"
+	"github.com/kumareswaramoorthi/companies/api/models"
+)
+
+func main() {
+	server := flag.String("server", "http://localhost:8080", "base URL of the companies API")
+	file := flag.String("file", "", "CSV or NDJSON file to import, - reads stdin")
+	format := flag.String("format", "", "csv or ndjson, taken from the file extension when omitted")
+	onConflict := flag.String("on-conflict", models.ImportConflictFail, "skip, overwrite or fail")
+	email := flag.String("email", os.Getenv("COMPANIES_EMAIL"), "login email")
+	password := flag.String("password", os.Getenv("COMPANIES_PASSWORD"), "login password")
+	flag.Parse()
+
+	if *file == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *format == "" {
+		*format = formatFromExtension(*file)
+	}
+
+	input := os.Stdin
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		input = f
+	}
+
+	token, err := login(*server, *email, *password)
+	if err != nil {
+		log.Fatalf("login failed: %v", err)
+	}
+
+	report, err := upload(*server, token, *format, *onConflict, input)
+	if err != nil {
+		log.Fatalf("import failed: %v", err)
+	}
+
+	out := json.NewEncoder(os.Stdout)
+	out.SetIndent("", "  ")
+	if err := out.Encode(report); err != nil {
+		log.Fatal(err)
+	}
+	if report.Failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func formatFromExtension(file string) string {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".csv":
+		return codec.CSV
+	case ".ndjson", ".jsonl":
+		return codec.NDJSON
+	}
+	return ""
+}
+
+func login(server, email, password string) (string, error) {
+	body, err := json.Marshal(map[string]string{"email": email, "password": password})
+	if err != nil {
+		return "", err
+	}
+	res, err := http.Post(server+"/api/v1/login", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %s", res.Status)
+	}
+
+	var tokenResp struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&tokenResp); err != nil {
+		return "", err
+	}
+	return tokenResp.Token, nil
+}
+
+// upload streams the input as the request body, so the file is never held in memory.
+func upload(server, token, format, onConflict string, input io.Reader) (models.ImportReport, error) {
+	query := url.Values{"format": {format}, "on_conflict": {onConflict}}
+	req, err := http.NewRequest(http.MethodPost, server+"/api/v1/company/import?"+query.Encode(), input)
+	if err != nil {
+		return models.ImportReport{}, err
+	}
+	req.Header.Set("Authorization", token)
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return models.ImportReport{}, err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return models.ImportReport{}, err
+	}
+	if res.StatusCode != http.StatusOK {
+		return models.ImportReport{}, fmt.Errorf("unexpected status %s: %s", res.Status, body)
+	}
+
+	var report models.ImportReport
+	if err := json.Unmarshal(body, &report); err != nil {
+		return models.ImportReport{}, err
+	}
+	return report, nil
+}
@@ -0,0 +1,15 @@
+ALTER TABLE companies ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+-- updated_at moves with the version, so it is the Last-Modified time of the company.
+-- It cannot be written by the application.
+CREATE OR REPLACE FUNCTION bump_company_version() RETURNS TRIGGER AS $$
+BEGIN
+    IF (to_jsonb(NEW) - 'version' - 'updated_at' - 'search_vector') IS DISTINCT FROM (to_jsonb(OLD) - 'version' - 'updated_at' - 'search_vector') THEN
+        NEW.version := OLD.version + 1;
+        NEW.updated_at := now();
+    ELSE
+        NEW.updated_at := OLD.updated_at;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,19 @@
+-- Companies created before this migration are stamped with the time it ran.
+ALTER TABLE companies ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+CREATE INDEX companies_created_at_idx ON companies (created_at, id);
+CREATE INDEX companies_updated_at_idx ON companies (updated_at, id);
+
+-- created_at is set once by the database clock and never changes afterwards.
+CREATE OR REPLACE FUNCTION bump_company_version() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.created_at := OLD.created_at;
+    IF (to_jsonb(NEW) - 'version' - 'updated_at' - 'search_vector') IS DISTINCT FROM (to_jsonb(OLD) - 'version' - 'updated_at' - 'search_vector') THEN
+        NEW.version := OLD.version + 1;
+        NEW.updated_at := now();
+    ELSE
+        NEW.updated_at := OLD.updated_at;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,48 @@
+-- A company can be the subsidiary of another one. Purging a parent from the trash
+-- detaches its subsidiaries, which are in the trash as well.
+ALTER TABLE companies ADD COLUMN parent_id UUID CONSTRAINT companies_parent_id_fkey REFERENCES companies (id) ON DELETE SET NULL;
+
+CREATE INDEX companies_parent_id_idx ON companies (parent_id);
+
+-- check_company_hierarchy keeps the companies a forest of live groups, once every
+-- statement is done so that a whole subtree can be moved to the trash at once:
+--   * the parent of a live company exists, is not in the trash and is not the company
+--     itself or one of its subsidiaries,
+--   * a company with live subsidiaries cannot be moved to the trash.
+-- Hierarchy changes take a transaction lock, so two concurrent moves cannot close a
+-- cycle that neither of them sees on its own.
+CREATE FUNCTION check_company_hierarchy() RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.deleted_at IS NULL AND NEW.parent_id IS NOT NULL AND (TG_OP = 'INSERT'
+        OR NEW.parent_id IS DISTINCT FROM OLD.parent_id OR OLD.deleted_at IS NOT NULL) THEN
+        PERFORM pg_advisory_xact_lock(hashtext('companies_hierarchy'));
+        IF NOT EXISTS (SELECT 1 FROM companies WHERE id = NEW.parent_id AND deleted_at IS NULL) THEN
+            RAISE EXCEPTION 'parent company % does not exist', NEW.parent_id
+                USING ERRCODE = 'foreign_key_violation', CONSTRAINT = 'companies_parent_id_fkey';
+        END IF;
+        IF EXISTS (
+            WITH RECURSIVE ancestors AS (
+                SELECT id, parent_id FROM companies WHERE id = NEW.parent_id
+                UNION
+                SELECT companies.id, companies.parent_id FROM companies JOIN ancestors ON companies.id = ancestors.parent_id
+            )
+            SELECT 1 FROM ancestors WHERE id = NEW.id
+        ) THEN
+            RAISE EXCEPTION 'company % cannot be a subsidiary of itself or of its subsidiary %', NEW.id, NEW.parent_id
+                USING ERRCODE = 'check_violation', CONSTRAINT = 'companies_parent_cycle';
+        END IF;
+    END IF;
+
+    IF TG_OP = 'UPDATE' AND NEW.deleted_at IS NOT NULL AND OLD.deleted_at IS NULL THEN
+        PERFORM pg_advisory_xact_lock(hashtext('companies_hierarchy'));
+        IF EXISTS (SELECT 1 FROM companies WHERE parent_id = NEW.id AND deleted_at IS NULL) THEN
+            RAISE EXCEPTION 'company % has subsidiaries', NEW.id
+                USING ERRCODE = 'restrict_violation', CONSTRAINT = 'companies_subsidiaries';
+        END IF;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER companies_check_hierarchy AFTER INSERT OR UPDATE OF parent_id, deleted_at ON companies
+    FOR EACH ROW EXECUTE FUNCTION check_company_hierarchy();
@@ -0,0 +1,51 @@
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+-- A shareholding is the percentage of a company a shareholder company holds from
+-- effective_from until effective_to, open ended when effective_to is NULL. Purging a
+-- company from the trash drops the stakes it held and the stakes held in it.
+CREATE TABLE company_shareholdings (
+    id UUID NOT NULL,
+    company_id UUID NOT NULL REFERENCES companies (id) ON DELETE CASCADE,
+    shareholder_id UUID NOT NULL REFERENCES companies (id) ON DELETE CASCADE,
+    percentage NUMERIC(7, 4) NOT NULL CHECK (percentage > 0 AND percentage <= 100),
+    effective_from TIMESTAMPTZ NOT NULL,
+    effective_to TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (id),
+    CHECK (shareholder_id <> company_id),
+    CHECK (effective_to IS NULL OR effective_to > effective_from),
+    -- a shareholder holds one stake in a company at a time
+    CONSTRAINT company_shareholdings_overlap EXCLUDE USING gist (
+        company_id WITH =, shareholder_id WITH =, tstzrange(effective_from, effective_to) WITH &&
+    )
+);
+
+CREATE INDEX company_shareholdings_shareholder_id_idx ON company_shareholdings (shareholder_id);
+
+-- check_company_shareholdings_total keeps the stakes held in a company at any time from
+-- adding up to more than 100 percent. The total only rises where a stake starts, so it is
+-- checked at the start of the written stake and of every stake overlapping it. Writes to
+-- the stakes of one company are serialized so that concurrent writes see each other.
+CREATE FUNCTION check_company_shareholdings_total() RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('company_shareholdings'), hashtext(NEW.company_id::text));
+    IF EXISTS (
+        SELECT 1
+        FROM company_shareholdings starts
+        WHERE starts.company_id = NEW.company_id
+            AND tstzrange(starts.effective_from, starts.effective_to) && tstzrange(NEW.effective_from, NEW.effective_to)
+            AND (
+                SELECT sum(percentage) FROM company_shareholdings
+                WHERE company_id = NEW.company_id
+                    AND tstzrange(effective_from, effective_to) @> greatest(starts.effective_from, NEW.effective_from)
+            ) > 100
+    ) THEN
+        RAISE EXCEPTION 'shareholdings in company % add up to more than 100 percent', NEW.company_id
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'company_shareholdings_total';
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER company_shareholdings_check_total AFTER INSERT OR UPDATE ON company_shareholdings
+    FOR EACH ROW EXECUTE FUNCTION check_company_shareholdings_total();
@@ -0,0 +1,33 @@
+-- A location is an address of a company with its coordinates in degrees. A company has
+-- at most one headquarters and any number of branches. Purging a company from the trash
+-- drops its locations.
+CREATE TABLE company_locations (
+    id UUID NOT NULL,
+    company_id UUID NOT NULL REFERENCES companies (id) ON DELETE CASCADE,
+    type TEXT NOT NULL CHECK (type IN ('headquarters', 'branch')),
+    street TEXT NOT NULL,
+    city TEXT NOT NULL,
+    postal_code TEXT NOT NULL DEFAULT '',
+    region TEXT NOT NULL DEFAULT '',
+    country CHAR(2) NOT NULL CHECK (country ~ '^[A-Z]{2}$'),
+    latitude DOUBLE PRECISION NOT NULL CHECK (latitude BETWEEN -90 AND 90),
+    longitude DOUBLE PRECISION NOT NULL CHECK (longitude BETWEEN -180 AND 180),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX company_locations_company_id_idx ON company_locations (company_id);
+CREATE UNIQUE INDEX company_locations_headquarters_idx ON company_locations (company_id) WHERE type = 'headquarters';
+-- radius searches first narrow the locations down to a band of latitudes
+CREATE INDEX company_locations_latitude_idx ON company_locations (latitude);
+
+CREATE FUNCTION touch_company_location() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at := now();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER company_locations_touch BEFORE UPDATE ON company_locations
+    FOR EACH ROW EXECUTE FUNCTION touch_company_location();
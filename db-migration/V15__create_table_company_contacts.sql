@@ -0,0 +1,28 @@
+-- A contact is a person at a company. Contacts are found by their email, unique within a
+-- company, and searched by name, role or email across companies. Purging a company from
+-- the trash drops its contacts.
+CREATE TABLE company_contacts (
+    id UUID NOT NULL,
+    company_id UUID NOT NULL REFERENCES companies (id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    role TEXT NOT NULL DEFAULT '',
+    email TEXT NOT NULL,
+    -- E.164, + followed by up to 15 digits
+    phone TEXT NOT NULL DEFAULT '' CHECK (phone = '' OR phone ~ '^\+[1-9][0-9]{6,14}$'),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (id)
+);
+
+CREATE UNIQUE INDEX company_contacts_email_idx ON company_contacts (company_id, lower(email));
+CREATE INDEX company_contacts_search_idx ON company_contacts USING gin ((name || ' ' || role || ' ' || email) gin_trgm_ops);
+
+CREATE FUNCTION touch_company_contact() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at := now();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER company_contacts_touch BEFORE UPDATE ON company_contacts
+    FOR EACH ROW EXECUTE FUNCTION touch_company_contact();
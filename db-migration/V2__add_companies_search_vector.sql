@@ -0,0 +1,7 @@
+ALTER TABLE companies
+    ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', coalesce(name, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(description, '')), 'B')
+    ) STORED;
+
+CREATE INDEX companies_search_vector_idx ON companies USING GIN (search_vector);
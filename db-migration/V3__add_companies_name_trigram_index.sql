@@ -0,0 +1,3 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX companies_name_trgm_idx ON companies USING GIN (name gin_trgm_ops);
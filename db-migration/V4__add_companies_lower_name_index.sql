@@ -0,0 +1 @@
+CREATE INDEX companies_lower_name_idx ON companies (lower(name));
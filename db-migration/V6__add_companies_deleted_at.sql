@@ -0,0 +1,7 @@
+ALTER TABLE companies ADD COLUMN deleted_at TIMESTAMPTZ;
+
+-- a deleted company no longer holds its name, so the name is only unique among live companies
+ALTER TABLE companies DROP CONSTRAINT companies_name_key;
+CREATE UNIQUE INDEX companies_name_key ON companies (name) WHERE deleted_at IS NULL;
+
+CREATE INDEX companies_deleted_at_idx ON companies (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,50 @@
+CREATE TABLE company_revisions (
+    company_id UUID NOT NULL,
+    revision INTEGER NOT NULL,
+    operation TEXT NOT NULL CHECK (operation IN ('create', 'update', 'delete', 'restore')),
+    snapshot JSONB NOT NULL,
+    changed_fields TEXT[] NOT NULL,
+    actor TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (company_id, revision)
+);
+
+-- companies created before the history was recorded start from a baseline revision
+INSERT INTO company_revisions (company_id, revision, operation, snapshot, changed_fields)
+SELECT id, 1, 'create', to_jsonb(companies) - 'search_vector', '{}' FROM companies;
+
+-- record_company_revision stores the new state of a company after each insert or update,
+-- with the changed columns and the actor set by the application in companies.actor.
+CREATE FUNCTION record_company_revision() RETURNS TRIGGER AS $$
+DECLARE
+    new_snapshot JSONB := to_jsonb(NEW) - 'search_vector';
+    old_snapshot JSONB;
+    changed TEXT[];
+    op TEXT := 'create';
+BEGIN
+    IF TG_OP = 'UPDATE' THEN
+        old_snapshot := to_jsonb(OLD) - 'search_vector';
+        SELECT coalesce(array_agg(key ORDER BY key), '{}') INTO changed
+        FROM jsonb_each(new_snapshot) WHERE value IS DISTINCT FROM old_snapshot -> key;
+        IF cardinality(changed) = 0 THEN
+            RETURN NULL;
+        END IF;
+        op := CASE
+            WHEN OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL THEN 'delete'
+            WHEN OLD.deleted_at IS NOT NULL AND NEW.deleted_at IS NULL THEN 'restore'
+            ELSE 'update'
+        END;
+    ELSE
+        SELECT coalesce(array_agg(key ORDER BY key), '{}') INTO changed
+        FROM jsonb_each(new_snapshot) WHERE value <> 'null';
+    END IF;
+
+    INSERT INTO company_revisions (company_id, revision, operation, snapshot, changed_fields, actor)
+    SELECT NEW.id, coalesce(max(revision), 0) + 1, op, new_snapshot, changed, nullif(current_setting('companies.actor', true), '')
+    FROM company_revisions WHERE company_id = NEW.id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER companies_record_revision AFTER INSERT OR UPDATE ON companies
+    FOR EACH ROW EXECUTE FUNCTION record_company_revision();
@@ -0,0 +1,40 @@
+ALTER TABLE company_revisions ADD COLUMN reverted_from INTEGER;
+
+ALTER TABLE company_revisions DROP CONSTRAINT company_revisions_operation_check;
+ALTER TABLE company_revisions ADD CONSTRAINT company_revisions_operation_check
+    CHECK (operation IN ('create', 'update', 'delete', 'restore', 'revert'));
+
+-- a revert is recorded even when the company already matches the reverted revision,
+-- the application sets the reverted revision number in companies.reverted_from
+CREATE OR REPLACE FUNCTION record_company_revision() RETURNS TRIGGER AS $$
+DECLARE
+    new_snapshot JSONB := to_jsonb(NEW) - 'search_vector';
+    old_snapshot JSONB;
+    changed TEXT[];
+    op TEXT := 'create';
+    reverted INTEGER := nullif(current_setting('companies.reverted_from', true), '')::INTEGER;
+BEGIN
+    IF TG_OP = 'UPDATE' THEN
+        old_snapshot := to_jsonb(OLD) - 'search_vector';
+        SELECT coalesce(array_agg(key ORDER BY key), '{}') INTO changed
+        FROM jsonb_each(new_snapshot) WHERE value IS DISTINCT FROM old_snapshot -> key;
+        IF cardinality(changed) = 0 AND reverted IS NULL THEN
+            RETURN NULL;
+        END IF;
+        op := CASE
+            WHEN reverted IS NOT NULL THEN 'revert'
+            WHEN OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL THEN 'delete'
+            WHEN OLD.deleted_at IS NOT NULL AND NEW.deleted_at IS NULL THEN 'restore'
+            ELSE 'update'
+        END;
+    ELSE
+        SELECT coalesce(array_agg(key ORDER BY key), '{}') INTO changed
+        FROM jsonb_each(new_snapshot) WHERE value <> 'null';
+    END IF;
+
+    INSERT INTO company_revisions (company_id, revision, operation, snapshot, changed_fields, actor, reverted_from)
+    SELECT NEW.id, coalesce(max(revision), 0) + 1, op, new_snapshot, changed, nullif(current_setting('companies.actor', true), ''), reverted
+    FROM company_revisions WHERE company_id = NEW.id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,15 @@
+ALTER TABLE companies ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+-- every change to a company bumps its version, writes that change nothing keep it.
+-- Generated columns are not computed yet in a BEFORE trigger, so search_vector is ignored.
+CREATE FUNCTION bump_company_version() RETURNS TRIGGER AS $$
+BEGIN
+    IF (to_jsonb(NEW) - 'version' - 'search_vector') IS DISTINCT FROM (to_jsonb(OLD) - 'version' - 'search_vector') THEN
+        NEW.version := OLD.version + 1;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER companies_bump_version BEFORE UPDATE ON companies
+    FOR EACH ROW EXECUTE FUNCTION bump_company_version();
@@ -3,11 +3,14 @@ package e2etest
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/kumareswaramoorthi/companies/api/models"
 	"github.com/stretchr/testify/require"
@@ -81,6 +84,68 @@ func TestCreateCompany(t *testing.T) {
 	require.Equal(t, actualResponse.Name, "xyz6")
 }
 
+func TestCreateCompanyWithoutID(t *testing.T) {
+	reqJson := `{
+		"name": "generated",
+		"description": "company with a server generated ID",
+		"amount_of_employees": 10,
+		"type" : "NonProfit",
+		"registered": true
+	}`
+
+	client := &http.Client{}
+	req, _ := http.NewRequest("POST", "http://localhost:8080/api/v1/company", strings.NewReader(reqJson))
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", token)
+	res, err := client.Do(req)
+	require.Nil(t, err)
+	defer res.Body.Close()
+
+	respBody, err := io.ReadAll(res.Body)
+	require.Nil(t, err)
+
+	var actualResponse models.Company
+	err = json.Unmarshal(respBody, &actualResponse)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+	require.NotEmpty(t, actualResponse.ID)
+	require.Equal(t, "/api/v1/company/"+actualResponse.ID, res.Header.Get("Location"))
+}
+
+func TestCreateCompanyIdempotentRetry(t *testing.T) {
+	reqJson := `{
+		"name": "idempotent",
+		"amount_of_employees": 5,
+		"type" : "Cooperative",
+		"registered": true
+	}`
+
+	var responses []models.Company
+	for i := 0; i < 2; i++ {
+		client := &http.Client{}
+		req, _ := http.NewRequest("POST", "http://localhost:8080/api/v1/company", strings.NewReader(reqJson))
+		req.Header.Add("Content-Type", "application/json")
+		req.Header.Add("Authorization", token)
+		req.Header.Add("Idempotency-Key", "e2e-create-idempotent")
+		res, err := client.Do(req)
+		require.Nil(t, err)
+		defer res.Body.Close()
+
+		respBody, err := io.ReadAll(res.Body)
+		require.Nil(t, err)
+
+		var actualResponse models.Company
+		err = json.Unmarshal(respBody, &actualResponse)
+		require.Nil(t, err)
+		require.Equal(t, http.StatusCreated, res.StatusCode)
+		if i == 1 {
+			require.Equal(t, "true", res.Header.Get("Idempotent-Replayed"))
+		}
+		responses = append(responses, actualResponse)
+	}
+	require.Equal(t, responses[0], responses[1])
+}
+
 func TestGetCompany(t *testing.T) {
 	client := &http.Client{}
 	req, _ := http.NewRequest("GET", "http://localhost:8080/api/v1/company/"+testID, nil)
@@ -101,6 +166,640 @@ func TestGetCompany(t *testing.T) {
 	require.Equal(t, actualResponse.Name, "test")
 }
 
+func TestGetCompanyByName(t *testing.T) {
+	client := &http.Client{}
+	req, _ := http.NewRequest("GET", "http://localhost:8080/api/v1/company/by-name/TEST", nil)
+	req.Header.Add("Content-Type", "application/json")
+	res, err := client.Do(req)
+	require.Nil(t, err)
+	defer res.Body.Close()
+
+	respBody, err := io.ReadAll(res.Body)
+	require.Nil(t, err)
+
+	var actualResponse models.Company
+	err = json.Unmarshal(respBody, &actualResponse)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, actualResponse.ID, testID)
+}
+
+func TestListCompanies(t *testing.T) {
+	client := &http.Client{}
+	req, _ := http.NewRequest("GET", "http://localhost:8080/api/v1/company?type=Corporations&sort=-name&limit=1", nil)
+	req.Header.Add("Content-Type", "application/json")
+	res, err := client.Do(req)
+	require.Nil(t, err)
+	defer res.Body.Close()
+
+	respBody, err := io.ReadAll(res.Body)
+	require.Nil(t, err)
+
+	var actualResponse models.CompanyPage
+	err = json.Unmarshal(respBody, &actualResponse)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Len(t, actualResponse.Data, 1)
+	require.Equal(t, actualResponse.Data[0].Name, "xyz6")
+	require.GreaterOrEqual(t, actualResponse.TotalCount, 2)
+	require.NotEmpty(t, actualResponse.NextCursor)
+}
+
+func TestSearchCompanies(t *testing.T) {
+	client := &http.Client{}
+	req, _ := http.NewRequest("GET", "http://localhost:8080/api/v1/company/search?q=new+company", nil)
+	req.Header.Add("Content-Type", "application/json")
+	res, err := client.Do(req)
+	require.Nil(t, err)
+	defer res.Body.Close()
+
+	respBody, err := io.ReadAll(res.Body)
+	require.Nil(t, err)
+
+	var actualResponse []models.CompanySearchResult
+	err = json.Unmarshal(respBody, &actualResponse)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.NotEmpty(t, actualResponse)
+	require.Equal(t, actualResponse[0].Name, "xyz6")
+	require.Contains(t, actualResponse[0].Snippet, "<b>")
+}
+
+func TestSuggestCompanies(t *testing.T) {
+	client := &http.Client{}
+	req, _ := http.NewRequest("GET", "http://localhost:8080/api/v1/company/suggest?q=xyz7", nil)
+	req.Header.Add("Content-Type", "application/json")
+	res, err := client.Do(req)
+	require.Nil(t, err)
+	defer res.Body.Close()
+
+	respBody, err := io.ReadAll(res.Body)
+	require.Nil(t, err)
+
+	var actualResponse []models.CompanySuggestion
+	err = json.Unmarshal(respBody, &actualResponse)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.NotEmpty(t, actualResponse)
+	require.Equal(t, actualResponse[0].Name, "xyz6")
+}
+
+func TestExportCompanies(t *testing.T) {
+	client := &http.Client{}
+	req, _ := http.NewRequest("GET", "http://localhost:8080/api/v1/company/export?type=Corporations&sort=name", nil)
+	req.Header.Add("Accept", "text/csv")
+	res, err := client.Do(req)
+	require.Nil(t, err)
+	defer res.Body.Close()
+
+	respBody, err := io.ReadAll(res.Body)
+	require.Nil(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(respBody)), "\n")
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, "text/csv", res.Header.Get("Content-Type"))
+	require.Equal(t, "id,name,description,amount_of_employees,registered,type,parent_id", lines[0])
+	require.GreaterOrEqual(t, len(lines), 3)
+}
+
+func TestImportCompanies(t *testing.T) {
+	input := `id,name,description,amount_of_employees,registered,type
+041d2027-e6fa-4d6d-836d-eedb235c82bc,xyz6,new company,100,true,Corporations
+5f0c7a9e-3b1d-4c2e-8a6f-1d9b7e4c2a10,imported,imported company,12,true,NonProfit
+not-a-uuid,broken,broken row,1,true,NonProfit
+`
+
+	client := &http.Client{}
+	req, _ := http.NewRequest("POST", "http://localhost:8080/api/v1/company/import?on_conflict=skip", strings.NewReader(input))
+	req.Header.Add("Content-Type", "text/csv")
+	req.Header.Add("Authorization", token)
+	res, err := client.Do(req)
+	require.Nil(t, err)
+	defer res.Body.Close()
+
+	respBody, err := io.ReadAll(res.Body)
+	require.Nil(t, err)
+
+	var actualResponse models.ImportReport
+	err = json.Unmarshal(respBody, &actualResponse)
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, 3, actualResponse.Processed)
+	require.Equal(t, 1, actualResponse.Skipped)
+	require.Equal(t, 1, actualResponse.Failed)
+	require.Equal(t, 4, actualResponse.Errors[0].Line)
+}
+
+func TestPatchCompanyIfMatch(t *testing.T) {
+	client := &http.Client{}
+	req, _ := http.NewRequest("GET", "http://localhost:8080/api/v1/company/"+testID, nil)
+	res, err := client.Do(req)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	etag := res.Header.Get("ETag")
+	require.NotEmpty(t, etag)
+
+	req, _ = http.NewRequest("PATCH", "http://localhost:8080/api/v1/company/"+testID, strings.NewReader(`{"amount_of_employees": 150}`))
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", token)
+	req.Header.Add("If-Match", etag)
+	res, err = client.Do(req)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.NotEqual(t, etag, res.Header.Get("ETag"))
+
+	// the first update changed the version, so the same ETag is stale now
+	req, _ = http.NewRequest("PATCH", "http://localhost:8080/api/v1/company/"+testID, strings.NewReader(`{"amount_of_employees": 200}`))
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", token)
+	req.Header.Add("If-Match", etag)
+	res, err = client.Do(req)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusPreconditionFailed, res.StatusCode)
+}
+
+func TestGetCompanyNotModified(t *testing.T) {
+	client := &http.Client{}
+	req, _ := http.NewRequest("GET", "http://localhost:8080/api/v1/company/"+testID, nil)
+	res, err := client.Do(req)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	etag := res.Header.Get("ETag")
+	lastModified := res.Header.Get("Last-Modified")
+	require.NotEmpty(t, etag)
+	require.NotEmpty(t, lastModified)
+	require.NotEmpty(t, res.Header.Get("Cache-Control"))
+
+	req, _ = http.NewRequest("GET", "http://localhost:8080/api/v1/company/"+testID, nil)
+	req.Header.Add("If-None-Match", etag)
+	res, err = client.Do(req)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusNotModified, res.StatusCode)
+
+	req, _ = http.NewRequest("GET", "http://localhost:8080/api/v1/company/"+testID, nil)
+	req.Header.Add("If-Modified-Since", lastModified)
+	res, err = client.Do(req)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusNotModified, res.StatusCode)
+}
+
+func TestGetCompanyFields(t *testing.T) {
+	client := &http.Client{}
+	// the history is only embedded for authenticated users, as on its own endpoint
+	req, _ := http.NewRequest("GET", "http://localhost:8080/api/v1/company/"+testID+"?fields=name,type&include=history", nil)
+	res, err := client.Do(req)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+	req, _ = http.NewRequest("GET", "http://localhost:8080/api/v1/company/"+testID+"?fields=name,type&include=history", nil)
+	req.Header.Add("Authorization", token)
+	res, err = client.Do(req)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.True(t, strings.HasPrefix(res.Header.Get("ETag"), `W/"`))
+
+	var company map[string]json.RawMessage
+	err = json.NewDecoder(res.Body).Decode(&company)
+	require.Nil(t, err)
+	require.Len(t, company, 4)
+	require.Contains(t, company, "id")
+	require.Contains(t, company, "name")
+	require.Contains(t, company, "type")
+	require.Contains(t, company, "history")
+
+	req, _ = http.NewRequest("GET", "http://localhost:8080/api/v1/company?fields=name&limit=1", nil)
+	res, err = client.Do(req)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var page models.ShapedCompanyPage
+	err = json.NewDecoder(res.Body).Decode(&page)
+	require.Nil(t, err)
+	require.Len(t, page.Data, 1)
+	require.NotContains(t, page.Data[0], "description")
+
+	req, _ = http.NewRequest("GET", "http://localhost:8080/api/v1/company?fields=secret", nil)
+	res, err = client.Do(req)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
+
+func TestGetCompanyStats(t *testing.T) {
+	client := &http.Client{}
+	req, _ := http.NewRequest("GET", "http://localhost:8080/api/v1/company/stats?group_by=registered&buckets=0,100", nil)
+	res, err := client.Do(req)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var stats models.CompanyStats
+	err = json.NewDecoder(res.Body).Decode(&stats)
+	require.Nil(t, err)
+	require.Equal(t, "registered", stats.GroupBy)
+	require.NotZero(t, stats.TotalCount)
+	require.Len(t, stats.Histogram, 2)
+
+	req, _ = http.NewRequest("GET", "http://localhost:8080/api/v1/company/stats?buckets=100,0", nil)
+	res, err = client.Do(req)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
+
+func TestPatchCompanyTimestamps(t *testing.T) {
+	client := &http.Client{}
+	req, _ := http.NewRequest("PATCH", "http://localhost:8080/api/v1/company/"+testID, strings.NewReader(`{"created_at": "2000-01-01T00:00:00Z"}`))
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", token)
+	res, err := client.Do(req)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	req, _ = http.NewRequest("GET", "http://localhost:8080/api/v1/company?sort=-created_at&min_created_at=2000-01-01T00:00:00Z", nil)
+	res, err = client.Do(req)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var page models.CompanyPage
+	err = json.NewDecoder(res.Body).Decode(&page)
+	require.Nil(t, err)
+	require.NotEmpty(t, page.Data)
+	for _, company := range page.Data {
+		require.False(t, company.CreatedAt.IsZero())
+		require.False(t, company.UpdatedAt.Before(company.CreatedAt))
+	}
+}
+
+func TestPatchCompanyDocument(t *testing.T) {
+	client := &http.Client{}
+	req, _ := http.NewRequest("PATCH", "http://localhost:8080/api/v1/company/"+testID, strings.NewReader(`[
+		{"op": "test", "path": "/name", "value": "not the name"},
+		{"op": "replace", "path": "/amount_of_employees", "value": 10}
+	]`))
+	req.Header.Add("Content-Type", "application/json-patch+json")
+	req.Header.Add("Authorization", token)
+	res, err := client.Do(req)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusConflict, res.StatusCode)
+
+	req, _ = http.NewRequest("PATCH", "http://localhost:8080/api/v1/company/"+testID, strings.NewReader(`{"description": null}`))
+	req.Header.Add("Content-Type", "application/merge-patch+json")
+	req.Header.Add("Authorization", token)
+	res, err = client.Do(req)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var company models.Company
+	err = json.NewDecoder(res.Body).Decode(&company)
+	require.Nil(t, err)
+	require.Empty(t, company.Description)
+
+	req, _ = http.NewRequest("PATCH", "http://localhost:8080/api/v1/company/"+testID, strings.NewReader(`{"type": "Unknown"}`))
+	req.Header.Add("Content-Type", "application/merge-patch+json")
+	req.Header.Add("Authorization", token)
+	res, err = client.Do(req)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
+
+func TestPutCompany(t *testing.T) {
+	// trashed companies keep their ID and name, so every run uses new ones
+	suffix := time.Now().UnixNano() & 0xffffffffffff
+	putID := fmt.Sprintf("6f1c2b3a-4d5e-4f60-8a7b-%012x", suffix)
+	putName := fmt.Sprintf("put %x", suffix&0xffffff)
+	client := &http.Client{}
+	req, _ := http.NewRequest("PUT", "http://localhost:8080/api/v1/company/"+putID, strings.NewReader(`{
+		"name": "`+putName+`",
+		"description": "synced company",
+		"amount_of_employees": 5,
+		"registered": true,
+		"type": "Cooperative"
+	}`))
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", token)
+	res, err := client.Do(req)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+	require.Equal(t, "/api/v1/company/"+putID, res.Header.Get("Location"))
+
+	// a replacement leaves out the description, which clears it, and may set zero values
+	req, _ = http.NewRequest("PUT", "http://localhost:8080/api/v1/company/"+putID, strings.NewReader(`{
+		"name": "`+putName+`",
+		"amount_of_employees": 0,
+		"registered": false,
+		"type": "Cooperative"
+	}`))
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", token)
+	res, err = client.Do(req)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var company models.Company
+	err = json.NewDecoder(res.Body).Decode(&company)
+	require.Nil(t, err)
+	require.Equal(t, 0, company.AmountOfEmployees)
+	require.False(t, company.Registered)
+	require.Empty(t, company.Description)
+	require.Equal(t, 2, company.Version)
+
+	req, _ = http.NewRequest("DELETE", "http://localhost:8080/api/v1/company/"+putID, nil)
+	req.Header.Add("Authorization", token)
+	res, err = client.Do(req)
+	require.Nil(t, err)
+	defer res.Body.Close()
+
+	// a company in the trash must be restored before it is replaced
+	body := `{"name": "` + putName + `", "amount_of_employees": 5, "registered": true, "type": "Cooperative"}`
+	req, _ = http.NewRequest("PUT", "http://localhost:8080/api/v1/company/"+putID, strings.NewReader(body))
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", token)
+	res, err = client.Do(req)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusConflict, res.StatusCode)
+
+	// If-Match: * never matches a missing company
+	missingID := fmt.Sprintf("6f1c2b3a-4d5e-4f60-9a7b-%012x", suffix)
+	req, _ = http.NewRequest("PUT", "http://localhost:8080/api/v1/company/"+missingID, strings.NewReader(body))
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", token)
+	req.Header.Add("If-Match", "*")
+	res, err = client.Do(req)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusPreconditionFailed, res.StatusCode)
+}
+
+func TestCompanyHierarchy(t *testing.T) {
+	suffix := time.Now().UnixNano() & 0xffffffffffff
+	parentID := fmt.Sprintf("7a2d3c4b-5e6f-4071-8b8c-%012x", suffix)
+	childID := fmt.Sprintf("7a2d3c4b-5e6f-4071-9b8c-%012x", suffix)
+	client := &http.Client{}
+	put := func(id, name string, parentID *string) *http.Response {
+		body, _ := json.Marshal(models.Company{Name: name, AmountOfEmployees: 10, Registered: true, Type: "Corporations", ParentID: parentID})
+		req, _ := http.NewRequest("PUT", "http://localhost:8080/api/v1/company/"+id, bytes.NewReader(body))
+		req.Header.Add("Content-Type", "application/json")
+		req.Header.Add("Authorization", token)
+		res, err := client.Do(req)
+		require.Nil(t, err)
+		res.Body.Close()
+		return res
+	}
+	require.Equal(t, http.StatusCreated, put(parentID, fmt.Sprintf("group %x", suffix&0xffffff), nil).StatusCode)
+	require.Equal(t, http.StatusCreated, put(childID, fmt.Sprintf("sub %x", suffix&0xffffff), &parentID).StatusCode)
+
+	res, err := client.Get("http://localhost:8080/api/v1/company/" + childID + "/ancestors")
+	require.Nil(t, err)
+	defer res.Body.Close()
+	var ancestors []models.CompanyNode
+	err = json.NewDecoder(res.Body).Decode(&ancestors)
+	require.Nil(t, err)
+	require.Len(t, ancestors, 1)
+	require.Equal(t, parentID, ancestors[0].ID)
+
+	res, err = client.Get("http://localhost:8080/api/v1/company/" + parentID + "/subtree")
+	require.Nil(t, err)
+	defer res.Body.Close()
+	var tree models.CompanyTree
+	err = json.NewDecoder(res.Body).Decode(&tree)
+	require.Nil(t, err)
+	require.Len(t, tree.Subsidiaries, 1)
+	require.Equal(t, childID, tree.Subsidiaries[0].ID)
+
+	// a company cannot move under its own subsidiary
+	req, _ := http.NewRequest("PATCH", "http://localhost:8080/api/v1/company/"+parentID, strings.NewReader(`{"parent_id": "`+childID+`"}`))
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", token)
+	res, err = client.Do(req)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusConflict, res.StatusCode)
+
+	req, _ = http.NewRequest("DELETE", "http://localhost:8080/api/v1/company/"+parentID, nil)
+	req.Header.Add("Authorization", token)
+	res, err = client.Do(req)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusConflict, res.StatusCode)
+
+	req, _ = http.NewRequest("DELETE", "http://localhost:8080/api/v1/company/"+parentID+"?subsidiaries=cascade", nil)
+	req.Header.Add("Authorization", token)
+	res, err = client.Do(req)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	res, err = client.Get("http://localhost:8080/api/v1/company/" + childID)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	// a subsidiary restored without its parent has no ancestors
+	req, _ = http.NewRequest("POST", "http://localhost:8080/api/v1/company/"+childID+"/restore", nil)
+	req.Header.Add("Authorization", token)
+	res, err = client.Do(req)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	res, err = client.Get("http://localhost:8080/api/v1/company/" + childID + "/ancestors")
+	require.Nil(t, err)
+	defer res.Body.Close()
+	ancestors = nil
+	err = json.NewDecoder(res.Body).Decode(&ancestors)
+	require.Nil(t, err)
+	require.Empty(t, ancestors)
+}
+
+func TestCompanyOwnership(t *testing.T) {
+	suffix := time.Now().UnixNano() & 0xffffffffffff
+	companyID := fmt.Sprintf("8b3e4d5c-6f70-4182-8c9d-%012x", suffix)
+	holderID := fmt.Sprintf("8b3e4d5c-6f70-4182-9c9d-%012x", suffix)
+	ownerID := fmt.Sprintf("8b3e4d5c-6f70-4182-ac9d-%012x", suffix)
+	client := &http.Client{}
+	send := func(method, url, body string) *http.Response {
+		req, _ := http.NewRequest(method, "http://localhost:8080/api/v1"+url, strings.NewReader(body))
+		req.Header.Add("Content-Type", "application/json")
+		req.Header.Add("Authorization", token)
+		res, err := client.Do(req)
+		require.Nil(t, err)
+		return res
+	}
+	for i, id := range []string{companyID, holderID, ownerID} {
+		body := fmt.Sprintf(`{"name": "own%d %x", "amount_of_employees": 10, "registered": true, "type": "Corporations"}`, i, suffix&0xffffff)
+		res := send("PUT", "/company/"+id, body)
+		res.Body.Close()
+		require.Equal(t, http.StatusCreated, res.StatusCode)
+	}
+
+	// the company is half owned by the holder, which is half owned by the company
+	// itself and half by the owner
+	stake := func(companyID, shareholderID string, percentage int) *http.Response {
+		body := fmt.Sprintf(`{"shareholder_id": "%s", "percentage": %d, "effective_from": "2020-01-01T00:00:00Z"}`, shareholderID, percentage)
+		res := send("POST", "/company/"+companyID+"/shareholdings", body)
+		res.Body.Close()
+		return res
+	}
+	require.Equal(t, http.StatusCreated, stake(companyID, holderID, 50).StatusCode)
+	require.Equal(t, http.StatusCreated, stake(holderID, companyID, 50).StatusCode)
+	require.Equal(t, http.StatusCreated, stake(holderID, ownerID, 50).StatusCode)
+	require.Equal(t, http.StatusConflict, stake(companyID, ownerID, 60).StatusCode)
+
+	res, err := client.Get("http://localhost:8080/api/v1/company/" + companyID + "/shareholdings")
+	require.Nil(t, err)
+	defer res.Body.Close()
+	var shareholdings []models.Shareholding
+	err = json.NewDecoder(res.Body).Decode(&shareholdings)
+	require.Nil(t, err)
+	require.Len(t, shareholdings, 1)
+	require.Equal(t, holderID, shareholdings[0].ShareholderID)
+
+	res, err = client.Get("http://localhost:8080/api/v1/company/" + companyID + "/owners")
+	require.Nil(t, err)
+	defer res.Body.Close()
+	var report models.OwnershipReport
+	err = json.NewDecoder(res.Body).Decode(&report)
+	require.Nil(t, err)
+	require.Len(t, report.Owners, 1)
+	require.Equal(t, ownerID, report.Owners[0].ID)
+	require.Equal(t, 33.3333, report.Owners[0].EffectivePercentage)
+
+	res = send("DELETE", "/company/"+companyID+"/shareholdings/"+shareholdings[0].ID, "")
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+}
+
+func TestCompanyLocations(t *testing.T) {
+	suffix := time.Now().UnixNano() & 0xffffffffffff
+	companyID := fmt.Sprintf("9c4f5e6d-7081-4293-8dae-%012x", suffix)
+	client := &http.Client{}
+	send := func(method, url, body string) *http.Response {
+		req, _ := http.NewRequest(method, "http://localhost:8080/api/v1"+url, strings.NewReader(body))
+		req.Header.Add("Content-Type", "application/json")
+		req.Header.Add("Authorization", token)
+		res, err := client.Do(req)
+		require.Nil(t, err)
+		return res
+	}
+	res := send("PUT", "/company/"+companyID, fmt.Sprintf(`{"name": "geo %x", "amount_of_employees": 10, "registered": true, "type": "Corporations"}`, suffix&0xffffff))
+	res.Body.Close()
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+
+	// a location without coordinates is not placed at 0,0
+	res = send("POST", "/company/"+companyID+"/locations",
+		`{"type": "branch", "street": "1 Ocean Road", "city": "Nowhere", "country": "KI", "latitude": -10.5}`)
+	res.Body.Close()
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	// a headquarters in the middle of the Pacific keeps other test data out of the search
+	res = send("POST", "/company/"+companyID+"/locations",
+		`{"type": "headquarters", "street": "1 Ocean Road", "city": "Nowhere", "country": "ki", "latitude": -10.5, "longitude": -150.25}`)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+	var headquarters models.Location
+	err := json.NewDecoder(res.Body).Decode(&headquarters)
+	require.Nil(t, err)
+	require.Equal(t, "KI", headquarters.Country)
+
+	res = send("POST", "/company/"+companyID+"/locations",
+		`{"type": "headquarters", "street": "2 Ocean Road", "city": "Nowhere", "country": "KI", "latitude": -10.6, "longitude": -150.25}`)
+	res.Body.Close()
+	require.Equal(t, http.StatusConflict, res.StatusCode)
+
+	res, err = client.Get("http://localhost:8080/api/v1/company/near?lat=-10.6&lng=-150.25&radius=20")
+	require.Nil(t, err)
+	defer res.Body.Close()
+	var nearby []models.NearbyCompany
+	err = json.NewDecoder(res.Body).Decode(&nearby)
+	require.Nil(t, err)
+	require.NotEmpty(t, nearby)
+	require.Equal(t, companyID, nearby[0].ID)
+	require.Equal(t, headquarters.ID, nearby[0].Location.ID)
+	require.InDelta(t, 11.1, nearby[0].DistanceKm, 0.1)
+
+	res, err = client.Get("http://localhost:8080/api/v1/company/near?lat=-10.6&lng=-150.25&radius=5")
+	require.Nil(t, err)
+	defer res.Body.Close()
+	nearby = nil
+	err = json.NewDecoder(res.Body).Decode(&nearby)
+	require.Nil(t, err)
+	require.Empty(t, nearby)
+
+	res = send("DELETE", "/company/"+companyID+"/locations/"+headquarters.ID, "")
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+}
+
+func TestCompanyContacts(t *testing.T) {
+	suffix := time.Now().UnixNano() & 0xffffffffffff
+	companyID := fmt.Sprintf("ad5a6f7e-8192-43a4-8ebf-%012x", suffix)
+	email := fmt.Sprintf("contact-%x@example.com", suffix)
+	client := &http.Client{}
+	send := func(method, url, body string) *http.Response {
+		req, _ := http.NewRequest(method, "http://localhost:8080/api/v1"+url, strings.NewReader(body))
+		req.Header.Add("Content-Type", "application/json")
+		req.Header.Add("Authorization", token)
+		res, err := client.Do(req)
+		require.Nil(t, err)
+		return res
+	}
+	res := send("PUT", "/company/"+companyID, fmt.Sprintf(`{"name": "crm %x", "amount_of_employees": 10, "registered": true, "type": "Corporations"}`, suffix&0xffffff))
+	res.Body.Close()
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+
+	res = send("POST", "/company/"+companyID+"/contacts", `{"name": "Asha Rao", "role": "CFO", "email": "`+email+`", "phone": "+91 (44) 1234-5678"}`)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+	var contact models.Contact
+	err := json.NewDecoder(res.Body).Decode(&contact)
+	require.Nil(t, err)
+	require.Equal(t, "+914412345678", contact.Phone)
+
+	res = send("POST", "/company/"+companyID+"/contacts", `{"name": "Asha R", "email": "`+strings.ToUpper(email)+`"}`)
+	res.Body.Close()
+	require.Equal(t, http.StatusConflict, res.StatusCode)
+
+	res = send("POST", "/company/"+companyID+"/contacts", `{"name": "Ravi", "email": "ravi@example.com", "phone": "12345"}`)
+	res.Body.Close()
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	// contacts are not public
+	res, err = client.Get("http://localhost:8080/api/v1/company/" + companyID + "/contacts")
+	require.Nil(t, err)
+	res.Body.Close()
+	require.NotEqual(t, http.StatusOK, res.StatusCode)
+
+	res = send("GET", "/contacts/search?q="+email, "")
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	var results []models.ContactSearchResult
+	err = json.NewDecoder(res.Body).Decode(&results)
+	require.Nil(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, contact.ID, results[0].ID)
+
+	res = send("DELETE", "/company/"+companyID+"/contacts/"+contact.ID, "")
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+}
+
 func TestPatchCompany(t *testing.T) {
 	reqJson := `{
 		"name": "updated company",
@@ -127,6 +826,68 @@ func TestPatchCompany(t *testing.T) {
 	require.Equal(t, actualResponse.Description, "updated company description")
 }
 
+func TestGetCompanyHistory(t *testing.T) {
+	client := &http.Client{}
+	req, _ := http.NewRequest("GET", "http://localhost:8080/api/v1/company/"+testID+"/history", nil)
+	req.Header.Add("Authorization", token)
+	res, err := client.Do(req)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	respBody, err := io.ReadAll(res.Body)
+	require.Nil(t, err)
+	var page models.CompanyRevisionPage
+	err = json.Unmarshal(respBody, &page)
+	require.Nil(t, err)
+	require.GreaterOrEqual(t, page.TotalCount, 2)
+	require.Equal(t, "update", page.Data[0].Operation)
+	require.Contains(t, page.Data[0].ChangedFields, "name")
+	require.Equal(t, "admin@company.com", *page.Data[0].Actor)
+}
+
+func TestGetCompanyAsOf(t *testing.T) {
+	client := &http.Client{}
+	req, _ := http.NewRequest("GET", "http://localhost:8080/api/v1/company/"+testID+"?as_of="+url.QueryEscape(time.Now().Format(time.RFC3339Nano)), nil)
+	res, err := client.Do(req)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	respBody, err := io.ReadAll(res.Body)
+	require.Nil(t, err)
+	var company models.CompanyVersion
+	err = json.Unmarshal(respBody, &company)
+	require.Nil(t, err)
+	require.Equal(t, "updated company", company.Name)
+	require.Greater(t, company.Revision, 1)
+
+	req, _ = http.NewRequest("GET", "http://localhost:8080/api/v1/company/"+testID+"?as_of=2000-01-01T00:00:00Z", nil)
+	res, err = client.Do(req)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
+
+func TestRevertCompany(t *testing.T) {
+	client := &http.Client{}
+	req, _ := http.NewRequest("POST", "http://localhost:8080/api/v1/company/"+testID+"/revert", strings.NewReader(`{"revision": 1}`))
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", token)
+	res, err := client.Do(req)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	respBody, err := io.ReadAll(res.Body)
+	require.Nil(t, err)
+	var company models.Company
+	err = json.Unmarshal(respBody, &company)
+	require.Nil(t, err)
+	require.Equal(t, "test", company.Name)
+	require.Equal(t, "test company", company.Description)
+}
+
 func TestDeleteCompany(t *testing.T) {
 	client := &http.Client{}
 	req, _ := http.NewRequest("DELETE", "http://localhost:8080/api/v1/company/"+testID, nil)
@@ -139,6 +900,40 @@ func TestDeleteCompany(t *testing.T) {
 	require.Equal(t, http.StatusOK, res.StatusCode)
 }
 
+func TestRestoreCompany(t *testing.T) {
+	client := &http.Client{}
+	req, _ := http.NewRequest("GET", "http://localhost:8080/api/v1/company/trash", nil)
+	req.Header.Add("Authorization", token)
+	res, err := client.Do(req)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	respBody, err := io.ReadAll(res.Body)
+	require.Nil(t, err)
+	var trash []models.DeletedCompany
+	err = json.Unmarshal(respBody, &trash)
+	require.Nil(t, err)
+	found := false
+	for _, company := range trash {
+		found = found || company.ID == testID
+	}
+	require.True(t, found)
+
+	req, _ = http.NewRequest("POST", "http://localhost:8080/api/v1/company/"+testID+"/restore", nil)
+	req.Header.Add("Authorization", token)
+	res, err = client.Do(req)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	req, _ = http.NewRequest("GET", "http://localhost:8080/api/v1/company/"+testID, nil)
+	res, err = client.Do(req)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+}
+
 func createTestData() {
 	reqJson := `{
 		"id": "041d2027-e6fa-4d6d-836d-eedb235c82be",